@@ -0,0 +1,42 @@
+// Command discord-commands registers the bot slash commands (/report, /standings and
+// /mymatch) with Discord. Run it once after creating the application, and again when
+// the commands change.
+//
+// Usage:
+//
+//	DISCORD_BOT_TOKEN=... go run ./cmd/discord-commands -app <application id>
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/discord"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	appID := flag.String("app", "", "Discord application ID")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using system environment variables")
+	}
+
+	cfg := discord.ConfigFromEnv()
+	if *appID == "" || cfg.Token == "" {
+		log.Println("An application ID (-app) and DISCORD_BOT_TOKEN are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := discord.NewAPIClient(cfg.Token, cfg.APIURL).RegisterCommands(ctx, *appID); err != nil {
+		log.Fatal("Failed to register commands: ", err)
+	}
+	log.Printf("✅ Registered %d commands", len(discord.Commands))
+}
@@ -0,0 +1,157 @@
+// Command import loads historical in-person tournaments from spreadsheet CSV exports
+// into the archive tables, then refreshes the global statistics views and drops the
+// cached statistics so the imported tournaments show up in them.
+//
+// Usage:
+//
+//	go run ./cmd/import -standings standings.csv -pairings pairings.csv -races races.csv [-dry-run] [-create-players]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/cache"
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/handlers"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/tournamentio"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	standingsPath := flag.String("standings", "", "CSV file with final standings")
+	pairingsPath := flag.String("pairings", "", "CSV file with round pairings and results")
+	racesPath := flag.String("races", "", "CSV file with player races")
+	dryRun := flag.Bool("dry-run", false, "validate and report without writing anything")
+	createPlayers := flag.Bool("create-players", false, "create players missing from premier_players")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *standingsPath == "" && *pairingsPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using system environment variables")
+	}
+
+	var files tournamentio.BulkFiles
+	for path, target := range map[string]*io.Reader{
+		*standingsPath: &files.Standings,
+		*pairingsPath:  &files.Pairings,
+		*racesPath:     &files.Races,
+	} {
+		if path == "" {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal("Failed to open file:", err)
+		}
+		defer f.Close()
+		*target = f
+	}
+
+	if err := database.Connect(); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	// The cached statistics are only shared with the server through Redis
+	cacheConfig := cache.ConfigFromEnv()
+	store, err := cache.NewStore(cacheConfig)
+	if err != nil {
+		log.Fatal("Failed to configure cache: ", err)
+	}
+	cache.Use(store, cacheConfig)
+
+	ctx := context.Background()
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Fatal("Failed to start transaction:", err)
+	}
+	defer tx.Rollback()
+
+	report, err := tournamentio.BulkImport(ctx, tx, files, tournamentio.BulkOptions{
+		CreateMissingPlayers: *createPlayers,
+		DryRun:               *dryRun,
+	})
+	var validationErr *tournamentio.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		log.Fatal("Import failed:", err)
+	}
+
+	if !*dryRun && err == nil {
+		if err := tx.Commit(); err != nil {
+			log.Fatal("Failed to commit import:", err)
+		}
+		if imported(report) {
+			refreshCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+			if err := handlers.RefreshStats(refreshCtx); err != nil {
+				log.Fatal("Failed to refresh statistics views:", err)
+			}
+		}
+	}
+
+	printReport(report, *asJSON)
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+// imported reports whether the run wrote at least one tournament
+func imported(report *models.BulkImportReport) bool {
+	for _, t := range report.Tournaments {
+		if t.Status == models.BulkImportImported {
+			return true
+		}
+	}
+	return false
+}
+
+func printReport(report *models.BulkImportReport, asJSON bool) {
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Printf("❌ %d problem(s) found, nothing was imported:\n", len(report.Errors))
+		for _, e := range report.Errors {
+			fmt.Println("  -", e)
+		}
+		return
+	}
+
+	if report.DryRun {
+		fmt.Println("Dry run: nothing was written")
+	}
+	for _, t := range report.Tournaments {
+		switch t.Status {
+		case models.BulkImportDuplicate:
+			fmt.Printf("⏭  %s (%s %d): already exists, skipped\n", t.Name, t.Month, t.Year)
+		default:
+			id := "-"
+			if t.TournamentID != nil {
+				id = fmt.Sprint(*t.TournamentID)
+			}
+			fmt.Printf("✓ %s (%s %d): id %s, %d players, %d rounds, %d matches, %d races\n",
+				t.Name, t.Month, t.Year, id, t.Players, t.Rounds, t.Matches, t.Races)
+			for _, name := range t.PlayersCreated {
+				fmt.Println("    created player", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Command rebuild-stats regenerates the player_format_stats summary from every
+// archived and online match, then refreshes the global statistics views and drops
+// the cached statistics. The server keeps the summary up to date by itself; run this
+// after fixing data by hand or changing how the summary is computed.
+//
+// Usage:
+//
+//	go run ./cmd/rebuild-stats
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/cache"
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/handlers"
+	"github.com/andreuvv/premier_mitologico/backend/internal/playerstats"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using system environment variables")
+	}
+
+	if err := database.Connect(); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	// The cached statistics are only shared with the server through Redis
+	cacheConfig := cache.ConfigFromEnv()
+	store, err := cache.NewStore(cacheConfig)
+	if err != nil {
+		log.Fatal("Failed to configure cache: ", err)
+	}
+	cache.Use(store, cacheConfig)
+
+	ctx := context.Background()
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Fatal("Failed to start transaction:", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := playerstats.Rebuild(ctx, tx)
+	if err != nil {
+		log.Fatal("Failed to rebuild player statistics:", err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal("Failed to commit player statistics:", err)
+	}
+	log.Printf("✅ Rebuilt player_format_stats: %d rows", rows)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := handlers.RefreshStats(ctx); err != nil {
+		log.Fatal("Failed to refresh statistics views:", err)
+	}
+	log.Println("✅ Refreshed statistics views")
+}
@@ -1,115 +1,166 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/andreuvv/premier_mitologico/backend/internal/cache"
 	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/discord"
+	"github.com/andreuvv/premier_mitologico/backend/internal/env"
 	"github.com/andreuvv/premier_mitologico/backend/internal/handlers"
+	"github.com/andreuvv/premier_mitologico/backend/internal/logging"
 	"github.com/andreuvv/premier_mitologico/backend/internal/middleware"
+	"github.com/andreuvv/premier_mitologico/backend/internal/notify"
+	"github.com/andreuvv/premier_mitologico/backend/internal/openapi"
+	"github.com/andreuvv/premier_mitologico/backend/internal/ratelimit"
+	"github.com/andreuvv/premier_mitologico/backend/internal/scheduler"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
 func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: .env file not found, using system environment variables")
+	// Load environment variables, then log as LOG_LEVEL and LOG_FORMAT say
+	envErr := godotenv.Load()
+	logging.Setup(logging.ConfigFromEnv())
+	if envErr != nil {
+		slog.Warn(".env file not found, using system environment variables")
 	}
 
 	// Connect to database
 	if err := database.Connect(); err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("failed to connect to database", err)
 	}
 	defer database.Close()
 
 	// Run database migrations
 	if err := database.RunMigrations(); err != nil {
-		log.Fatal("Failed to run migrations:", err)
+		fatal("failed to run migrations", err)
 	}
 
-	// Set up Gin router
-	router := gin.Default()
+	// The background workers below run until SIGINT or SIGTERM (sent by Railway
+	// before replacing a deployment)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Start the online match deadline scheduler
+	scheduler.RegisterReminderHook(scheduler.LogReminder)
+	scheduler.RegisterReminderHook(notify.DeadlineReminder)
+	scheduler.RegisterExpiryQueue(webhooks.EnqueueOnlineMatchExpired)
+	scheduler.RegisterExpiryHook(webhooks.OnlineMatchExpired)
+	scheduler.Start(ctx, scheduler.ConfigFromEnv())
+
+	// Start the webhook dispatcher
+	webhooks.Start(ctx, webhooks.ConfigFromEnv())
+
+	// Email players about their tournaments
+	sender, err := notify.NewSender(notify.SMTPConfigFromEnv())
+	if err != nil {
+		fatal("failed to configure notifications", err)
+	}
+	webhooks.RegisterQueue(notify.QueueEvent)
+	webhooks.RegisterListener(notify.OnEvent)
+	notify.Start(ctx, notify.ConfigFromEnv(), sender)
+
+	// Cache the global statistics, in Redis when REDIS_URL is set, and keep them
+	// fresh after archive changes
+	cacheConfig := cache.ConfigFromEnv()
+	store, err := cache.NewStore(cacheConfig)
+	if err != nil {
+		fatal("failed to configure cache", err)
+	}
+	cache.Use(store, cacheConfig)
+	handlers.StartStatsRefresher(ctx)
 
-	// Apply middleware
-	router.Use(middleware.CORSMiddleware())
+	// Post tournament events to Discord when a bot is configured
+	if cfg := discord.ConfigFromEnv(); cfg.Enabled() {
+		handlers.EnableDiscord(discord.NewClient(cfg), cfg)
+	}
 
-	// Public routes (no authentication required)
-	public := router.Group("/api")
-	{
-		public.GET("/fixture", handlers.GetFixture)
-		public.GET("/standings", handlers.GetStandings)
-
-		// Player routes (more specific first)
-		public.GET("/players/:player_id/tournaments", handlers.GetPlayerTournamentHistory)
-		public.GET("/premier-players", handlers.GetPremierPlayers)
-		public.GET("/players", handlers.GetPlayers)
-
-		// Global statistics (aggregated from all tournaments)
-		public.GET("/global-standings", handlers.GetGlobalStandings)
-		public.GET("/global-races", handlers.GetGlobalRaces)
-
-		// Tournament history (public access)
-		public.GET("/tournaments", handlers.GetTournaments)
-		public.GET("/tournaments/:id/standings", handlers.GetTournamentStandings)
-		public.GET("/tournaments/:id/rounds", handlers.GetTournamentRounds)
-		public.GET("/tournaments/:id/races", handlers.GetTournamentRaces)
-		public.GET("/tournaments/:id/players", handlers.GetArchivedTournamentPlayers)
-		public.GET("/tournaments/:id/player-races", handlers.GetTournamentPlayerRaces)
-
-		// Active tournaments (online and in-person)
-		public.GET("/tournaments/active", handlers.GetAllActiveTournaments)
+	// Throttle clients and lock out API key guessing, sharing the buckets through Redis
+	// when REDIS_URL is set
+	limitConfig := ratelimit.ConfigFromEnv()
+	limitStore, err := ratelimit.NewStore(limitConfig)
+	if err != nil {
+		fatal("failed to configure rate limiting", err)
+	}
+	limiter := ratelimit.New(limitStore, limitConfig)
+
+	// Set up Gin router, with a request ID and a structured log line per request
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.LoggerMiddleware())
+
+	// Client IPs (for rate limiting) are only read from X-Forwarded-For when sent by
+	// one of TRUSTED_PROXIES; without it, the address of the connection is used
+	if err := middleware.TrustProxies(router, os.Getenv("TRUSTED_PROXIES")); err != nil {
+		fatal("invalid TRUSTED_PROXIES", err)
 	}
 
-	// Protected routes (require API key)
-	protected := router.Group("/api")
-	protected.Use(middleware.AuthMiddleware())
-	{
-		// Match score updates
-		protected.PATCH("/matches/:id/score", handlers.UpdateMatchScore)
-
-		// Player management
-		protected.POST("/players", handlers.CreatePlayer)
-		protected.PATCH("/players/:id/confirm", handlers.TogglePlayerConfirmed)
-		protected.GET("/players/confirmed", handlers.GetConfirmedPlayers)
-
-		// Fixture creation (creates entire tournament structure)
-		protected.POST("/fixture", handlers.CreateFixture)
-
-		// Clear tournament data
-		protected.DELETE("/tournament", handlers.ClearTournament)
-
-		// Tournament archiving
-		protected.POST("/tournaments/archive", handlers.ArchiveTournament)
-		protected.DELETE("/tournaments/:id", handlers.DeleteArchivedTournament)
-
-		// Tournament player race tracking
-		protected.PATCH("/tournaments/:id/players/:player_id/race", handlers.UpdatePlayerRace)
-
-		// Online tournament routes
-		protected.POST("/tournaments/online", handlers.CreateOnlineTournament)
-		protected.GET("/tournaments/online/:id/info", handlers.GetOnlineTournamentInfo)
-		protected.GET("/tournaments/online/:id/matches", handlers.GetOnlineTournamentMatches)
-		protected.GET("/tournaments/online/:id/matches/pending", handlers.GetOnlinePendingMatches)
-		protected.GET("/tournaments/online/:id/matches/completed", handlers.GetOnlineCompletedMatches)
-		protected.GET("/tournaments/online/:id/standings", handlers.GetOnlineTournamentStandings)
-		protected.PATCH("/tournaments/online/matches/:matchId", handlers.UpdateOnlineMatchScore)
-		protected.DELETE("/tournaments/online/:id", handlers.DeleteOnlineTournament)
+	// Apply middleware
+	router.Use(middleware.CORSMiddleware())
+	router.Use(middleware.RateLimitMiddleware(limiter))
+
+	// OpenAPI document of the routes below; requests are validated against it unless
+	// OPENAPI_VALIDATE=false
+	spec := openapi.New(openapi.Info{
+		Title:   "Premier Mitológico API",
+		Version: "1.1",
+	}, handlers.Endpoints)
+	if os.Getenv("OPENAPI_VALIDATE") != "false" {
+		router.Use(spec.Validate())
 	}
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	registerRoutes(router, spec, limiter)
 
 	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
+	server := &http.Server{
+		Addr:         ":" + port,
+		Handler:      router,
+		ReadTimeout:  env.Duration("HTTP_READ_TIMEOUT", 30*time.Second),
+		WriteTimeout: env.Duration("HTTP_WRITE_TIMEOUT", 60*time.Second),
+		IdleTimeout:  env.Duration("HTTP_IDLE_TIMEOUT", 120*time.Second),
+	}
 
-	log.Printf("🚀 Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	go func() {
+		slog.Info("server starting", "port", port, "read_timeout", server.ReadTimeout,
+			"write_timeout", server.WriteTimeout, "idle_timeout", server.IdleTimeout)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("failed to start server", err)
+		}
+	}()
+
+	// On a signal, stop the workers, fail /ready and let the requests in flight finish
+	// for up to SHUTDOWN_TIMEOUT; a second signal exits at once
+	<-ctx.Done()
+	stop()
+	timeout := env.Duration("SHUTDOWN_TIMEOUT", 25*time.Second)
+	slog.Info("shutting down", "timeout", timeout)
+	handlers.Drain()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("requests still running at shutdown timeout, closing them", "error", err)
+		server.Close()
 	}
+	slog.Info("server stopped")
+}
+
+// fatal logs an error that keeps the server from starting and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
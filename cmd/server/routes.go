@@ -0,0 +1,215 @@
+package main
+
+import (
+	"os"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/apiv2"
+	"github.com/andreuvv/premier_mitologico/backend/internal/handlers"
+	"github.com/andreuvv/premier_mitologico/backend/internal/metrics"
+	"github.com/andreuvv/premier_mitologico/backend/internal/middleware"
+	"github.com/andreuvv/premier_mitologico/backend/internal/openapi"
+	"github.com/andreuvv/premier_mitologico/backend/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// registerRoutes adds the API routes to router and documents them in spec
+func registerRoutes(router *gin.Engine, spec *openapi.Spec, limiter *ratelimit.Limiter) {
+	// Public routes (no authentication required)
+	public := router.Group("/api")
+	{
+		public.GET("/fixture", handlers.GetFixture)
+		public.GET("/standings", handlers.GetStandings)
+		public.GET("/matches/:id", handlers.GetMatch)
+
+		// Printable sheets for the venue (HTML or PDF)
+		public.GET("/print/pairings", handlers.PrintPairings)
+		public.GET("/print/standings", handlers.PrintStandings)
+		public.GET("/print/slips", handlers.PrintResultSlips)
+
+		// Round clocks (GET /clock/stream pushes server-sent events)
+		public.GET("/clock", handlers.GetCurrentRoundClock)
+		public.GET("/clock/stream", handlers.StreamRoundClock)
+		public.GET("/rounds/:number/clock", handlers.GetRoundClock)
+
+		// Discord slash commands (signed by Discord, not by API key)
+		public.POST("/discord/interactions", handlers.DiscordInteractions)
+
+		// Player routes (more specific first)
+		public.GET("/players/:player_id/tournaments", handlers.GetPlayerTournamentHistory)
+		public.GET("/players/:player_id/calendar.ics", handlers.GetPlayerCalendar)
+		public.GET("/premier-players", handlers.GetPremierPlayers)
+		public.GET("/players", handlers.GetPlayers)
+
+		// Global statistics (aggregated from all tournaments)
+		public.GET("/global-standings", handlers.GetGlobalStandings)
+		public.GET("/global-races", handlers.GetGlobalRaces)
+
+		// Tournament history (public access)
+		public.GET("/tournaments", handlers.GetTournaments)
+		public.GET("/tournaments/:id/standings", handlers.GetTournamentStandings)
+		public.GET("/tournaments/:id/rounds", handlers.GetTournamentRounds)
+		public.GET("/tournaments/:id/races", handlers.GetTournamentRaces)
+		public.GET("/tournaments/:id/players", handlers.GetArchivedTournamentPlayers)
+		public.GET("/tournaments/:id/player-races", handlers.GetTournamentPlayerRaces)
+
+		// Active tournaments (online and in-person)
+		public.GET("/tournaments/active", handlers.GetAllActiveTournaments)
+
+		// Seasons (yearly circuit)
+		public.GET("/seasons", handlers.GetSeasons)
+		public.GET("/seasons/:id", handlers.GetSeason)
+		public.GET("/seasons/:id/leaderboard", handlers.GetSeasonLeaderboard)
+	}
+
+	// API v2 (read-only for now): every answer is an envelope, errors carry a code and
+	// lists are paged with cursors. v1 above stays as it is.
+	v2 := router.Group(apiv2.Prefix)
+	{
+		v2.GET("/tournaments", handlers.GetTournamentsV2)
+		v2.GET("/tournaments/:id", handlers.GetTournamentV2)
+		v2.GET("/tournaments/:id/standings", handlers.GetTournamentStandingsV2)
+		v2.GET("/players", handlers.GetPlayersV2)
+		v2.GET("/premier-players", handlers.GetPremierPlayersV2)
+		v2.GET("/fixture", handlers.GetFixtureV2)
+		v2.GET("/standings", handlers.GetStandingsV2)
+		v2.GET("/matches/:id", handlers.GetMatchV2)
+	}
+
+	// Health check (the database answers) and readiness (migrated, not shutting down)
+	router.GET("/health", handlers.Health)
+	router.GET("/ready", handlers.Ready)
+
+	spec.RejectWith(apiv2.Prefix+"/", apiv2.RejectInvalid)
+	router.NoRoute(apiv2.NoRoute)
+	spec.AddRoutes(router.Routes(), false)
+
+	// Protected routes (require API key)
+	protected := router.Group("/api")
+	protected.Use(middleware.AuthMiddleware(limiter))
+	{
+		// Match score updates
+		protected.PATCH("/matches/:id/score", handlers.UpdateMatchScore)
+
+		// Player management
+		protected.POST("/players", handlers.CreatePlayer)
+		protected.PATCH("/players/:id/confirm", handlers.TogglePlayerConfirmed)
+		protected.GET("/players/confirmed", handlers.GetConfirmedPlayers)
+		protected.POST("/players/late-entry", handlers.LateEntryPlayer)
+		protected.POST("/players/:id/drop", handlers.DropPlayer)
+		protected.POST("/players/:id/disqualify", handlers.DisqualifyPlayer)
+		protected.PATCH("/players/:id/table", handlers.SetPlayerFixedTable)
+
+		// Fixture creation (creates entire tournament structure) and round lifecycle
+		protected.POST("/fixture", handlers.CreateFixture)
+		protected.POST("/rounds", handlers.CreateRound)
+		protected.POST("/rounds/:number/lock", handlers.LockRound)
+		protected.POST("/rounds/:number/unlock", handlers.UnlockRound)
+		protected.POST("/rounds/:number/tables", handlers.AssignRoundTables)
+
+		// Round clocks
+		protected.POST("/rounds/:number/clock/start", handlers.StartRoundClock)
+		protected.POST("/rounds/:number/clock/pause", handlers.PauseRoundClock)
+		protected.POST("/rounds/:number/clock/resume", handlers.ResumeRoundClock)
+		protected.POST("/rounds/:number/clock/extend", handlers.ExtendRoundClock)
+		protected.DELETE("/rounds/:number/clock", handlers.ResetRoundClock)
+		protected.POST("/matches/:id/extensions", handlers.AddMatchTimeExtension)
+
+		// Judging: infractions and penalties
+		protected.POST("/infractions", handlers.CreateInfraction)
+		protected.GET("/infractions", handlers.GetInfractions)
+		protected.GET("/players/:player_id/infractions", handlers.GetPlayerInfractions)
+
+		// Result corrections (reopen, void, scores of closed rounds and archives)
+		protected.POST("/matches/:id/corrections", handlers.CorrectMatch)
+		protected.POST("/tournaments/online/matches/:matchId/corrections", handlers.CorrectOnlineMatch)
+		protected.POST("/tournaments/:id/matches/:match_id/corrections", handlers.CorrectArchivedMatch)
+		protected.POST("/tournaments/:id/standings/recompute", handlers.RecomputeTournamentStandings)
+		protected.GET("/corrections", handlers.GetMatchCorrections)
+
+		// Clear tournament data
+		protected.DELETE("/tournament", handlers.ClearTournament)
+
+		// Tournament archiving
+		protected.POST("/tournaments/archive", handlers.ArchiveTournament)
+		protected.DELETE("/tournaments/:id", handlers.DeleteArchivedTournament)
+
+		// Tournament export/import (JSON bundle or CSV zip)
+		protected.GET("/tournaments/:id/export", handlers.ExportTournament)
+		protected.POST("/tournaments/import", handlers.ImportTournament)
+		protected.POST("/tournaments/import/bulk", handlers.BulkImportTournaments)
+
+		// Tournament lifecycle (draft, registration, in_progress, completed, archived)
+		protected.PATCH("/tournaments/:id/status", handlers.UpdateTournamentStatus)
+
+		// Tournament player race tracking
+		protected.PATCH("/tournaments/:id/players/:player_id/race", handlers.UpdatePlayerRace)
+
+		// Online tournament routes
+		protected.POST("/tournaments/online", handlers.CreateOnlineTournament)
+		protected.GET("/tournaments/online/:id/info", handlers.GetOnlineTournamentInfo)
+		protected.GET("/tournaments/online/:id/matches", handlers.GetOnlineTournamentMatches)
+		protected.GET("/tournaments/online/:id/matches/pending", handlers.GetOnlinePendingMatches)
+		protected.GET("/tournaments/online/:id/matches/completed", handlers.GetOnlineCompletedMatches)
+		protected.GET("/tournaments/online/:id/matches/expiring", handlers.GetOnlineExpiringMatches)
+		protected.GET("/tournaments/online/:id/standings", handlers.GetOnlineTournamentStandings)
+		protected.GET("/tournaments/online/matches/:matchId", handlers.GetOnlineMatch)
+		protected.PATCH("/tournaments/online/matches/:matchId", handlers.UpdateOnlineMatchScore)
+		protected.DELETE("/tournaments/online/:id", handlers.DeleteOnlineTournament)
+		protected.POST("/tournaments/online/:id/players", handlers.AddOnlineLateEntry)
+		protected.POST("/tournaments/online/:id/players/:player_id/drop", handlers.DropOnlinePlayer)
+		protected.POST("/tournaments/online/:id/players/:player_id/disqualify", handlers.DisqualifyOnlinePlayer)
+
+		// Online match deadlines
+		protected.PATCH("/tournaments/online/:id/deadline-policy", handlers.UpdateOnlineDeadlinePolicy)
+		protected.GET("/tournaments/online/:id/matchdays", handlers.GetOnlineMatchdays)
+		protected.POST("/tournaments/online/:id/matchdays", handlers.ScheduleOnlineMatchdays)
+		protected.PUT("/tournaments/online/:id/matchdays/:matchday", handlers.SetOnlineMatchdayDeadline)
+		protected.PATCH("/tournaments/online/matches/:matchId/deadline", handlers.SetOnlineMatchDeadline)
+		protected.POST("/tournaments/online/matches/:matchId/claim-forfeit", handlers.ClaimOnlineForfeit)
+
+		// Online match scheduling
+		protected.GET("/tournaments/online/matches/:matchId/proposals", handlers.GetMatchProposals)
+		protected.POST("/tournaments/online/matches/:matchId/proposals", handlers.CreateMatchProposal)
+		protected.POST("/tournaments/online/proposals/:proposalId/accept", handlers.AcceptMatchProposal)
+		protected.POST("/tournaments/online/proposals/:proposalId/decline", handlers.DeclineMatchProposal)
+		protected.POST("/tournaments/online/proposals/:proposalId/withdraw", handlers.WithdrawMatchProposal)
+
+		// Season management
+		protected.POST("/seasons", handlers.CreateSeason)
+		protected.PATCH("/seasons/:id", handlers.UpdateSeason)
+		protected.DELETE("/seasons/:id", handlers.DeleteSeason)
+		protected.POST("/seasons/:id/tournaments", handlers.AddSeasonTournament)
+		protected.DELETE("/seasons/:id/tournaments/:tournament_id", handlers.RemoveSeasonTournament)
+
+		// Webhooks (signed event deliveries with retries)
+		protected.POST("/webhooks", handlers.CreateWebhook)
+		protected.GET("/webhooks", handlers.GetWebhooks)
+		protected.PATCH("/webhooks/:id", handlers.UpdateWebhook)
+		protected.DELETE("/webhooks/:id", handlers.DeleteWebhook)
+		protected.POST("/webhooks/:id/test", handlers.TestWebhook)
+		protected.GET("/webhooks/:id/deliveries", handlers.GetWebhookDeliveries)
+		protected.POST("/webhooks/:id/deliveries/:delivery_id/retry", handlers.RetryWebhookDelivery)
+
+		// Discord bot: player links and text commands relayed by a bot
+		protected.GET("/discord/links", handlers.GetDiscordLinks)
+		protected.PUT("/discord/links/:discord_user_id", handlers.LinkDiscordUser)
+		protected.DELETE("/discord/links/:discord_user_id", handlers.UnlinkDiscordUser)
+		protected.POST("/discord/commands", handlers.RunDiscordCommand)
+
+		// Notifications
+		protected.GET("/players/:player_id/contact", handlers.GetPlayerContact)
+		protected.PUT("/players/:player_id/contact", handlers.UpdatePlayerContact)
+		protected.DELETE("/players/:player_id/contact", handlers.DeletePlayerContact)
+		protected.POST("/players/:id/contact/test", handlers.TestPlayerContact)
+		protected.GET("/notifications", handlers.GetNotifications)
+		protected.POST("/notifications/:id/retry", handlers.RetryNotification)
+	}
+	spec.AddRoutes(router.Routes(), true)
+
+	// API documentation
+	router.GET("/api/openapi.json", spec.ServeJSON)
+	router.GET("/api/docs", openapi.SwaggerUI("Premier Mitológico API", "/api/openapi.json"))
+
+	// Prometheus metrics, behind METRICS_TOKEN when it is set
+	router.GET("/metrics", gin.WrapH(metrics.Handler(os.Getenv("METRICS_TOKEN"))))
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/handlers"
+	"github.com/andreuvv/premier_mitologico/backend/internal/openapi"
+	"github.com/andreuvv/premier_mitologico/backend/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// TestEveryRouteHasAnEndpoint checks that each route served by a handler of the
+// handlers package is described in handlers.Endpoints
+func TestEveryRouteHasAnEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	spec := openapi.New(openapi.Info{Title: "test", Version: "test"}, handlers.Endpoints)
+	registerRoutes(router, spec, ratelimit.New(ratelimit.NewMemory(), ratelimit.Config{}))
+
+	described := map[string]bool{}
+	for _, e := range handlers.Endpoints {
+		described[runtime.FuncForPC(reflect.ValueOf(e.Handler).Pointer()).Name()] = true
+	}
+
+	prefix := reflect.TypeOf(handlers.WebhookList{}).PkgPath() + "."
+	checked := 0
+	for _, r := range router.Routes() {
+		if !strings.HasPrefix(r.Handler, prefix) {
+			continue
+		}
+		checked++
+		if !described[r.Handler] {
+			t.Errorf("%s %s: %s has no entry in handlers.Endpoints", r.Method, r.Path, strings.TrimPrefix(r.Handler, prefix))
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no route served by the handlers package")
+	}
+}
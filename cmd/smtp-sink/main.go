@@ -0,0 +1,130 @@
+// Command smtp-sink is a local stand-in for a mail server. It accepts every message
+// without TLS or authentication and prints it, so notifications can be tried without
+// sending real email.
+//
+// Usage:
+//
+//	go run ./cmd/smtp-sink [-addr :2525] [-fail]
+//
+// Run the server with SMTP_HOST=localhost and SMTP_PORT=2525. -fail rejects every
+// message with a temporary error, to watch the retries and backoff.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+)
+
+func main() {
+	addr := flag.String("addr", ":2525", "address to listen on")
+	fail := flag.Bool("fail", false, "reject every message with a temporary error")
+	flag.Parse()
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal("Failed to listen: ", err)
+	}
+	log.Printf("📭 SMTP sink listening on %s", *addr)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("⚠️  Accept: %v", err)
+			continue
+		}
+		go serve(conn, *fail)
+	}
+}
+
+// serve speaks just enough SMTP for net/smtp clients
+func serve(conn net.Conn, fail bool) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	reply("220 smtp-sink ready")
+	var from string
+	var to []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250 smtp-sink")
+		case "MAIL":
+			from, to = strings.TrimPrefix(arg, "FROM:"), nil
+			reply("250 OK")
+		case "RCPT":
+			to = append(to, strings.TrimPrefix(arg, "TO:"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readData(r)
+			if err != nil {
+				return
+			}
+			if fail {
+				log.Printf("❌ Rejected message from %s to %s", from, strings.Join(to, ", "))
+				reply("451 Rejected by smtp-sink -fail")
+				continue
+			}
+			printMessage(from, to, data)
+			reply("250 OK")
+		case "RSET":
+			from, to = "", nil
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// readData reads a message up to the line with a single dot, undoing dot-stuffing
+func readData(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if line == ".\r\n" || line == ".\n" {
+			return b.String(), nil
+		}
+		b.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+// printMessage logs a received message with its subject decoded
+func printMessage(from string, to []string, data string) {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		log.Printf("✅ Message from %s to %s (unparsed)\n%s", from, strings.Join(to, ", "), data)
+		return
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	body, _ := io.ReadAll(msg.Body)
+	log.Printf("✅ Message from %s to %s\nSubject: %s\n\n%s", from, strings.Join(to, ", "), subject,
+		strings.ReplaceAll(string(body), "\r\n", "\n"))
+}
@@ -0,0 +1,70 @@
+// Command webhook-listener is a local stand-in for a webhook receiver. It verifies the
+// signature of every delivery and prints the event, so webhooks can be tried without
+// a public endpoint.
+//
+// Usage:
+//
+//	go run ./cmd/webhook-listener -secret <webhook secret> [-addr :9090] [-status 500]
+//
+// Register http://localhost:9090/ as the webhook URL. -status answers every delivery
+// with that status code, to watch the retries and backoff.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	secret := flag.String("secret", "", "secret of the webhook, to verify signatures")
+	status := flag.Int("status", http.StatusOK, "status code to answer deliveries with")
+	tolerance := flag.Duration("tolerance", 5*time.Minute, "maximum age of a signature (0 accepts any)")
+	flag.Parse()
+
+	if *secret == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		delivery := r.Header.Get(webhooks.HeaderDelivery)
+		event := r.Header.Get(webhooks.HeaderEvent)
+		if err := webhooks.Verify(*secret, r.Header.Get(webhooks.HeaderSignature), body, *tolerance); err != nil {
+			log.Printf("❌ Delivery %s (%s) rejected: %v", delivery, event, err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "", "  "); err != nil {
+			pretty.Write(body)
+		}
+		log.Printf("✅ Delivery %s (%s), answering %d\n%s", delivery, event, *status, pretty.String())
+
+		w.WriteHeader(*status)
+		fmt.Fprintln(w, http.StatusText(*status))
+	})
+
+	log.Printf("👂 Listening for webhooks on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
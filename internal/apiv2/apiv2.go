@@ -0,0 +1,148 @@
+// Package apiv2 holds the conventions of the /api/v2 surface: every response is an
+// envelope with the result in "data" (and paging in "meta"), or an "error" with a
+// machine-readable code. Lists are paged with opaque cursors.
+package apiv2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Prefix of the v2 routes
+const Prefix = "/api/v2"
+
+// ErrorCode says what went wrong, independently of the message
+type ErrorCode string
+
+const (
+	CodeInvalidRequest   ErrorCode = "invalid_request"   // malformed parameter or body
+	CodeValidationFailed ErrorCode = "validation_failed" // the request breaks the OpenAPI document
+	CodeUnauthorized     ErrorCode = "unauthorized"
+	CodeNotFound         ErrorCode = "not_found"
+	CodeConflict         ErrorCode = "conflict"
+	CodeRateLimited      ErrorCode = "rate_limited" // too many requests; see Retry-After
+	CodeInternal         ErrorCode = "internal_error"
+)
+
+// statuses maps every code to its HTTP status
+var statuses = map[ErrorCode]int{
+	CodeInvalidRequest:   http.StatusBadRequest,
+	CodeValidationFailed: http.StatusBadRequest,
+	CodeUnauthorized:     http.StatusUnauthorized,
+	CodeNotFound:         http.StatusNotFound,
+	CodeConflict:         http.StatusConflict,
+	CodeRateLimited:      http.StatusTooManyRequests,
+	CodeInternal:         http.StatusInternalServerError,
+}
+
+// Envelope wraps every successful v2 response
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta *Meta       `json:"meta,omitempty"`
+}
+
+// Meta pages a list. NextCursor is empty on the last page.
+type Meta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// ErrorResponse wraps every failed v2 response
+type ErrorResponse struct {
+	Error ErrorInfo `json:"error"`
+}
+
+type ErrorInfo struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Details []string  `json:"details,omitempty"`
+}
+
+// OK answers 200 with data
+func OK(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusOK, Envelope{Data: data})
+}
+
+// List answers 200 with a page of items
+func List(c *gin.Context, items interface{}, meta Meta) {
+	c.JSON(http.StatusOK, Envelope{Data: items, Meta: &meta})
+}
+
+// Fail aborts with an error envelope
+func Fail(c *gin.Context, code ErrorCode, message string, details ...string) {
+	status, ok := statuses[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	c.AbortWithStatusJSON(status, ErrorResponse{Error: ErrorInfo{Code: code, Message: message, Details: details}})
+}
+
+// RejectInvalid answers requests that break the OpenAPI document
+func RejectInvalid(c *gin.Context, problems []string) {
+	Fail(c, CodeValidationFailed, problems[0], problems...)
+}
+
+// NoRoute answers unknown v2 paths with an error envelope, and others with gin's
+// plain 404
+func NoRoute(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, Prefix+"/") {
+		Fail(c, CodeNotFound, "No such endpoint")
+		return
+	}
+	c.String(http.StatusNotFound, "404 page not found")
+}
+
+// Paging defaults
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Page is the requested slice of a list: ?limit= and ?cursor=
+type Page struct {
+	Limit  int
+	Cursor string
+}
+
+// ParsePage reads the page of a list request, answering invalid_request when the
+// limit is out of range
+func ParsePage(c *gin.Context) (Page, bool) {
+	page := Page{Limit: DefaultLimit, Cursor: c.Query("cursor")}
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxLimit {
+			Fail(c, CodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(MaxLimit))
+			return page, false
+		}
+		page.Limit = n
+	}
+	return page, true
+}
+
+// ErrInvalidCursor is returned for cursors this API did not produce
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor turns the sort key of the last item of a page into a cursor
+func EncodeCursor(key interface{}) string {
+	b, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor reads a cursor made by EncodeCursor into key
+func DecodeCursor(cursor string, key interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, key); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
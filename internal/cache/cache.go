@@ -0,0 +1,157 @@
+// Package cache keeps computed JSON responses, such as the global statistics, so
+// they are not recomputed on every request. Entries live in a Store: in process by
+// default, or in Redis when REDIS_URL is set so every instance shares them.
+//
+// Entries belong to a tag. Invalidating a tag bumps its generation, which is part of
+// the key of its entries, so every entry of the tag is missed from then on and
+// expires by itself. Served entries carry an ETag and Last-Modified, and conditional
+// requests that still match get 304 Not Modified.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/env"
+	"github.com/andreuvv/premier_mitologico/backend/internal/logging"
+)
+
+// Store holds raw entries. Stores are shared by goroutines.
+type Store interface {
+	// Get returns the value of key, and false if it is missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Incr adds one to the counter under key, created at 0, and returns it
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// Config selects and sizes the store
+type Config struct {
+	TTL        time.Duration // how long an entry is kept if not invalidated
+	MaxEntries int           // entries kept in process
+	RedisURL   string        // redis://[:password@]host:port[/db]; empty keeps entries in process
+}
+
+// ConfigFromEnv reads CACHE_TTL (default 10m), CACHE_MAX_ENTRIES (default 1000) and
+// REDIS_URL
+func ConfigFromEnv() Config {
+	cfg := Config{
+		TTL:        env.Duration("CACHE_TTL", 10*time.Minute),
+		MaxEntries: env.Int("CACHE_MAX_ENTRIES", 1000, 1),
+		RedisURL:   os.Getenv("REDIS_URL"),
+	}
+	return cfg
+}
+
+// NewStore returns the Redis store if the config has a URL, else an in-process one
+func NewStore(cfg Config) (Store, error) {
+	if cfg.RedisURL == "" {
+		return NewMemory(cfg.MaxEntries), nil
+	}
+	return NewRedis(cfg.RedisURL)
+}
+
+var (
+	mu    sync.RWMutex
+	store Store = NewMemory(1000)
+	ttl         = 10 * time.Minute
+)
+
+// Use makes the cache keep its entries in s for the configured TTL
+func Use(s Store, cfg Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	store = s
+	ttl = cfg.TTL
+}
+
+func current() (Store, time.Duration) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return store, ttl
+}
+
+// keyPrefix keeps the keys of this API apart from others in a shared Redis
+const keyPrefix = "pm:cache:"
+
+// Entry is a cached JSON response
+type Entry struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// NewEntry marshals a response into an entry modified now
+func NewEntry(value interface{}) (*Entry, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	return &Entry{
+		Body:         body,
+		ETag:         `"` + hex.EncodeToString(sum[:12]) + `"`,
+		LastModified: time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
+
+// Load returns the entry of key under tag, computing and storing it if it is missing.
+// Store failures are logged and the value is computed as if nothing was cached.
+func Load(ctx context.Context, tag, key string, compute func() (interface{}, error)) (*Entry, error) {
+	s, ttl := current()
+
+	fullKey := ""
+	generation, err := getGeneration(ctx, s, tag)
+	if err != nil {
+		logging.FromContext(ctx).Warn("cache unavailable", "error", err)
+	} else {
+		fullKey = keyPrefix + tag + ":" + strconv.FormatInt(generation, 10) + ":" + key
+		if raw, ok, err := s.Get(ctx, fullKey); err != nil {
+			logging.FromContext(ctx).Warn("cache read failed", "key", fullKey, "error", err)
+		} else if ok {
+			var entry Entry
+			if err := json.Unmarshal(raw, &entry); err == nil {
+				return &entry, nil
+			}
+		}
+	}
+
+	value, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	entry, err := NewEntry(value)
+	if err != nil {
+		return nil, err
+	}
+	if fullKey != "" {
+		raw, _ := json.Marshal(entry)
+		if err := s.Set(ctx, fullKey, raw, ttl); err != nil {
+			logging.FromContext(ctx).Warn("cache write failed", "key", fullKey, "error", err)
+		}
+	}
+	return entry, nil
+}
+
+// Invalidate drops every entry of tag
+func Invalidate(ctx context.Context, tag string) {
+	s, _ := current()
+	if _, err := s.Incr(ctx, keyPrefix+"gen:"+tag); err != nil {
+		logging.FromContext(ctx).Warn("cache invalidation failed", "tag", tag, "error", err)
+	}
+}
+
+func getGeneration(ctx context.Context, s Store, tag string) (int64, error) {
+	raw, ok, err := s.Get(ctx, keyPrefix+"gen:"+tag)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return strconv.ParseInt(string(raw), 10, 64)
+}
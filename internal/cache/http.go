@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Serve answers with an entry, or with 304 Not Modified when the request already has
+// it: If-None-Match lists its ETag or, without If-None-Match, If-Modified-Since is
+// not older than it. Clients are asked to revalidate before reusing the answer.
+func Serve(c *gin.Context, entry *Entry) {
+	c.Header("ETag", entry.ETag)
+	c.Header("Last-Modified", entry.LastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "no-cache")
+
+	if notModified(c.Request, entry) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", entry.Body)
+}
+
+func notModified(r *http.Request, entry *Entry) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == entry.ETag {
+				return true
+			}
+		}
+		return false
+	}
+	if header := r.Header.Get("If-Modified-Since"); header != "" {
+		since, err := time.Parse(http.TimeFormat, header)
+		return err == nil && !entry.LastModified.After(since)
+	}
+	return false
+}
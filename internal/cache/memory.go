@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Memory is a Store in the memory of the process, holding up to a number of entries
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]memoryItem
+}
+
+type memoryItem struct {
+	value   []byte
+	expires time.Time // zero for counters, which never expire
+}
+
+// NewMemory returns an in-process store of up to maxEntries entries
+func NewMemory(maxEntries int) *Memory {
+	return &Memory{maxEntries: maxEntries, items: map[string]memoryItem{}}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !item.expires.IsZero() && time.Now().After(item.expires) {
+		delete(m.items, key)
+		return nil, false, nil
+	}
+	return item.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.items[key]; !exists && len(m.items) >= m.maxEntries {
+		m.evict()
+	}
+	m.items[key] = memoryItem{value: value, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *Memory) Incr(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n, _ := strconv.ParseInt(string(m.items[key].value), 10, 64)
+	n++
+	m.items[key] = memoryItem{value: []byte(strconv.FormatInt(n, 10))}
+	return n, nil
+}
+
+// evict makes room for an entry: expired entries go first, else any entry does.
+// Counters are kept.
+func (m *Memory) evict() {
+	now := time.Now()
+	for key, item := range m.items {
+		if !item.expires.IsZero() && now.After(item.expires) {
+			delete(m.items, key)
+		}
+	}
+	if len(m.items) < m.maxEntries {
+		return
+	}
+	for key, item := range m.items {
+		if !item.expires.IsZero() {
+			delete(m.items, key)
+			return
+		}
+	}
+}
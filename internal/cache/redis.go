@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redisTimeout bounds every command, so a slow Redis only slows requests down to
+// computing the value themselves
+const redisTimeout = 2 * time.Second
+
+// Redis is a Store in a Redis server, spoken to with the plain RESP protocol over a
+// few reused connections
+type Redis struct {
+	addr     string
+	username string
+	password string
+	db       int
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// errNil is the answer to GET of a missing key
+var errNil = errors.New("redis: nil")
+
+// NewRedis returns a store for redis://[[username]:password@]host:port[/db]
+func NewRedis(rawURL string) (*Redis, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "redis" || u.Host == "" {
+		return nil, fmt.Errorf("invalid REDIS_URL %q", rawURL)
+	}
+	r := &Redis{addr: u.Host, idle: make(chan *redisConn, 4)}
+	if !strings.Contains(u.Host, ":") {
+		r.addr += ":6379"
+	}
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+	}
+	if path := strings.TrimPrefix(u.Path, "/"); path != "" {
+		if r.db, err = strconv.Atoi(path); err != nil {
+			return nil, fmt.Errorf("invalid database in REDIS_URL %q", rawURL)
+		}
+	}
+	return r, nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.do(ctx, "GET", key)
+	if err == errNil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value.([]byte), true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := r.do(ctx, "SET", key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+func (r *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	value, err := r.do(ctx, "INCR", key)
+	if err != nil {
+		return 0, err
+	}
+	return value.(int64), nil
+}
+
+// Do sends a command and returns its answer: []byte, string, int64, or nil for a
+// missing value. Other packages use it to share the Redis of the cache.
+func (r *Redis) Do(ctx context.Context, args ...string) (interface{}, error) {
+	value, err := r.do(ctx, args...)
+	if err == errNil {
+		return nil, nil
+	}
+	return value, err
+}
+
+// do sends a command and reads its answer: []byte, string, int64 or errNil
+func (r *Redis) do(ctx context.Context, args ...string) (interface{}, error) {
+	c, err := r.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.command(ctx, args...)
+	if err != nil && err != errNil && !isServerError(err) {
+		c.conn.Close()
+		return nil, err
+	}
+	r.put(c)
+	return value, err
+}
+
+// get takes an idle connection or opens one, authenticated and on the database
+func (r *Redis) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-r.idle:
+		return c, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: redisTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	if r.password != "" {
+		auth := []string{"AUTH", r.password}
+		if r.username != "" {
+			auth = []string{"AUTH", r.username, r.password}
+		}
+		if _, err := c.command(ctx, auth...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.db != 0 {
+		if _, err := c.command(ctx, "SELECT", strconv.Itoa(r.db)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (r *Redis) put(c *redisConn) {
+	select {
+	case r.idle <- c:
+	default:
+		c.conn.Close()
+	}
+}
+
+// serverError is an error answered by Redis; the connection stays usable
+type serverError string
+
+func (e serverError) Error() string { return "redis: " + string(e) }
+
+func isServerError(err error) bool {
+	var e serverError
+	return errors.As(err, &e)
+}
+
+func (c *redisConn) command(ctx context.Context, args ...string) (interface{}, error) {
+	deadline := time.Now().Add(redisTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// read parses one RESP answer
+func (c *redisConn) read() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty answer")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, serverError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errNil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+	return nil, fmt.Errorf("redis: unexpected answer %q", line)
+}
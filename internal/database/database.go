@@ -1,16 +1,48 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"sync"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/andreuvv/premier_mitologico/backend/internal/env"
+	"github.com/andreuvv/premier_mitologico/backend/internal/metrics"
+	"github.com/lib/pq"
 )
 
 var DB *sql.DB
 
+// Querier runs statements on DB or in one of its transactions
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// PoolConfig sizes the connection pool of DB
+type PoolConfig struct {
+	MaxOpenConns    int           // open connections, in use or idle; 0 is no limit
+	MaxIdleConns    int           // idle connections kept for reuse
+	ConnMaxLifetime time.Duration // age at which a connection is closed
+	ConnMaxIdleTime time.Duration // idle time after which a connection is closed
+}
+
+// PoolConfigFromEnv reads DB_MAX_OPEN_CONNS (default 20), DB_MAX_IDLE_CONNS (default
+// 10), DB_CONN_MAX_LIFETIME (default 30m) and DB_CONN_MAX_IDLE_TIME (default 5m)
+func PoolConfigFromEnv() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    env.Int("DB_MAX_OPEN_CONNS", 20, 0),
+		MaxIdleConns:    env.Int("DB_MAX_IDLE_CONNS", 10, 0),
+		ConnMaxLifetime: env.Duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		ConnMaxIdleTime: env.Duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+	}
+}
+
+// Connect opens DB with the connection pool of PoolConfigFromEnv
 func Connect() error {
 	// Check for Railway's DATABASE_URL first
 	connStr := os.Getenv("DATABASE_URL")
@@ -28,23 +60,65 @@ func Connect() error {
 		)
 	}
 
-	var err error
-	DB, err = sql.Open("postgres", connStr)
+	connector, err := pq.NewConnector(connStr)
 	if err != nil {
 		return fmt.Errorf("error opening database: %w", err)
 	}
+	slowQuery = env.Duration("DB_SLOW_QUERY", 250*time.Millisecond)
+	DB = sql.OpenDB(timedConnector{connector})
+
+	pool := PoolConfigFromEnv()
+	DB.SetMaxOpenConns(pool.MaxOpenConns)
+	DB.SetMaxIdleConns(pool.MaxIdleConns)
+	DB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	DB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	if err = DB.Ping(); err != nil {
 		return fmt.Errorf("error connecting to database: %w", err)
 	}
 
-	log.Println("✓ Database connected successfully")
+	registerPoolMetrics.Do(poolMetrics)
+	slog.Info("database connected", "max_open_conns", pool.MaxOpenConns, "max_idle_conns", pool.MaxIdleConns,
+		"conn_max_lifetime", pool.ConnMaxLifetime, "conn_max_idle_time", pool.ConnMaxIdleTime)
 	return nil
 }
 
+// Ping checks that the database answers within ctx
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return fmt.Errorf("database not connected")
+	}
+	return DB.PingContext(ctx)
+}
+
 func Close() {
 	if DB != nil {
 		DB.Close()
-		log.Println("Database connection closed")
+		slog.Info("database connection closed")
+	}
+}
+
+var registerPoolMetrics sync.Once
+
+// poolMetrics exposes the connection pool statistics of DB
+func poolMetrics() {
+	stats := func(read func(sql.DBStats) float64) func() float64 {
+		return func() float64 { return read(DB.Stats()) }
 	}
+	metrics.NewGaugeFunc("db_pool_max_open_connections", "Maximum number of open connections",
+		stats(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	metrics.NewGaugeFunc("db_pool_open_connections", "Open connections, in use or idle",
+		stats(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	metrics.NewGaugeFunc("db_pool_in_use_connections", "Connections in use",
+		stats(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	metrics.NewGaugeFunc("db_pool_idle_connections", "Idle connections",
+		stats(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	metrics.NewCounterFunc("db_pool_wait_count_total", "Connections waited for",
+		stats(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	metrics.NewCounterFunc("db_pool_wait_duration_seconds_total", "Time spent waiting for a connection",
+		stats(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	metrics.NewCounterFunc("db_pool_max_idle_closed_total", "Connections closed because the pool had too many idle",
+		stats(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	metrics.NewCounterFunc("db_pool_max_lifetime_closed_total", "Connections closed because they reached their lifetime",
+		stats(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
 }
@@ -1,8 +1,9 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
@@ -23,7 +24,7 @@ func RunMigrations() error {
 	}
 
 	// Get list of applied migrations
-	appliedMigrations, err := getAppliedMigrations()
+	appliedMigrations, err := getAppliedMigrations(context.Background())
 	if err != nil {
 		return fmt.Errorf("failed to get applied migrations: %w", err)
 	}
@@ -32,33 +33,23 @@ func RunMigrations() error {
 	// mark old migrations as applied to avoid conflicts
 	if len(appliedMigrations) == 0 {
 		if err := markExistingMigrationsAsApplied(); err != nil {
-			log.Printf("⚠️  Warning: Could not mark existing migrations: %v", err)
+			slog.Warn("could not mark existing migrations", "error", err)
 		}
 		// Refresh the list of applied migrations
-		appliedMigrations, err = getAppliedMigrations()
+		appliedMigrations, err = getAppliedMigrations(context.Background())
 		if err != nil {
 			return fmt.Errorf("failed to get applied migrations: %w", err)
 		}
 	}
 
 	// Read migration files
-	migrationsDir := "migrations"
-	files, err := os.ReadDir(migrationsDir)
+	migrationFiles, err := listMigrations()
 	if err != nil {
 		// If migrations directory doesn't exist, skip migrations
-		log.Println("⚠️  No migrations directory found, skipping migrations")
+		slog.Warn("no migrations directory found, skipping migrations")
 		return nil
 	}
 
-	// Sort migration files
-	var migrationFiles []string
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			migrationFiles = append(migrationFiles, file.Name())
-		}
-	}
-	sort.Strings(migrationFiles)
-
 	// Apply pending migrations
 	pendingCount := 0
 	for _, filename := range migrationFiles {
@@ -77,7 +68,7 @@ func RunMigrations() error {
 		}
 
 		// Execute migration
-		log.Printf("📦 Applying migration: %s", filename)
+		slog.Info("applying migration", "file", filename)
 		if _, err := DB.Exec(string(content)); err != nil {
 			return fmt.Errorf("failed to apply migration %s: %w", filename, err)
 		}
@@ -88,18 +79,59 @@ func RunMigrations() error {
 		}
 
 		pendingCount++
-		log.Printf("✓ Migration applied: %s", filename)
+		slog.Info("migration applied", "file", filename)
 	}
 
 	if pendingCount == 0 {
-		log.Println("✓ All migrations up to date")
+		slog.Info("all migrations up to date")
 	} else {
-		log.Printf("✓ Applied %d migration(s)", pendingCount)
+		slog.Info("migrations applied", "count", pendingCount)
 	}
 
 	return nil
 }
 
+// migrationsDir holds the migration files, relative to the working directory
+const migrationsDir = "migrations"
+
+// listMigrations returns the names of the migration files, in the order they apply
+func listMigrations() ([]string, error) {
+	files, err := os.ReadDir(migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	var migrationFiles []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
+			migrationFiles = append(migrationFiles, file.Name())
+		}
+	}
+	sort.Strings(migrationFiles)
+	return migrationFiles, nil
+}
+
+// PendingMigrations returns the versions of the migration files not applied to the
+// database yet. Without a migrations directory there is nothing to apply.
+func PendingMigrations(ctx context.Context) ([]string, error) {
+	migrationFiles, err := listMigrations()
+	if err != nil {
+		return nil, nil
+	}
+
+	applied, err := getAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, filename := range migrationFiles {
+		if version := strings.TrimSuffix(filename, ".sql"); !applied[version] {
+			pending = append(pending, version)
+		}
+	}
+	return pending, nil
+}
+
 func markExistingMigrationsAsApplied() error {
 	// Check if standings view exists
 	var viewExists bool
@@ -114,7 +146,7 @@ func markExistingMigrationsAsApplied() error {
 	}
 
 	if viewExists {
-		log.Println("📋 Detected existing database schema, marking old migrations as applied...")
+		slog.Info("detected existing database schema, marking old migrations as applied")
 		// Mark migrations 001, 002, and 003 as applied since they were run manually
 		oldMigrations := []string{
 			"001_initial_schema",
@@ -125,15 +157,15 @@ func markExistingMigrationsAsApplied() error {
 			if err := recordMigration(migration); err != nil {
 				return err
 			}
-			log.Printf("✓ Marked as applied: %s.sql", migration)
+			slog.Info("migration marked as applied", "file", migration+".sql")
 		}
 	}
 
 	return nil
 }
 
-func getAppliedMigrations() (map[string]bool, error) {
-	rows, err := DB.Query("SELECT version FROM schema_migrations")
+func getAppliedMigrations(ctx context.Context) (map[string]bool, error) {
+	rows, err := DB.QueryContext(ctx, "SELECT version FROM schema_migrations")
 	if err != nil {
 		return nil, err
 	}
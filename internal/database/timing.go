@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/logging"
+	"github.com/andreuvv/premier_mitologico/backend/internal/metrics"
+)
+
+var (
+	queryDuration = metrics.NewHistogramVec("db_query_duration_seconds",
+		"Time to run a SQL statement, until its first row", metrics.DefaultBuckets, "operation")
+	queryErrors = metrics.NewCounterVec("db_query_errors_total",
+		"SQL statements that failed", "operation")
+)
+
+// slowQuery (DB_SLOW_QUERY, read by Connect) is the duration from which statements
+// are logged as warnings; faster ones are logged at debug level
+var slowQuery = 250 * time.Millisecond
+
+// timedConnector opens connections that time and count every statement
+type timedConnector struct {
+	driver.Connector
+}
+
+func (t timedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := t.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &timedConn{Conn: conn}, nil
+}
+
+// timedConn passes every call to the driver connection, timing queries and execs
+type timedConn struct {
+	driver.Conn
+}
+
+func (c *timedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	observe(ctx, query, start, err)
+	return rows, err
+}
+
+func (c *timedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	observe(ctx, query, start, err)
+	return result, err
+}
+
+func (c *timedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *timedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *timedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *timedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *timedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// observe records a statement in the metrics and the log
+func observe(ctx context.Context, query string, start time.Time, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	elapsed := time.Since(start)
+	operation := operationOf(query)
+	queryDuration.Observe(elapsed.Seconds(), operation)
+
+	logger := logging.FromContext(ctx)
+	switch {
+	case err != nil && err != driver.ErrBadConn:
+		queryErrors.Inc(operation)
+		logger.Warn("sql failed", "operation", operation, "duration_ms", elapsed.Milliseconds(), "query", compact(query), "error", err)
+	case elapsed >= slowQuery:
+		logger.Warn("slow sql", "operation", operation, "duration_ms", elapsed.Milliseconds(), "query", compact(query))
+	default:
+		logger.Debug("sql", "operation", operation, "duration_ms", elapsed.Milliseconds(), "query", compact(query))
+	}
+}
+
+// operationOf is the first keyword of a statement, e.g. SELECT, the label of its metrics
+func operationOf(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "OTHER"
+	}
+	switch op := strings.ToUpper(fields[0]); op {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH", "REFRESH", "BEGIN", "COMMIT", "ROLLBACK":
+		return op
+	}
+	return "OTHER"
+}
+
+// compact puts a statement on one line, cut to 300 characters
+func compact(query string) string {
+	q := strings.Join(strings.Fields(query), " ")
+	if len(q) > 300 {
+		q = q[:300] + "…"
+	}
+	return q
+}
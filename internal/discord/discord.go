@@ -0,0 +1,195 @@
+// Package discord posts tournament pairings, results and standings to Discord channels
+// and understands the bot commands players use from Discord. The Discord API is
+// behind the Client interface; Fake stands in for it locally and in tests.
+package discord
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultAPIURL is the Discord REST API the bot talks to
+const DefaultAPIURL = "https://discord.com/api/v10"
+
+// maxMessageLength is the longest message Discord accepts
+const maxMessageLength = 2000
+
+// Config holds the bot credentials and the channels events are posted to. An empty
+// channel ID turns that kind of post off.
+type Config struct {
+	Token     string
+	PublicKey ed25519.PublicKey // verifies the interactions Discord sends to the API
+	APIURL    string
+
+	PairingsChannel  string // new rounds
+	ResultsChannel   string // completed matches
+	StandingsChannel string // standings after results, locked rounds and finished tournaments
+
+	Fake bool // log messages instead of sending them
+}
+
+// ConfigFromEnv reads DISCORD_BOT_TOKEN, DISCORD_PUBLIC_KEY (hex), DISCORD_API_URL,
+// DISCORD_PAIRINGS_CHANNEL_ID, DISCORD_RESULTS_CHANNEL_ID, DISCORD_STANDINGS_CHANNEL_ID
+// and DISCORD_FAKE
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Token:            os.Getenv("DISCORD_BOT_TOKEN"),
+		APIURL:           os.Getenv("DISCORD_API_URL"),
+		PairingsChannel:  os.Getenv("DISCORD_PAIRINGS_CHANNEL_ID"),
+		ResultsChannel:   os.Getenv("DISCORD_RESULTS_CHANNEL_ID"),
+		StandingsChannel: os.Getenv("DISCORD_STANDINGS_CHANNEL_ID"),
+		Fake:             os.Getenv("DISCORD_FAKE") == "true",
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	if value := os.Getenv("DISCORD_PUBLIC_KEY"); value != "" {
+		key, err := hex.DecodeString(value)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			slog.Warn("invalid DISCORD_PUBLIC_KEY, Discord commands are disabled")
+		} else {
+			cfg.PublicKey = key
+		}
+	}
+	return cfg
+}
+
+// Enabled reports whether the bot has a token, or runs against the fake
+func (cfg Config) Enabled() bool {
+	return cfg.Token != "" || cfg.Fake
+}
+
+// Client sends messages to Discord channels
+type Client interface {
+	SendMessage(ctx context.Context, channelID, content string) error
+}
+
+// NewClient returns the client for cfg: the Discord API, or a logging Fake
+func NewClient(cfg Config) Client {
+	if cfg.Fake {
+		return NewFake(true)
+	}
+	return NewAPIClient(cfg.Token, cfg.APIURL)
+}
+
+// APIClient is a Client calling the Discord REST API as a bot
+type APIClient struct {
+	token   string
+	baseURL string
+	http    *http.Client
+}
+
+// NewAPIClient returns a client for the bot token; baseURL defaults to DefaultAPIURL
+func NewAPIClient(token, baseURL string) *APIClient {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &APIClient{
+		token:   token,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendMessage posts content to a channel. Mentions in the content are not pinged.
+func (c *APIClient) SendMessage(ctx context.Context, channelID, content string) error {
+	return c.do(ctx, http.MethodPost, "/channels/"+channelID+"/messages", map[string]interface{}{
+		"content":          truncate(content),
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	})
+}
+
+// RegisterCommands creates or replaces the bot slash commands of an application
+func (c *APIClient) RegisterCommands(ctx context.Context, applicationID string) error {
+	return c.do(ctx, http.MethodPut, "/applications/"+applicationID+"/commands", Commands)
+}
+
+func (c *APIClient) do(ctx context.Context, method, path string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bot "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("discord answered %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// truncate cuts a message to the length Discord accepts
+func truncate(content string) string {
+	if utf8.RuneCountInString(content) <= maxMessageLength {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:maxMessageLength-1]) + "…"
+}
+
+// Message is a message sent through the Fake
+type Message struct {
+	ChannelID string
+	Content   string
+}
+
+// Fake is a Client that keeps the messages in memory instead of sending them
+type Fake struct {
+	mu       sync.Mutex
+	messages []Message
+	log      bool
+}
+
+// NewFake returns an empty Fake; with logging on, every message is also written to
+// the log
+func NewFake(logging bool) *Fake {
+	return &Fake{log: logging}
+}
+
+// SendMessage records the message
+func (f *Fake) SendMessage(ctx context.Context, channelID, content string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, Message{ChannelID: channelID, Content: truncate(content)})
+	if f.log {
+		slog.Info("discord message", "channel", channelID, "content", content)
+	}
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (f *Fake) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.messages...)
+}
+
+// Reset forgets the messages sent so far
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = nil
+}
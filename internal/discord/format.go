@@ -0,0 +1,177 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+)
+
+// Messages are in Spanish, like the printouts players read at the venue
+
+// FormatPairings announces the pairings of a new round
+func FormatPairings(round models.FixtureRound) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Ronda %d (%s)**: emparejamientos\n", round.Number, round.Format)
+	for _, m := range round.Matches {
+		table := "-"
+		if m.TableNumber != nil {
+			table = fmt.Sprint(*m.TableNumber)
+		}
+		switch {
+		case m.Player2Name == "BYE":
+			fmt.Fprintf(&b, "Mesa %s: %s (BYE)\n", table, m.Player1Name)
+		case m.Player1Name == "BYE":
+			fmt.Fprintf(&b, "Mesa %s: %s (BYE)\n", table, m.Player2Name)
+		default:
+			fmt.Fprintf(&b, "Mesa %s: %s vs %s\n", table, m.Player1Name, m.Player2Name)
+		}
+	}
+	return b.String()
+}
+
+// FormatMatchResult announces the result of an in-person match
+func FormatMatchResult(m models.MatchDetail) string {
+	return fmt.Sprintf("Ronda %d: %s", m.RoundNumber, formatScore(m.Player1Name, m.Player2Name, m.Score1, m.Score2, m.ResultType))
+}
+
+// FormatOnlineResult announces the result of an online match
+func FormatOnlineResult(m models.OnlineTournamentMatch, tournament string) string {
+	prefix := tournament
+	if m.Matchday != nil {
+		prefix = fmt.Sprintf("%s, fecha %d", tournament, *m.Matchday)
+	}
+	return fmt.Sprintf("%s: %s", prefix, formatScore(m.Player1Name, m.Player2Name, m.Score1, m.Score2, m.ResultType))
+}
+
+// formatScore writes a result with the winner in bold
+func formatScore(player1, player2 string, score1, score2 *int, resultType string) string {
+	switch resultType {
+	case models.MatchResultVoid:
+		return fmt.Sprintf("%s vs %s, partida anulada", player1, player2)
+	case models.MatchResultDoubleLoss:
+		return fmt.Sprintf("%s vs %s, derrota para ambos", player1, player2)
+	}
+	if score1 == nil || score2 == nil {
+		return fmt.Sprintf("%s vs %s", player1, player2)
+	}
+
+	if *score1 > *score2 {
+		player1 = "**" + player1 + "**"
+	} else if *score2 > *score1 {
+		player2 = "**" + player2 + "**"
+	}
+	text := fmt.Sprintf("%s %d-%d %s", player1, *score1, *score2, player2)
+	if resultType == models.MatchResultForfeit {
+		text += " (por W.O.)"
+	}
+	return text
+}
+
+// StandingRow is a line of a standings table
+type StandingRow struct {
+	Name          string
+	Status        string
+	MatchesPlayed int
+	Wins          int
+	Ties          int
+	Losses        int
+	Points        int
+}
+
+// InPersonStandings converts the standings of the running in-person tournament
+func InPersonStandings(standings []models.Standing) []StandingRow {
+	rows := make([]StandingRow, 0, len(standings))
+	for _, s := range standings {
+		rows = append(rows, StandingRow{s.Name, s.Status, s.MatchesPlayed, s.Wins, s.Ties, s.Losses, s.Points})
+	}
+	return rows
+}
+
+// OnlineStandings converts the standings of an online tournament
+func OnlineStandings(standings []models.OnlineTournamentStanding) []StandingRow {
+	rows := make([]StandingRow, 0, len(standings))
+	for _, s := range standings {
+		rows = append(rows, StandingRow{s.PlayerName, s.Status, s.MatchesPlayed, s.Wins, s.Ties, s.Losses, s.Points})
+	}
+	return rows
+}
+
+// FormatStandings writes a standings table as a code block, in the given order
+func FormatStandings(title string, rows []StandingRow) string {
+	if len(rows) == 0 {
+		return fmt.Sprintf("**%s**\nTodavía no hay resultados.", title)
+	}
+
+	names := make([]string, len(rows))
+	width := len("Jugador")
+	for i, r := range rows {
+		names[i] = r.Name
+		switch r.Status {
+		case models.PlayerStatusDisqualified:
+			names[i] += " (DQ)"
+		case models.PlayerStatusDropped:
+			names[i] += " (retirado)"
+		}
+		if n := utf8.RuneCountInString(names[i]); n > width {
+			width = n
+		}
+	}
+	if width > 24 {
+		width = 24
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**\n```\n", title)
+	fmt.Fprintf(&b, "%3s  %s  %2s %2s %2s %2s %3s\n", "#", pad("Jugador", width), "PJ", "G", "E", "P", "Pts")
+	for i, r := range rows {
+		fmt.Fprintf(&b, "%3d  %s  %2d %2d %2d %2d %3d\n", i+1, pad(names[i], width), r.MatchesPlayed, r.Wins, r.Ties, r.Losses, r.Points)
+	}
+	b.WriteString("```")
+	return b.String()
+}
+
+// pad fits a name to width runes
+func pad(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		return string([]rune(s)[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// FormatPendingMatches lists the pending matches of a player in an online tournament
+func FormatPendingMatches(player, tournament string, playerID int, matches []models.OnlineTournamentMatch) string {
+	if len(matches) == 0 {
+		return fmt.Sprintf("%s, no tienes partidas pendientes en %s.", player, tournament)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Tus partidas pendientes en %s**\n", tournament)
+	for _, m := range matches {
+		fmt.Fprintf(&b, "- vs %s", Opponent(m, playerID))
+		if m.Matchday != nil {
+			fmt.Fprintf(&b, " (fecha %d)", *m.Matchday)
+		}
+		if m.Deadline != nil {
+			fmt.Fprintf(&b, ", plazo <t:%d:f>", m.Deadline.Unix())
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Informa el resultado con `/report 2-1`, con tu puntaje primero.")
+	return b.String()
+}
+
+// Opponent returns the rival of a player in a match
+func Opponent(m models.OnlineTournamentMatch, playerID int) string {
+	if m.Player1ID == playerID {
+		return m.Player2Name
+	}
+	return m.Player1Name
+}
+
+// FormatReported confirms a result reported from Discord
+func FormatReported(m models.OnlineTournamentMatch) string {
+	return "Resultado registrado: " + formatScore(m.Player1Name, m.Player2Name, m.Score1, m.Score2, models.MatchResultPlayed)
+}
@@ -0,0 +1,214 @@
+package discord
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bot commands
+const (
+	CommandReport    = "report"    // /report 2-1 [opponent]: report the result of a pending match
+	CommandStandings = "standings" // /standings [tournament]: standings of an online tournament
+	CommandMyMatch   = "mymatch"   // /mymatch [tournament]: pending matches of the player
+)
+
+// Option types of the slash command definitions
+const (
+	optionString  = 3
+	optionInteger = 4
+)
+
+// Commands are the slash command definitions registered with Discord
+var Commands = []map[string]interface{}{
+	{
+		"name":        CommandReport,
+		"description": "Informa el resultado de tu partida pendiente",
+		"options": []map[string]interface{}{
+			{"name": "score", "description": "Tu resultado primero, por ejemplo 2-1", "type": optionString, "required": true},
+			{"name": "opponent", "description": "Tu rival, si tienes más de una partida pendiente", "type": optionString},
+			{"name": "tournament", "description": "ID del torneo online", "type": optionInteger},
+		},
+	},
+	{
+		"name":        CommandStandings,
+		"description": "Muestra la tabla del torneo online",
+		"options": []map[string]interface{}{
+			{"name": "tournament", "description": "ID del torneo online", "type": optionInteger},
+		},
+	},
+	{
+		"name":        CommandMyMatch,
+		"description": "Muestra tus partidas pendientes",
+		"options": []map[string]interface{}{
+			{"name": "tournament", "description": "ID del torneo online", "type": optionInteger},
+		},
+	},
+}
+
+// Command is a bot command, from a slash command or from a "/report 2-1" message
+type Command struct {
+	Name       string
+	Score      string // report: the score of the reporting player first, e.g. "2-1"
+	Opponent   string // report: needed when the player has several pending matches
+	Tournament int    // online tournament ID; 0 picks the running one
+}
+
+// ParseCommand reads a slash-style message: "/report 2-1 [opponent]",
+// "/standings [tournament]" or "/mymatch [tournament]". Errors are meant for the
+// player, in Spanish.
+func ParseCommand(text string) (Command, error) {
+	fields := strings.Fields(strings.TrimSpace(text))
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return Command{}, errors.New("los comandos empiezan con /")
+	}
+	cmd := Command{Name: strings.ToLower(strings.TrimPrefix(fields[0], "/"))}
+	args := fields[1:]
+
+	switch cmd.Name {
+	case CommandReport:
+		if len(args) == 0 {
+			return cmd, errors.New("uso: /report 2-1 [rival]")
+		}
+		cmd.Score = args[0]
+		cmd.Opponent = strings.Join(args[1:], " ")
+	case CommandStandings, CommandMyMatch:
+		if len(args) > 0 {
+			id, err := strconv.Atoi(args[0])
+			if err != nil || id < 1 {
+				return cmd, fmt.Errorf("uso: /%s [id del torneo]", cmd.Name)
+			}
+			cmd.Tournament = id
+		}
+	default:
+		return cmd, fmt.Errorf("comando desconocido /%s", cmd.Name)
+	}
+	return cmd, nil
+}
+
+// ParseScore reads a score such as "2-1" into the two scores
+func ParseScore(score string) (int, int, error) {
+	left, right, ok := strings.Cut(strings.ReplaceAll(score, " ", ""), "-")
+	if !ok {
+		return 0, 0, errors.New("el resultado debe ser como 2-1, con tu puntaje primero")
+	}
+	a, errA := strconv.Atoi(left)
+	b, errB := strconv.Atoi(right)
+	if errA != nil || errB != nil || a < 0 || b < 0 {
+		return 0, 0, errors.New("el resultado debe ser como 2-1, con tu puntaje primero")
+	}
+	return a, b, nil
+}
+
+// Interaction types and responses, as sent and expected by Discord
+const (
+	InteractionPing               = 1
+	InteractionApplicationCommand = 2
+
+	ResponsePong           = 1
+	ResponseChannelMessage = 4
+
+	// FlagEphemeral shows a response only to the user who ran the command
+	FlagEphemeral = 1 << 6
+)
+
+// Interaction is a request Discord sends to the interactions endpoint
+type Interaction struct {
+	Type   int          `json:"type"`
+	Data   *CommandData `json:"data"`
+	Member *Member      `json:"member"` // set in a server channel
+	User   *User        `json:"user"`   // set in a direct message
+}
+
+type CommandData struct {
+	Name    string          `json:"name"`
+	Options []CommandOption `json:"options"`
+}
+
+type CommandOption struct {
+	Name  string      `json:"name"`
+	Type  int         `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type Member struct {
+	User *User `json:"user"`
+}
+
+type User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// Invoker returns the user who ran the command
+func (i Interaction) Invoker() *User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// Command converts a slash command into a Command
+func (i Interaction) Command() Command {
+	if i.Data == nil {
+		return Command{}
+	}
+	cmd := Command{Name: i.Data.Name}
+	for _, o := range i.Data.Options {
+		switch o.Name {
+		case "score":
+			cmd.Score = fmt.Sprint(o.Value)
+		case "opponent":
+			cmd.Opponent = fmt.Sprint(o.Value)
+		case "tournament":
+			// JSON numbers decode as float64
+			if v, ok := o.Value.(float64); ok {
+				cmd.Tournament = int(v)
+			}
+		}
+	}
+	return cmd
+}
+
+// InteractionResponse answers an interaction
+type InteractionResponse struct {
+	Type int           `json:"type"`
+	Data *ResponseData `json:"data,omitempty"`
+}
+
+type ResponseData struct {
+	Content         string                 `json:"content"`
+	Flags           int                    `json:"flags,omitempty"`
+	AllowedMentions map[string]interface{} `json:"allowed_mentions"`
+}
+
+// Pong answers the ping Discord sends when the endpoint is configured
+func Pong() InteractionResponse {
+	return InteractionResponse{Type: ResponsePong}
+}
+
+// Reply answers a command with a message, only visible to its user if ephemeral
+func Reply(content string, ephemeral bool) InteractionResponse {
+	data := &ResponseData{
+		Content:         truncate(content),
+		AllowedMentions: map[string]interface{}{"parse": []string{}},
+	}
+	if ephemeral {
+		data.Flags = FlagEphemeral
+	}
+	return InteractionResponse{Type: ResponseChannelMessage, Data: data}
+}
+
+// VerifyInteraction checks the X-Signature-Ed25519 and X-Signature-Timestamp headers
+// Discord signs every interaction with
+func VerifyInteraction(publicKey ed25519.PublicKey, signature, timestamp string, body []byte) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize || len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	message := append([]byte(timestamp), body...)
+	return ed25519.Verify(publicKey, message, sig)
+}
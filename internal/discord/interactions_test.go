@@ -0,0 +1,113 @@
+package discord
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Command
+		wantErr string
+	}{
+		{"/report 2-1", Command{Name: CommandReport, Score: "2-1"}, ""},
+		{"  /REPORT 0-2 Juan Pérez ", Command{Name: CommandReport, Score: "0-2", Opponent: "Juan Pérez"}, ""},
+		{"/standings", Command{Name: CommandStandings}, ""},
+		{"/standings 12", Command{Name: CommandStandings, Tournament: 12}, ""},
+		{"/mymatch 3", Command{Name: CommandMyMatch, Tournament: 3}, ""},
+		{"/report", Command{}, "uso: /report 2-1 [rival]"},
+		{"/standings abc", Command{}, "uso: /standings [id del torneo]"},
+		{"/mymatch 0", Command{}, "uso: /mymatch [id del torneo]"},
+		{"/ranking", Command{}, "comando desconocido /ranking"},
+		{"report 2-1", Command{}, "los comandos empiezan con /"},
+		{"", Command{}, "los comandos empiezan con /"},
+	}
+	for _, tt := range tests {
+		got, err := ParseCommand(tt.text)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ParseCommand(%q) error = %v, want %q", tt.text, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCommand(%q) error = %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCommand(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseScore(t *testing.T) {
+	tests := []struct {
+		score      string
+		own, other int
+		ok         bool
+	}{
+		{"2-1", 2, 1, true},
+		{"0 - 2", 0, 2, true},
+		{"10-0", 10, 0, true},
+		{"2:1", 0, 0, false},
+		{"2-", 0, 0, false},
+		{"-1-2", 0, 0, false},
+		{"a-b", 0, 0, false},
+	}
+	for _, tt := range tests {
+		own, other, err := ParseScore(tt.score)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseScore(%q) error = %v, want ok %v", tt.score, err, tt.ok)
+			continue
+		}
+		if own != tt.own || other != tt.other {
+			t.Errorf("ParseScore(%q) = %d, %d, want %d, %d", tt.score, own, other, tt.own, tt.other)
+		}
+	}
+}
+
+func TestInteractionCommand(t *testing.T) {
+	interaction := Interaction{
+		Type: InteractionApplicationCommand,
+		Data: &CommandData{Name: CommandReport, Options: []CommandOption{
+			{Name: "score", Type: optionString, Value: "2-0"},
+			{Name: "opponent", Type: optionString, Value: "Ana"},
+			{Name: "tournament", Type: optionInteger, Value: float64(4)},
+		}},
+		Member: &Member{User: &User{ID: "42"}},
+	}
+	want := Command{Name: CommandReport, Score: "2-0", Opponent: "Ana", Tournament: 4}
+	if got := interaction.Command(); got != want {
+		t.Errorf("Command() = %+v, want %+v", got, want)
+	}
+	if user := interaction.Invoker(); user == nil || user.ID != "42" {
+		t.Errorf("Invoker() = %+v, want the member's user", user)
+	}
+	if got := (Interaction{Type: InteractionPing}).Command(); got != (Command{}) {
+		t.Errorf("Command() of a ping = %+v, want none", got)
+	}
+}
+
+func TestVerifyInteraction(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte(`{"type":1}`)
+	signature := hex.EncodeToString(ed25519.Sign(private, append([]byte("1700000000"), body...)))
+
+	if !VerifyInteraction(public, signature, "1700000000", body) {
+		t.Error("valid signature rejected")
+	}
+	if VerifyInteraction(public, signature, "1700000001", body) {
+		t.Error("signature accepted with another timestamp")
+	}
+	if VerifyInteraction(public, signature, "1700000000", []byte(`{"type":2}`)) {
+		t.Error("signature accepted for another body")
+	}
+	if VerifyInteraction(public, "not hex", "1700000000", body) {
+		t.Error("malformed signature accepted")
+	}
+}
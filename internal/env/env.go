@@ -0,0 +1,39 @@
+// Package env reads settings from environment variables. An unset variable gives its
+// default; an invalid one is logged and gives its default too, so a typo never keeps
+// the server from starting.
+package env
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Duration reads a positive Go duration, e.g. "30s" or "5m"
+func Duration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		slog.Warn("invalid duration, using default", "variable", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return d
+}
+
+// Int reads an integer of at least min
+func Int(key string, fallback, min int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min {
+		slog.Warn("invalid number, using default", "variable", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return n
+}
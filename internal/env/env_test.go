@@ -0,0 +1,47 @@
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", time.Minute},
+		{"30s", 30 * time.Second},
+		{"2h", 2 * time.Hour},
+		{"0s", time.Minute},
+		{"-5m", time.Minute},
+		{"ten minutes", time.Minute},
+	}
+	for _, tt := range tests {
+		t.Setenv("TEST_DURATION", tt.value)
+		if got := Duration("TEST_DURATION", time.Minute); got != tt.want {
+			t.Errorf("Duration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		value string
+		min   int
+		want  int
+	}{
+		{"", 0, 10},
+		{"25", 0, 25},
+		{"0", 0, 0},
+		{"0", 1, 10},
+		{"-1", 0, 10},
+		{"many", 0, 10},
+	}
+	for _, tt := range tests {
+		t.Setenv("TEST_INT", tt.value)
+		if got := Int("TEST_INT", 10, tt.min); got != tt.want {
+			t.Errorf("Int(%q, min %d) = %d, want %d", tt.value, tt.min, got, tt.want)
+		}
+	}
+}
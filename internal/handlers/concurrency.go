@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// versionETag formats a match version as a strong ETag
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatch reports whether the If-Match header of the request accepts the current
+// version. Without the header every write is accepted, so existing clients keep working.
+func ifMatch(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == versionETag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// respondVersionConflict rejects a stale write with the current state of the match
+func respondVersionConflict(c *gin.Context, version int, current interface{}) {
+	c.Header("ETag", versionETag(version))
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   "Match was updated by someone else; review the current result and retry with its ETag",
+		"current": current,
+	})
+}
+
+// GetMatch returns a match of the in-person fixture with its version as ETag
+func GetMatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+
+	match, err := fetchMatchDetail(ctx, database.DB, matchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+
+	c.Header("ETag", versionETag(match.Version))
+	c.JSON(http.StatusOK, match)
+}
+
+// GetOnlineMatch returns a match of an online tournament with its version as ETag
+func GetOnlineMatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID, err := strconv.Atoi(c.Param("matchId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+
+	match, err := fetchOnlineMatch(ctx, database.DB, matchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+
+	c.Header("ETag", versionETag(match.Version))
+	c.JSON(http.StatusOK, match)
+}
+
+// fetchOnlineMatch loads a single online tournament match
+func fetchOnlineMatch(ctx context.Context, db database.Querier, matchID int) (*models.OnlineTournamentMatch, error) {
+	var match models.OnlineTournamentMatch
+	err := db.QueryRowContext(ctx, `
+		SELECT 
+			id,
+			tournament_id,
+			player1_id,
+			player2_id,
+			player1_name,
+			player2_name,
+			score1,
+			score2,
+			completed,
+			result_type,
+			match_date,
+			matchday,
+			deadline,
+			forfeit_claimed_by,
+			version,
+			created_at,
+			updated_at
+		FROM online_tournament_matches
+		WHERE id = $1
+	`, matchID).Scan(
+		&match.ID,
+		&match.TournamentID,
+		&match.Player1ID,
+		&match.Player2ID,
+		&match.Player1Name,
+		&match.Player2Name,
+		&match.Score1,
+		&match.Score2,
+		&match.Completed,
+		&match.ResultType,
+		&match.MatchDate,
+		&match.Matchday,
+		&match.Deadline,
+		&match.ClaimedBy,
+		&match.Version,
+		&match.CreatedAt,
+		&match.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
@@ -0,0 +1,651 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/playerstats"
+	"github.com/andreuvv/premier_mitologico/backend/internal/roundclock"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+// bindCorrection reads a correction request; correcting the score requires both scores
+func bindCorrection(c *gin.Context) (models.MatchCorrectionRequest, bool) {
+	var req models.MatchCorrectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	if req.Action == models.CorrectionScore && (req.Score1 == nil || req.Score2 == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "score1 and score2 are required to correct the score"})
+		return req, false
+	}
+	return req, true
+}
+
+// recordCorrection logs a correction. The correction must hold the match and its
+// previous result; the new result is taken from the request.
+func recordCorrection(ctx context.Context, tx *sql.Tx, correction *models.MatchCorrection, req models.MatchCorrectionRequest) error {
+	correction.Action = req.Action
+	correction.Reason = req.Reason
+	if req.Action == models.CorrectionScore {
+		correction.Score1 = req.Score1
+		correction.Score2 = req.Score2
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO match_corrections (
+			tournament_id, match_source, match_id, round_number, player1_name, player2_name, action,
+			previous_score1, previous_score2, previous_completed, previous_result_type,
+			score1, score2, reason
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at
+	`,
+		correction.TournamentID, correction.MatchSource, correction.MatchID, correction.RoundNumber,
+		correction.Player1Name, correction.Player2Name, correction.Action,
+		correction.PreviousScore1, correction.PreviousScore2, correction.PreviousCompleted, correction.PreviousResultType,
+		correction.Score1, correction.Score2, correction.Reason,
+	).Scan(&correction.ID, &correction.CreatedAt)
+}
+
+// CorrectMatch corrects the result of an in-person match of the running tournament,
+// also in a round that is already over or locked: a new score, reopening the match so
+// it can be played again, or voiding it. Like UpdateMatchScore it honours If-Match.
+func CorrectMatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+
+	req, ok := bindCorrection(c)
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	correction := models.MatchCorrection{MatchSource: models.MatchSourceInPerson, MatchID: matchID}
+	var version int
+	var locked bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT m.version, r.locked_at IS NOT NULL, r.round_number, p1.name, p2.name,
+			m.score1, m.score2, m.completed, m.result_type
+		FROM matches m
+		JOIN rounds r ON m.round_id = r.id
+		JOIN players p1 ON m.player1_id = p1.id
+		JOIN players p2 ON m.player2_id = p2.id
+		WHERE m.id = $1
+		FOR UPDATE OF m
+	`, matchID).Scan(
+		&version,
+		&locked,
+		&correction.RoundNumber,
+		&correction.Player1Name,
+		&correction.Player2Name,
+		&correction.PreviousScore1,
+		&correction.PreviousScore2,
+		&correction.PreviousCompleted,
+		&correction.PreviousResultType,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+	if !ifMatch(c, version) {
+		tx.Rollback()
+		current, err := fetchMatchDetail(ctx, database.DB, matchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+			return
+		}
+		respondVersionConflict(c, current.Version, current)
+		return
+	}
+	if req.Action == models.CorrectionReopen && !correction.PreviousCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Match is already pending"})
+		return
+	}
+	// A pending match could not be scored in a locked round
+	if req.Action == models.CorrectionReopen && locked {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Round %d is locked; unlock it before reopening a match", *correction.RoundNumber)})
+		return
+	}
+
+	switch req.Action {
+	case models.CorrectionScore:
+		err = setMatchResult(ctx, tx, matchID, *req.Score1, *req.Score2, models.MatchResultPlayed, true)
+	case models.CorrectionReopen:
+		err = clearMatchResult(ctx, tx, matchID, false)
+	case models.CorrectionVoid:
+		err = clearMatchResult(ctx, tx, matchID, true)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to correct match"})
+		return
+	}
+
+	if err := recordCorrection(ctx, tx, &correction, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record correction"})
+		return
+	}
+
+	match, err := fetchMatchDetail(ctx, tx, matchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+	event := webhooks.MatchData{Source: correction.MatchSource, Match: match, Correction: correction}
+	if err := webhooks.Enqueue(ctx, tx, webhooks.EventMatchCorrected, event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	// A reopened match is back on the clock
+	roundclock.Notify()
+
+	webhooks.Emit(webhooks.EventMatchCorrected, event)
+
+	c.Header("ETag", versionETag(match.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Match corrected successfully",
+		"match":      match,
+		"correction": correction,
+	})
+}
+
+// CorrectOnlineMatch corrects the result of an online tournament match. Matches can
+// only be reopened while the tournament is in progress; once it is completed or
+// archived, corrected scores and void matches refreeze its final standings.
+func CorrectOnlineMatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID, err := strconv.Atoi(c.Param("matchId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+
+	req, ok := bindCorrection(c)
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	correction := models.MatchCorrection{MatchSource: models.MatchSourceOnline, MatchID: matchID}
+	var tournamentID, version int
+	var status string
+	err = tx.QueryRowContext(ctx, `
+		SELECT otm.tournament_id, t.status, otm.version, otm.player1_name, otm.player2_name,
+			otm.score1, otm.score2, otm.completed, otm.result_type
+		FROM online_tournament_matches otm
+		JOIN tournaments t ON t.id = otm.tournament_id
+		WHERE otm.id = $1
+		FOR UPDATE OF otm
+	`, matchID).Scan(
+		&tournamentID,
+		&status,
+		&version,
+		&correction.Player1Name,
+		&correction.Player2Name,
+		&correction.PreviousScore1,
+		&correction.PreviousScore2,
+		&correction.PreviousCompleted,
+		&correction.PreviousResultType,
+	)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+	correction.TournamentID = &tournamentID
+
+	finished := status == models.TournamentStatusCompleted || status == models.TournamentStatusArchived
+	if status != models.TournamentStatusInProgress && !finished {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot correct matches of a tournament in status '%s'", status)})
+		return
+	}
+	if req.Action == models.CorrectionReopen && finished {
+		c.JSON(http.StatusConflict, gin.H{"error": "Matches of a finished tournament cannot be reopened; void them instead"})
+		return
+	}
+	if !ifMatch(c, version) {
+		tx.Rollback()
+		current, err := fetchOnlineMatch(ctx, database.DB, matchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+			return
+		}
+		respondVersionConflict(c, current.Version, current)
+		return
+	}
+	if req.Action == models.CorrectionReopen && !correction.PreviousCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Match is already pending"})
+		return
+	}
+
+	switch req.Action {
+	case models.CorrectionScore:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE online_tournament_matches
+			SET score1 = $1, score2 = $2, completed = true, result_type = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4
+		`, *req.Score1, *req.Score2, models.MatchResultPlayed, matchID)
+	case models.CorrectionReopen:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE online_tournament_matches
+			SET score1 = NULL, score2 = NULL, completed = false, result_type = $1,
+				forfeit_claimed_by = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+		`, models.MatchResultPlayed, matchID)
+	case models.CorrectionVoid:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE online_tournament_matches
+			SET score1 = NULL, score2 = NULL, completed = true, result_type = $1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+		`, models.MatchResultVoid, matchID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to correct match"})
+		return
+	}
+
+	if finished {
+		if err := freezeOnlineStandings(ctx, tx, tournamentID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute standings: " + err.Error()})
+			return
+		}
+	}
+	if err := playerstats.RefreshTournament(ctx, tx, tournamentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player statistics: " + err.Error()})
+		return
+	}
+
+	if err := recordCorrection(ctx, tx, &correction, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record correction"})
+		return
+	}
+
+	match, err := fetchOnlineMatch(ctx, tx, matchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+	event := webhooks.MatchData{Source: correction.MatchSource, Match: match, Correction: correction}
+	if err := webhooks.Enqueue(ctx, tx, webhooks.EventMatchCorrected, event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	statsChanged()
+
+	webhooks.Emit(webhooks.EventMatchCorrected, event)
+
+	c.Header("ETag", versionETag(match.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Match corrected successfully",
+		"match":      match,
+		"correction": correction,
+	})
+}
+
+// CorrectArchivedMatch corrects a match of an archived in-person tournament and
+// recomputes the tournament standings from its matches. Archived matches cannot be
+// reopened; a void one is kept as not played.
+func CorrectArchivedMatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	matchID, err := strconv.Atoi(c.Param("match_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+
+	req, ok := bindCorrection(c)
+	if !ok {
+		return
+	}
+	if req.Action == models.CorrectionReopen {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Archived matches cannot be reopened; void them instead"})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	if !lockArchivedTournament(c, tx, tournamentID) {
+		return
+	}
+
+	correction := models.MatchCorrection{
+		TournamentID: &tournamentID,
+		MatchSource:  models.MatchSourceArchived,
+		MatchID:      matchID,
+	}
+	err = tx.QueryRowContext(ctx, `
+		SELECT tr.round_number, tm.player1_name, tm.player2_name, tm.score1, tm.score2, tm.completed, tm.result_type
+		FROM tournament_matches tm
+		JOIN tournament_rounds tr ON tr.id = tm.tournament_round_id
+		WHERE tm.id = $1 AND tr.tournament_id = $2
+		FOR UPDATE OF tm
+	`, matchID, tournamentID).Scan(
+		&correction.RoundNumber,
+		&correction.Player1Name,
+		&correction.Player2Name,
+		&correction.PreviousScore1,
+		&correction.PreviousScore2,
+		&correction.PreviousCompleted,
+		&correction.PreviousResultType,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+
+	if req.Action == models.CorrectionScore {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE tournament_matches
+			SET score1 = $1, score2 = $2, completed = true, result_type = $3
+			WHERE id = $4
+		`, *req.Score1, *req.Score2, models.MatchResultPlayed, matchID)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE tournament_matches
+			SET score1 = NULL, score2 = NULL, completed = false, result_type = $1
+			WHERE id = $2
+		`, models.MatchResultVoid, matchID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to correct match"})
+		return
+	}
+
+	if err := recomputeArchivedStandings(ctx, tx, tournamentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute standings: " + err.Error()})
+		return
+	}
+	if err := playerstats.RefreshTournament(ctx, tx, tournamentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player statistics: " + err.Error()})
+		return
+	}
+
+	if err := recordCorrection(ctx, tx, &correction, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record correction"})
+		return
+	}
+
+	event := webhooks.MatchData{Source: correction.MatchSource, Correction: correction}
+	if err := webhooks.Enqueue(ctx, tx, webhooks.EventMatchCorrected, event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	statsChanged()
+
+	webhooks.Emit(webhooks.EventMatchCorrected, event)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Match corrected successfully",
+		"correction": correction,
+	})
+}
+
+// RecomputeTournamentStandings rebuilds the final standings of a finished tournament:
+// from its archived matches for an in-person tournament, or by refreezing the live
+// standings for an online one
+func RecomputeTournamentStandings(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var status, tournamentType string
+	err = tx.QueryRowContext(ctx, "SELECT status, type FROM tournaments WHERE id = $1 FOR UPDATE", tournamentID).Scan(&status, &tournamentType)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournament"})
+		return
+	}
+	if status != models.TournamentStatusCompleted && status != models.TournamentStatusArchived {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Standings of a tournament in status '%s' are not final yet", status)})
+		return
+	}
+
+	if tournamentType == "ONLINE" {
+		err = freezeOnlineStandings(ctx, tx, tournamentID)
+	} else {
+		err = recomputeArchivedStandings(ctx, tx, tournamentID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute standings: " + err.Error()})
+		return
+	}
+	if err := playerstats.RefreshTournament(ctx, tx, tournamentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player statistics: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	statsChanged()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Standings recomputed successfully",
+		"tournament_id": tournamentID,
+	})
+}
+
+// lockArchivedTournament locks an archived in-person tournament for a correction,
+// writing the error response if it cannot be corrected through the archive
+func lockArchivedTournament(c *gin.Context, tx *sql.Tx, tournamentID int) bool {
+	ctx := c.Request.Context()
+	var status, tournamentType string
+	err := tx.QueryRowContext(ctx, "SELECT status, type FROM tournaments WHERE id = $1 FOR UPDATE", tournamentID).Scan(&status, &tournamentType)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournament"})
+		return false
+	}
+	if tournamentType == "ONLINE" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Online tournament matches are corrected through /api/tournaments/online/matches/:matchId/corrections"})
+		return false
+	}
+	if status != models.TournamentStatusCompleted && status != models.TournamentStatusArchived {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot correct matches of a tournament in status '%s'", status)})
+		return false
+	}
+	return true
+}
+
+// recomputeArchivedStandings recomputes the tournament_standings of an archived
+// in-person tournament from its tournament_matches, with the same rules as the live
+// standings: 3 points a win and 1 a tie, forfeit wins score no game points, void
+// and unplayed matches count for nothing. Final positions are ranked as when the
+// standings were archived (finalPositionOrder), disqualified players last.
+func recomputeArchivedStandings(ctx context.Context, tx *sql.Tx, tournamentID int) error {
+	_, err := tx.ExecContext(ctx, `
+		WITH results AS (
+			SELECT tm.player1_id AS player_id, tm.score1 AS own, tm.score2 AS other, tm.result_type
+			FROM tournament_matches tm
+			JOIN tournament_rounds tr ON tr.id = tm.tournament_round_id
+			WHERE tr.tournament_id = $1 AND tm.completed = true AND tm.result_type <> 'void'
+			  AND tm.score1 IS NOT NULL AND tm.score2 IS NOT NULL
+			UNION ALL
+			SELECT tm.player2_id, tm.score2, tm.score1, tm.result_type
+			FROM tournament_matches tm
+			JOIN tournament_rounds tr ON tr.id = tm.tournament_round_id
+			WHERE tr.tournament_id = $1 AND tm.completed = true AND tm.result_type <> 'void'
+			  AND tm.score1 IS NOT NULL AND tm.score2 IS NOT NULL
+		),
+		totals AS (
+			SELECT
+				player_id,
+				COUNT(*) AS matches_played,
+				COUNT(*) FILTER (WHERE own > other) AS wins,
+				COUNT(*) FILTER (WHERE own = other) AS ties,
+				COUNT(*) FILTER (WHERE own < other) AS losses,
+				SUM(CASE WHEN own > other THEN 3 WHEN own = other THEN 1 ELSE 0 END) AS points,
+				SUM(CASE WHEN result_type = 'forfeit' THEN 0 ELSE own END) AS scored,
+				SUM(own + other) AS games
+			FROM results
+			GROUP BY player_id
+		)
+		UPDATE tournament_standings ts
+		SET matches_played = COALESCE(t.matches_played, 0),
+			wins = COALESCE(t.wins, 0),
+			ties = COALESCE(t.ties, 0),
+			losses = COALESCE(t.losses, 0),
+			points = COALESCE(t.points, 0),
+			total_points_scored = COALESCE(t.scored, 0),
+			total_matches = COALESCE(t.games, 0)
+		FROM tournament_standings s
+		LEFT JOIN totals t ON t.player_id = s.player_id
+		WHERE ts.id = s.id AND s.tournament_id = $1
+	`, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE tournament_standings ts
+		SET final_position = ranked.position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (
+				ORDER BY `+finalPositionOrder("status", "points", "wins", "total_points_scored", "player_name")+`
+			) AS position
+			FROM tournament_standings
+			WHERE tournament_id = $1
+		) ranked
+		WHERE ts.id = ranked.id
+	`, tournamentID)
+	return err
+}
+
+// GetMatchCorrections lists the logged corrections of the running in-person tournament,
+// or of a tournament with ?tournament_id=
+func GetMatchCorrections(c *gin.Context) {
+	ctx := c.Request.Context()
+	where := "tournament_id IS NULL"
+	args := []interface{}{}
+
+	if tournamentParam := c.Query("tournament_id"); tournamentParam != "" {
+		tournamentID, err := strconv.Atoi(tournamentParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+			return
+		}
+		args = append(args, tournamentID)
+		where = "tournament_id = $1"
+	}
+
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT id, tournament_id, match_source, match_id, round_number, player1_name, player2_name, action,
+			previous_score1, previous_score2, previous_completed, previous_result_type,
+			score1, score2, reason, created_at
+		FROM match_corrections
+		WHERE `+where+`
+		ORDER BY created_at, id
+	`, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch corrections"})
+		return
+	}
+	defer rows.Close()
+
+	corrections := []models.MatchCorrection{}
+	for rows.Next() {
+		var mc models.MatchCorrection
+		err := rows.Scan(
+			&mc.ID,
+			&mc.TournamentID,
+			&mc.MatchSource,
+			&mc.MatchID,
+			&mc.RoundNumber,
+			&mc.Player1Name,
+			&mc.Player2Name,
+			&mc.Action,
+			&mc.PreviousScore1,
+			&mc.PreviousScore2,
+			&mc.PreviousCompleted,
+			&mc.PreviousResultType,
+			&mc.Score1,
+			&mc.Score2,
+			&mc.Reason,
+			&mc.CreatedAt,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan correction"})
+			return
+		}
+		corrections = append(corrections, mc)
+	}
+
+	c.JSON(http.StatusOK, corrections)
+}
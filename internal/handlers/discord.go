@@ -0,0 +1,535 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/discord"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+// Discord bot, set once at startup by EnableDiscord
+var (
+	discordClient discord.Client
+	discordConfig discord.Config
+)
+
+// EnableDiscord posts pairings, results and standings to the configured channels and
+// verifies the interactions Discord sends with the configured public key
+func EnableDiscord(client discord.Client, cfg discord.Config) {
+	discordClient = client
+	discordConfig = cfg
+	webhooks.RegisterListener(postDiscordEvent)
+	slog.Info("discord bot enabled", "pairings_channel", cfg.PairingsChannel,
+		"results_channel", cfg.ResultsChannel, "standings_channel", cfg.StandingsChannel)
+}
+
+// postDiscordEvent turns an event into channel messages. It runs in the background so
+// the request that caused the event does not wait for Discord.
+func postDiscordEvent(event string, data interface{}) {
+	go func() {
+		ctx := context.Background()
+		switch event {
+		case webhooks.EventRoundCreated:
+			if round, ok := data.(models.FixtureRound); ok {
+				sendDiscord(discordConfig.PairingsChannel, discord.FormatPairings(round))
+			}
+
+		case webhooks.EventRoundLocked:
+			if round, ok := data.(models.FixtureRound); ok {
+				postInPersonStandings(ctx, fmt.Sprintf("Clasificación tras la ronda %d", round.Number))
+			}
+
+		case webhooks.EventMatchCompleted:
+			d, ok := data.(webhooks.MatchData)
+			if !ok {
+				return
+			}
+			switch m := d.Match.(type) {
+			case *models.MatchDetail:
+				sendDiscord(discordConfig.ResultsChannel, discord.FormatMatchResult(*m))
+			case *models.OnlineTournamentMatch:
+				postOnlineResult(ctx, *m)
+			case models.OnlineTournamentMatch:
+				postOnlineResult(ctx, m)
+			}
+
+		case webhooks.EventOnlineTournamentCompleted:
+			if t, ok := data.(webhooks.TournamentData); ok {
+				name, err := onlineTournamentName(ctx, t.TournamentID)
+				if err != nil {
+					slog.Warn("discord: failed to fetch tournament", "tournament_id", t.TournamentID, "error", err)
+					return
+				}
+				postOnlineStandings(t.TournamentID, "Tabla final de "+name)
+			}
+		}
+	}()
+}
+
+func postOnlineResult(ctx context.Context, m models.OnlineTournamentMatch) {
+	name, err := onlineTournamentName(ctx, m.TournamentID)
+	if err != nil {
+		slog.Warn("discord: failed to fetch tournament", "tournament_id", m.TournamentID, "error", err)
+		return
+	}
+	sendDiscord(discordConfig.ResultsChannel, discord.FormatOnlineResult(m, name))
+	postOnlineStandings(m.TournamentID, "Tabla de "+name)
+}
+
+func postOnlineStandings(tournamentID int, title string) {
+	if discordConfig.StandingsChannel == "" {
+		return
+	}
+	var standings []models.OnlineTournamentStanding
+	params := gin.Params{{Key: "id", Value: strconv.Itoa(tournamentID)}}
+	if _, err := callHandler(GetOnlineTournamentStandings, http.MethodGet, params, nil, &standings); err != nil {
+		slog.Warn("discord: failed to fetch standings", "tournament_id", tournamentID, "error", err)
+		return
+	}
+	sendDiscord(discordConfig.StandingsChannel, discord.FormatStandings(title, discord.OnlineStandings(standings)))
+}
+
+func postInPersonStandings(ctx context.Context, title string) {
+	if discordConfig.StandingsChannel == "" {
+		return
+	}
+	standings, err := fetchStandings(ctx)
+	if err != nil {
+		slog.Warn("discord: failed to fetch standings", "error", err)
+		return
+	}
+	sendDiscord(discordConfig.StandingsChannel, discord.FormatStandings(title, discord.InPersonStandings(standings)))
+}
+
+// sendDiscord posts a message; a channel left unconfigured skips it
+func sendDiscord(channelID, content string) {
+	if channelID == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := discordClient.SendMessage(ctx, channelID, content); err != nil {
+		slog.Warn("discord: failed to post", "channel", channelID, "error", err)
+	}
+}
+
+// inProcessEngine hosts the contexts of in-process handler calls
+var inProcessEngine = gin.New()
+
+// callHandler runs an API handler in process, so bot commands go through the same
+// checks as the HTTP API. A 2xx answer is decoded into out; any other answer is
+// returned as an error with the handler's message.
+func callHandler(handler gin.HandlerFunc, method string, params gin.Params, body interface{}, out interface{}) (int, error) {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	w := httptest.NewRecorder()
+	c := gin.CreateTestContextOnly(w, inProcessEngine)
+	req, err := http.NewRequest(method, "/", reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+	c.Params = params
+	handler(c)
+
+	if w.Code < 200 || w.Code > 299 {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &failure); err != nil || failure.Error == "" {
+			failure.Error = http.StatusText(w.Code)
+		}
+		return w.Code, errors.New(failure.Error)
+	}
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			return w.Code, err
+		}
+	}
+	return w.Code, nil
+}
+
+// DiscordInteractions answers the slash commands Discord sends to the bot. Discord
+// signs every request; unsigned ones are rejected.
+func DiscordInteractions(c *gin.Context) {
+	ctx := c.Request.Context()
+	if discordConfig.PublicKey == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Discord commands are not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read body"})
+		return
+	}
+	signature := c.GetHeader("X-Signature-Ed25519")
+	timestamp := c.GetHeader("X-Signature-Timestamp")
+	if !discord.VerifyInteraction(discordConfig.PublicKey, signature, timestamp, body) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid request signature"})
+		return
+	}
+
+	var interaction discord.Interaction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interaction"})
+		return
+	}
+
+	switch interaction.Type {
+	case discord.InteractionPing:
+		c.JSON(http.StatusOK, discord.Pong())
+	case discord.InteractionApplicationCommand:
+		user := interaction.Invoker()
+		if user == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Interaction has no user"})
+			return
+		}
+		reply, ephemeral := runDiscordCommand(ctx, user.ID, interaction.Command())
+		c.JSON(http.StatusOK, discord.Reply(reply, ephemeral))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported interaction type"})
+	}
+}
+
+// RunDiscordCommand runs a slash-style command such as "/report 2-1" as a Discord
+// user, for bots that read channel messages instead of slash commands
+func RunDiscordCommand(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req models.DiscordCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd, err := discord.ParseCommand(req.Text)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"reply": err.Error(), "ephemeral": true})
+		return
+	}
+	reply, ephemeral := runDiscordCommand(ctx, req.DiscordUserID, cmd)
+	c.JSON(http.StatusOK, gin.H{"reply": reply, "ephemeral": ephemeral})
+}
+
+// runDiscordCommand runs a bot command and returns the reply, in Spanish, and whether
+// only the user who ran it should see it
+func runDiscordCommand(ctx context.Context, discordUserID string, cmd discord.Command) (string, bool) {
+	switch cmd.Name {
+	case discord.CommandStandings:
+		return discordStandings(ctx, cmd), false
+	case discord.CommandMyMatch:
+		return discordMyMatch(ctx, discordUserID, cmd), true
+	case discord.CommandReport:
+		return discordReport(ctx, discordUserID, cmd), true
+	}
+	return "Comando desconocido. Usa /report, /standings o /mymatch.", true
+}
+
+func discordStandings(ctx context.Context, cmd discord.Command) string {
+	tournamentID, name, err := discordTournament(ctx, cmd.Tournament, 0)
+	if err != nil {
+		return err.Error()
+	}
+
+	var standings []models.OnlineTournamentStanding
+	params := gin.Params{{Key: "id", Value: strconv.Itoa(tournamentID)}}
+	if _, err := callHandler(GetOnlineTournamentStandings, http.MethodGet, params, nil, &standings); err != nil {
+		return "No se pudo obtener la tabla: " + err.Error()
+	}
+	return discord.FormatStandings("Tabla de "+name, discord.OnlineStandings(standings))
+}
+
+func discordMyMatch(ctx context.Context, discordUserID string, cmd discord.Command) string {
+	playerID, playerName, err := discordPlayer(ctx, discordUserID)
+	if err != nil {
+		return err.Error()
+	}
+	tournamentID, name, err := discordTournament(ctx, cmd.Tournament, playerID)
+	if err != nil {
+		return err.Error()
+	}
+
+	matches, err := discordPendingMatches(tournamentID, playerID)
+	if err != nil {
+		return "No se pudieron obtener tus partidas: " + err.Error()
+	}
+	return discord.FormatPendingMatches(playerName, name, playerID, matches)
+}
+
+func discordReport(ctx context.Context, discordUserID string, cmd discord.Command) string {
+	own, other, err := discord.ParseScore(cmd.Score)
+	if err != nil {
+		return err.Error()
+	}
+	playerID, _, err := discordPlayer(ctx, discordUserID)
+	if err != nil {
+		return err.Error()
+	}
+	tournamentID, name, err := discordTournament(ctx, cmd.Tournament, playerID)
+	if err != nil {
+		return err.Error()
+	}
+
+	pending, err := discordPendingMatches(tournamentID, playerID)
+	if err != nil {
+		return "No se pudieron obtener tus partidas: " + err.Error()
+	}
+	var candidates []models.OnlineTournamentMatch
+	for _, m := range pending {
+		if cmd.Opponent == "" || strings.EqualFold(discord.Opponent(m, playerID), cmd.Opponent) {
+			candidates = append(candidates, m)
+		}
+	}
+	switch {
+	case len(candidates) == 0 && cmd.Opponent != "":
+		return fmt.Sprintf("No tienes una partida pendiente contra %s en %s.", cmd.Opponent, name)
+	case len(candidates) == 0:
+		return fmt.Sprintf("No tienes partidas pendientes en %s.", name)
+	case len(candidates) > 1:
+		opponents := make([]string, len(candidates))
+		for i, m := range candidates {
+			opponents[i] = discord.Opponent(m, playerID)
+		}
+		return fmt.Sprintf("Tienes %d partidas pendientes en %s; indica tu rival, por ejemplo `/report %s %s`. Rivales: %s.",
+			len(candidates), name, cmd.Score, opponents[0], strings.Join(opponents, ", "))
+	}
+
+	// The score is given from the reporting player's side
+	match := candidates[0]
+	score := models.UpdateOnlineMatchScoreRequest{Score1: own, Score2: other}
+	if match.Player2ID == playerID {
+		score = models.UpdateOnlineMatchScoreRequest{Score1: other, Score2: own}
+	}
+	params := gin.Params{{Key: "matchId", Value: strconv.Itoa(match.ID)}}
+	if _, err := callHandler(UpdateOnlineMatchScore, http.MethodPatch, params, score, nil); err != nil {
+		return "No se pudo registrar el resultado: " + err.Error()
+	}
+
+	match.Score1, match.Score2 = &score.Score1, &score.Score2
+	return discord.FormatReported(match)
+}
+
+// discordPendingMatches returns the pending matches of a player in an online tournament
+func discordPendingMatches(tournamentID, playerID int) ([]models.OnlineTournamentMatch, error) {
+	var matches []models.OnlineTournamentMatch
+	params := gin.Params{{Key: "id", Value: strconv.Itoa(tournamentID)}}
+	if _, err := callHandler(GetOnlinePendingMatches, http.MethodGet, params, nil, &matches); err != nil {
+		return nil, err
+	}
+
+	own := matches[:0]
+	for _, m := range matches {
+		if m.Player1ID == playerID || m.Player2ID == playerID {
+			own = append(own, m)
+		}
+	}
+	return own, nil
+}
+
+// discordPlayer returns the premier player linked to a Discord user
+func discordPlayer(ctx context.Context, discordUserID string) (int, string, error) {
+	var id int
+	var name string
+	err := database.DB.QueryRowContext(ctx, `
+		SELECT pp.id, pp.name
+		FROM discord_links l
+		JOIN premier_players pp ON pp.id = l.player_id
+		WHERE l.discord_user_id = $1
+	`, discordUserID).Scan(&id, &name)
+	if err == sql.ErrNoRows {
+		return 0, "", errors.New("Tu cuenta de Discord no está vinculada a ningún jugador. Pide a un organizador que la vincule.")
+	}
+	if err != nil {
+		return 0, "", errors.New("No se pudo obtener tu jugador, inténtalo de nuevo.")
+	}
+	return id, name, nil
+}
+
+// discordTournament resolves the online tournament of a command: the given one, or
+// the latest one in progress (that the player is in, if playerID is not 0)
+func discordTournament(ctx context.Context, tournamentID, playerID int) (int, string, error) {
+	var name string
+	var err error
+	switch {
+	case tournamentID != 0:
+		name, err = onlineTournamentName(ctx, tournamentID)
+		if err == sql.ErrNoRows {
+			return 0, "", fmt.Errorf("No existe el torneo online %d.", tournamentID)
+		}
+	case playerID != 0:
+		err = database.DB.QueryRowContext(ctx, `
+			SELECT t.id, t.name
+			FROM tournaments t
+			JOIN online_tournament_players otp ON otp.tournament_id = t.id
+			WHERE t.type = 'ONLINE' AND t.status = $1 AND otp.player_id = $2
+			ORDER BY t.id DESC
+			LIMIT 1
+		`, models.TournamentStatusInProgress, playerID).Scan(&tournamentID, &name)
+		if err == sql.ErrNoRows {
+			return 0, "", errors.New("No estás jugando ningún torneo online en curso.")
+		}
+	default:
+		err = database.DB.QueryRowContext(ctx, `
+			SELECT id, name FROM tournaments
+			WHERE type = 'ONLINE' AND status = $1
+			ORDER BY id DESC
+			LIMIT 1
+		`, models.TournamentStatusInProgress).Scan(&tournamentID, &name)
+		if err == sql.ErrNoRows {
+			return 0, "", errors.New("No hay ningún torneo online en curso.")
+		}
+	}
+	if err != nil {
+		return 0, "", errors.New("No se pudo obtener el torneo, inténtalo de nuevo.")
+	}
+	return tournamentID, name, nil
+}
+
+func onlineTournamentName(ctx context.Context, tournamentID int) (string, error) {
+	var name string
+	err := database.DB.QueryRowContext(ctx,
+		"SELECT name FROM tournaments WHERE id = $1 AND type = 'ONLINE'",
+		tournamentID,
+	).Scan(&name)
+	return name, err
+}
+
+// GetDiscordLinks lists the Discord accounts linked to players
+func GetDiscordLinks(c *gin.Context) {
+	ctx := c.Request.Context()
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT l.discord_user_id, l.player_id, pp.name, l.discord_username, l.created_at, l.updated_at
+		FROM discord_links l
+		JOIN premier_players pp ON pp.id = l.player_id
+		ORDER BY pp.name
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Discord links"})
+		return
+	}
+	defer rows.Close()
+
+	links := []models.DiscordLink{}
+	for rows.Next() {
+		var l models.DiscordLink
+		if err := rows.Scan(&l.DiscordUserID, &l.PlayerID, &l.PlayerName, &l.DiscordUsername, &l.CreatedAt, &l.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan Discord link"})
+			return
+		}
+		links = append(links, l)
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// LinkDiscordUser links a Discord user id to a premier player, replacing the player
+// the Discord user was linked to
+func LinkDiscordUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	discordUserID := c.Param("discord_user_id")
+	if _, err := strconv.ParseUint(discordUserID, 10, 64); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Discord user ID"})
+		return
+	}
+
+	var req models.LinkDiscordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	link := models.DiscordLink{DiscordUserID: discordUserID, PlayerID: req.PlayerID}
+	err = tx.QueryRowContext(ctx, "SELECT name FROM premier_players WHERE id = $1", req.PlayerID).Scan(&link.PlayerName)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player"})
+		return
+	}
+
+	var linkedTo string
+	err = tx.QueryRowContext(ctx,
+		"SELECT discord_user_id FROM discord_links WHERE player_id = $1 FOR UPDATE",
+		req.PlayerID,
+	).Scan(&linkedTo)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Discord links"})
+		return
+	}
+	if err == nil && linkedTo != discordUserID {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":           fmt.Sprintf("%s is already linked to another Discord account; unlink it first", link.PlayerName),
+			"discord_user_id": linkedTo,
+		})
+		return
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO discord_links (discord_user_id, player_id, discord_username)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (discord_user_id) DO UPDATE
+		SET player_id = EXCLUDED.player_id, discord_username = EXCLUDED.discord_username
+		RETURNING discord_username, created_at, updated_at
+	`, discordUserID, req.PlayerID, req.DiscordUsername).Scan(&link.DiscordUsername, &link.CreatedAt, &link.UpdatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Discord user"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// UnlinkDiscordUser removes the link of a Discord user
+func UnlinkDiscordUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	discordUserID := c.Param("discord_user_id")
+
+	result, err := database.DB.ExecContext(ctx, "DELETE FROM discord_links WHERE discord_user_id = $1", discordUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink Discord user"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Discord user is not linked"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Discord user unlinked successfully", "discord_user_id": discordUserID})
+}
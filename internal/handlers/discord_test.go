@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/discord"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	fakeDiscord    = discord.NewFake(false)
+	discordPrivate ed25519.PrivateKey
+	enableOnce     sync.Once
+)
+
+// newDiscordRouter posts to fakeDiscord. The commands tested fail before any
+// database query, so they run without a database.
+func newDiscordRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	enableOnce.Do(func() {
+		public, private, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		discordPrivate = private
+		EnableDiscord(fakeDiscord, discord.Config{
+			PublicKey:       public,
+			PairingsChannel: "pairings",
+			ResultsChannel:  "results",
+			Fake:            true,
+		})
+	})
+	fakeDiscord.Reset()
+
+	router := gin.New()
+	router.POST("/api/discord/interactions", DiscordInteractions)
+	router.POST("/api/discord/commands", RunDiscordCommand)
+	return router
+}
+
+func postInteraction(router *gin.Engine, interaction discord.Interaction, sign bool) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(interaction)
+	req := httptest.NewRequest(http.MethodPost, "/api/discord/interactions", bytes.NewReader(body))
+	timestamp := "1700000000"
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+	if sign {
+		req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(discordPrivate, append([]byte(timestamp), body...))))
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestDiscordInteractionsRejectsUnsigned(t *testing.T) {
+	router := newDiscordRouter(t)
+	if w := postInteraction(router, discord.Interaction{Type: discord.InteractionPing}, false); w.Code != http.StatusUnauthorized {
+		t.Errorf("status %d, want 401", w.Code)
+	}
+}
+
+func TestDiscordInteractionsAnswersPing(t *testing.T) {
+	router := newDiscordRouter(t)
+	w := postInteraction(router, discord.Interaction{Type: discord.InteractionPing}, true)
+	var got discord.InteractionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK || got.Type != discord.ResponsePong {
+		t.Errorf("status %d, body %s, want a pong", w.Code, w.Body)
+	}
+}
+
+func TestDiscordInteractionsRepliesToCommands(t *testing.T) {
+	router := newDiscordRouter(t)
+	user := &discord.Member{User: &discord.User{ID: "42"}}
+	tests := []struct {
+		name string
+		data *discord.CommandData
+		want string
+	}{
+		{
+			"unknown command",
+			&discord.CommandData{Name: "ranking"},
+			"Comando desconocido. Usa /report, /standings o /mymatch.",
+		},
+		{
+			"malformed score",
+			&discord.CommandData{Name: discord.CommandReport, Options: []discord.CommandOption{{Name: "score", Value: "dos a uno"}}},
+			"el resultado debe ser como 2-1, con tu puntaje primero",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postInteraction(router, discord.Interaction{Type: discord.InteractionApplicationCommand, Data: tt.data, Member: user}, true)
+			var got discord.InteractionResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Data == nil {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+			if got.Data.Content != tt.want {
+				t.Errorf("reply %q, want %q", got.Data.Content, tt.want)
+			}
+			if got.Data.Flags != discord.FlagEphemeral {
+				t.Errorf("flags %d, want an ephemeral reply", got.Data.Flags)
+			}
+		})
+	}
+
+	if w := postInteraction(router, discord.Interaction{Type: discord.InteractionApplicationCommand, Data: &discord.CommandData{Name: "ranking"}}, true); w.Code != http.StatusBadRequest {
+		t.Errorf("command without a user: status %d, want 400", w.Code)
+	}
+}
+
+func TestRunDiscordCommandParsesText(t *testing.T) {
+	router := newDiscordRouter(t)
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"report 2-1", "los comandos empiezan con /"},
+		{"/report", "uso: /report 2-1 [rival]"},
+		{"/standings abc", "uso: /standings [id del torneo]"},
+		{"/ranking", "comando desconocido /ranking"},
+		{"/report 2:1", "el resultado debe ser como 2-1, con tu puntaje primero"},
+	}
+	for _, tt := range tests {
+		body, _ := json.Marshal(models.DiscordCommandRequest{DiscordUserID: "42", Text: tt.text})
+		req := httptest.NewRequest(http.MethodPost, "/api/discord/commands", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var got struct {
+			Reply     string `json:"reply"`
+			Ephemeral bool   `json:"ephemeral"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("%q: status %d, body %s", tt.text, w.Code, w.Body)
+		}
+		if got.Reply != tt.want || !got.Ephemeral {
+			t.Errorf("%q: reply %q (ephemeral %v), want %q only to the user", tt.text, got.Reply, got.Ephemeral, tt.want)
+		}
+	}
+	if n := len(fakeDiscord.Messages()); n != 0 {
+		t.Errorf("commands posted %d channel messages, want none", n)
+	}
+}
+
+func TestDiscordPostsEvents(t *testing.T) {
+	newDiscordRouter(t)
+	table := 3
+	score1, score2 := 2, 1
+	round := models.FixtureRound{Number: 2, Format: "PB", Matches: []models.MatchDetail{
+		{TableNumber: &table, Player1Name: "Ana", Player2Name: "Bruno"},
+		{Player1Name: "Carla", Player2Name: "BYE"},
+	}}
+	match := &models.MatchDetail{RoundNumber: 2, Player1Name: "Ana", Player2Name: "Bruno", Score1: &score1, Score2: &score2, ResultType: models.MatchResultPlayed}
+
+	webhooks.Emit(webhooks.EventRoundCreated, round)
+	webhooks.Emit(webhooks.EventMatchCompleted, webhooks.MatchData{Source: models.MatchSourceInPerson, Match: match})
+
+	want := map[string]string{
+		"pairings": discord.FormatPairings(round),
+		"results":  discord.FormatMatchResult(*match),
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(fakeDiscord.Messages()) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	messages := fakeDiscord.Messages()
+	if len(messages) != len(want) {
+		t.Fatalf("posted %d messages, want %d: %+v", len(messages), len(want), messages)
+	}
+	for _, m := range messages {
+		if m.Content != want[m.ChannelID] {
+			t.Errorf("channel %s: posted %q, want %q", m.ChannelID, m.Content, want[m.ChannelID])
+		}
+	}
+}
@@ -1,40 +1,83 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"strconv"
 
+	"github.com/andreuvv/premier_mitologico/backend/internal/cache"
 	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/metrics"
 	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/playerstats"
+	"github.com/andreuvv/premier_mitologico/backend/internal/roundclock"
+	"github.com/andreuvv/premier_mitologico/backend/internal/tournamentio"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
 	"github.com/gin-gonic/gin"
 )
 
-// GetFixture returns all rounds with their matches
+// GetFixture returns all rounds with their matches and the progress of each round
 func GetFixture(c *gin.Context) {
-	query := `
-		SELECT 
-			r.round_number,
-			r.format,
-			m.id as match_id,
-			p1.name as player1_name,
-			p2.name as player2_name,
-			m.score1,
-			m.score2,
-			m.completed,
-			m.updated_at
-		FROM rounds r
-		LEFT JOIN matches m ON m.round_id = r.id
-		LEFT JOIN players p1 ON m.player1_id = p1.id
-		LEFT JOIN players p2 ON m.player2_id = p2.id
-		ORDER BY r.round_number, m.id
-	`
-
-	rows, err := database.DB.Query(query)
+	ctx := c.Request.Context()
+	rounds, err := fetchFixture(ctx, database.DB)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fixture"})
 		return
 	}
+
+	c.JSON(http.StatusOK, fixtureProgress(rounds))
+}
+
+// fixtureProgress sums up the progress of the rounds
+func fixtureProgress(rounds []models.FixtureRound) models.FixtureResponse {
+	response := models.FixtureResponse{Rounds: rounds}
+	for _, r := range rounds {
+		response.TotalMatches += r.TotalMatches
+		response.CompletedMatches += r.CompletedMatches
+		if r.Status == models.RoundStatusComplete || r.Status == models.RoundStatusLocked {
+			response.CompletedRounds++
+		} else if response.CurrentRound == nil {
+			number := r.Number
+			response.CurrentRound = &number
+		}
+	}
+	return response
+}
+
+// fixtureQuery lists the rounds of the current tournament with their matches, one
+// row per match and a row of NULL match columns for a round without matches
+const fixtureQuery = `
+	SELECT 
+		r.round_number,
+		r.format,
+		r.status,
+		r.total_matches,
+		r.completed_matches,
+		r.locked_at,
+		m.id as match_id,
+		m.table_number,
+		p1.name as player1_name,
+		p2.name as player2_name,
+		m.score1,
+		m.score2,
+		m.completed,
+		COALESCE(m.result_type, 'played'),
+		m.version,
+		m.updated_at
+	FROM round_progress r
+	LEFT JOIN matches m ON m.round_id = r.id
+	LEFT JOIN players p1 ON m.player1_id = p1.id
+	LEFT JOIN players p2 ON m.player2_id = p2.id
+	ORDER BY r.round_number, m.table_number NULLS LAST, m.id
+`
+
+// fetchFixture loads all rounds of the current tournament with their matches
+func fetchFixture(ctx context.Context, db database.Querier) ([]models.FixtureRound, error) {
+	rows, err := db.QueryContext(ctx, fixtureQuery)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	roundsMap := make(map[int]*models.FixtureRound)
@@ -42,17 +85,25 @@ func GetFixture(c *gin.Context) {
 	for rows.Next() {
 		var roundNum int
 		var format string
+		var progress models.FixtureRound
 		var match models.MatchDetail
 
 		err := rows.Scan(
 			&roundNum,
 			&format,
+			&progress.Status,
+			&progress.TotalMatches,
+			&progress.CompletedMatches,
+			&progress.LockedAt,
 			&match.ID,
+			&match.TableNumber,
 			&match.Player1Name,
 			&match.Player2Name,
 			&match.Score1,
 			&match.Score2,
 			&match.Completed,
+			&match.ResultType,
+			&match.Version,
 			&match.UpdatedAt,
 		)
 		if err != nil {
@@ -61,9 +112,13 @@ func GetFixture(c *gin.Context) {
 
 		if _, exists := roundsMap[roundNum]; !exists {
 			roundsMap[roundNum] = &models.FixtureRound{
-				Number:  roundNum,
-				Format:  format,
-				Matches: []models.MatchDetail{},
+				Number:           roundNum,
+				Format:           format,
+				Status:           progress.Status,
+				TotalMatches:     progress.TotalMatches,
+				CompletedMatches: progress.CompletedMatches,
+				LockedAt:         progress.LockedAt,
+				Matches:          []models.MatchDetail{},
 			}
 		}
 
@@ -79,16 +134,59 @@ func GetFixture(c *gin.Context) {
 			rounds = append(rounds, *round)
 		}
 	}
+	return rounds, nil
+}
 
-	c.JSON(http.StatusOK, models.FixtureResponse{Rounds: rounds})
+// fetchMatchDetail loads a match of the current tournament as shown in the fixture
+func fetchMatchDetail(ctx context.Context, db database.Querier, matchID int) (*models.MatchDetail, error) {
+	var m models.MatchDetail
+	err := db.QueryRowContext(ctx, `
+		SELECT m.id, r.round_number, r.format, m.table_number, p1.name, p2.name,
+			m.score1, m.score2, m.completed, COALESCE(m.result_type, 'played'), m.version, m.updated_at
+		FROM matches m
+		JOIN rounds r ON m.round_id = r.id
+		JOIN players p1 ON m.player1_id = p1.id
+		JOIN players p2 ON m.player2_id = p2.id
+		WHERE m.id = $1
+	`, matchID).Scan(
+		&m.ID,
+		&m.RoundNumber,
+		&m.Format,
+		&m.TableNumber,
+		&m.Player1Name,
+		&m.Player2Name,
+		&m.Score1,
+		&m.Score2,
+		&m.Completed,
+		&m.ResultType,
+		&m.Version,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
 }
 
 // GetStandings returns current tournament standings
 func GetStandings(c *gin.Context) {
+	ctx := c.Request.Context()
+	standings, err := fetchStandings(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch standings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, standings)
+}
+
+// fetchStandings loads the current tournament standings, disqualified players last
+func fetchStandings(ctx context.Context) ([]models.Standing, error) {
 	query := `
 		SELECT 
 			id,
 			name,
+			status,
 			matches_played,
 			wins,
 			ties,
@@ -97,13 +195,12 @@ func GetStandings(c *gin.Context) {
 			total_points_scored,
 			total_matches
 		FROM standings
-		ORDER BY points DESC, total_points_scored DESC
+		ORDER BY CASE WHEN status = 'disqualified' THEN 1 ELSE 0 END, points DESC, total_points_scored DESC
 	`
 
-	rows, err := database.DB.Query(query)
+	rows, err := database.DB.QueryContext(ctx, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch standings"})
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -113,6 +210,7 @@ func GetStandings(c *gin.Context) {
 		err := rows.Scan(
 			&s.ID,
 			&s.Name,
+			&s.Status,
 			&s.MatchesPlayed,
 			&s.Wins,
 			&s.Ties,
@@ -126,13 +224,23 @@ func GetStandings(c *gin.Context) {
 		}
 		standings = append(standings, s)
 	}
-
-	c.JSON(http.StatusOK, standings)
+	return standings, nil
 }
 
-// UpdateMatchScore updates the score for a specific match
+// scoreUpdates counts the scores reported, by match source; rate() of it gives the
+// score updates per minute
+var scoreUpdates = metrics.NewCounterVec("score_updates_total", "Match scores reported, by source", "source")
+
+// UpdateMatchScore updates the score of a match. With an If-Match header the update
+// only applies if the match is still at that version; otherwise 409 returns the
+// current result.
 func UpdateMatchScore(c *gin.Context) {
-	matchID := c.Param("id")
+	ctx := c.Request.Context()
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
 
 	var req models.UpdateScoreRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -141,17 +249,24 @@ func UpdateMatchScore(c *gin.Context) {
 	}
 
 	// Start transaction
-	tx, err := database.DB.Begin()
+	tx, err := database.DB.BeginTx(ctx, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
 		return
 	}
 	defer tx.Rollback()
 
-	// Get player IDs for this match
-	var player1ID, player2ID int
-	queryPlayers := `SELECT player1_id, player2_id FROM matches WHERE id = $1`
-	err = tx.QueryRow(queryPlayers, matchID).Scan(&player1ID, &player2ID)
+	// Lock the match so concurrent updates are checked one after the other, and its
+	// round so it cannot be locked meanwhile
+	var version, roundNumber int
+	var locked bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT m.version, r.round_number, r.locked_at IS NOT NULL
+		FROM matches m
+		JOIN rounds r ON m.round_id = r.id
+		WHERE m.id = $1
+		FOR UPDATE OF m FOR SHARE OF r
+	`, matchID).Scan(&version, &roundNumber, &locked)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
@@ -160,59 +275,139 @@ func UpdateMatchScore(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
 		return
 	}
+	if locked {
+		respondRoundLocked(c, roundNumber)
+		return
+	}
+	if !ifMatch(c, version) {
+		tx.Rollback()
+		current, err := fetchMatchDetail(ctx, database.DB, matchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+			return
+		}
+		respondVersionConflict(c, current.Version, current)
+		return
+	}
+
+	if err := setMatchResult(ctx, tx, matchID, req.Score1, req.Score2, models.MatchResultPlayed, true); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update score"})
+		return
+	}
+
+	match, err := fetchMatchDetail(ctx, tx, matchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+	event := webhooks.MatchData{Source: models.MatchSourceInPerson, Match: match}
+	if match.Completed {
+		if err := webhooks.Enqueue(ctx, tx, webhooks.EventMatchCompleted, event); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
+			return
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	scoreUpdates.Inc(models.MatchSourceInPerson)
+
+	// The match clock is now completed
+	roundclock.Notify()
+
+	if match.Completed {
+		webhooks.Emit(webhooks.EventMatchCompleted, event)
+	}
+
+	c.Header("ETag", versionETag(match.Version))
+	c.JSON(http.StatusOK, gin.H{"message": "Score updated successfully", "match_id": matchID, "match": match})
+}
+
+// setMatchResult writes the result of an in-person match. Every result goes through
+// here, whether entered by the players or imposed by a judge penalty. A completed
+// result updates player_match_stats and, if it ends the round, seats the next one;
+// an incomplete one only records the games so far. It returns sql.ErrNoRows if the
+// match does not exist.
+func setMatchResult(ctx context.Context, tx *sql.Tx, matchID, score1, score2 int, resultType string, completed bool) error {
+	// Get player IDs for this match
+	var player1ID, player2ID int
+	queryPlayers := `SELECT player1_id, player2_id FROM matches WHERE id = $1`
+	if err := tx.QueryRowContext(ctx, queryPlayers, matchID).Scan(&player1ID, &player2ID); err != nil {
+		return err
+	}
 
 	// Update match score
 	query := `
 		UPDATE matches 
-		SET score1 = $1, score2 = $2, completed = true, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
-		RETURNING id
+		SET score1 = $1, score2 = $2, completed = $3, result_type = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
 	`
-
-	var id int
-	err = tx.QueryRow(query, req.Score1, req.Score2, matchID).Scan(&id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update score"})
-		return
+	if _, err := tx.ExecContext(ctx, query, score1, score2, completed, resultType, matchID); err != nil {
+		return err
+	}
+	if !completed {
+		return nil
 	}
 
 	// Update player_match_stats for both players
-	totalGames := req.Score1 + req.Score2
+	totalGames := score1 + score2
 
-	// Player 1 stats
 	upsertStats := `
 		INSERT INTO player_match_stats (player_id, match_id, games_played, games_won, updated_at)
 		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
 		ON CONFLICT (player_id, match_id) 
 		DO UPDATE SET games_played = $3, games_won = $4, updated_at = CURRENT_TIMESTAMP
 	`
-	_, err = tx.Exec(upsertStats, player1ID, matchID, totalGames, req.Score1)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player1 stats"})
-		return
+	if _, err := tx.ExecContext(ctx, upsertStats, player1ID, matchID, totalGames, score1); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, upsertStats, player2ID, matchID, totalGames, score2); err != nil {
+		return err
 	}
 
-	// Player 2 stats
-	_, err = tx.Exec(upsertStats, player2ID, matchID, totalGames, req.Score2)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player2 stats"})
-		return
+	// Once the round is over, seat the next one with the updated standings
+	return seatNextRound(ctx, tx, matchID)
+}
+
+// clearMatchResult removes the result of an in-person match. A reopened match is
+// pending again; a void one stays decided, so its round can still end, but gives no
+// result to either player. It returns sql.ErrNoRows if the match does not exist.
+func clearMatchResult(ctx context.Context, tx *sql.Tx, matchID int, void bool) error {
+	resultType := models.MatchResultPlayed
+	if void {
+		resultType = models.MatchResultVoid
 	}
 
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-		return
+	result, err := tx.ExecContext(ctx, `
+		UPDATE matches
+		SET score1 = NULL, score2 = NULL, completed = $1, result_type = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, void, resultType, matchID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Score updated successfully", "match_id": id})
+	if _, err := tx.ExecContext(ctx, "DELETE FROM player_match_stats WHERE match_id = $1", matchID); err != nil {
+		return err
+	}
+	if !void {
+		return nil
+	}
+	return seatNextRound(ctx, tx, matchID)
 }
 
 // GetPlayers returns all players
 func GetPlayers(c *gin.Context) {
-	query := `SELECT id, name, confirmed, created_at, updated_at FROM players ORDER BY name`
+	ctx := c.Request.Context()
+	query := `SELECT id, name, confirmed, status, late_entry, fixed_table, created_at, updated_at FROM players ORDER BY name`
 
-	rows, err := database.DB.Query(query)
+	rows, err := database.DB.QueryContext(ctx, query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch players"})
 		return
@@ -222,7 +417,7 @@ func GetPlayers(c *gin.Context) {
 	players := []models.Player{}
 	for rows.Next() {
 		var p models.Player
-		err := rows.Scan(&p.ID, &p.Name, &p.Confirmed, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.ID, &p.Name, &p.Confirmed, &p.Status, &p.LateEntry, &p.FixedTable, &p.CreatedAt, &p.UpdatedAt)
 		if err != nil {
 			continue
 		}
@@ -234,6 +429,7 @@ func GetPlayers(c *gin.Context) {
 
 // CreatePlayer creates a new player
 func CreatePlayer(c *gin.Context) {
+	ctx := c.Request.Context()
 	var req models.CreatePlayerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -241,16 +437,19 @@ func CreatePlayer(c *gin.Context) {
 	}
 
 	query := `
-		INSERT INTO players (name, confirmed) 
-		VALUES ($1, $2) 
-		RETURNING id, name, confirmed, created_at, updated_at
+		INSERT INTO players (name, confirmed, fixed_table) 
+		VALUES ($1, $2, $3) 
+		RETURNING id, name, confirmed, status, late_entry, fixed_table, created_at, updated_at
 	`
 
 	var player models.Player
-	err := database.DB.QueryRow(query, req.Name, req.Confirmed).Scan(
+	err := database.DB.QueryRowContext(ctx, query, req.Name, req.Confirmed, req.FixedTable).Scan(
 		&player.ID,
 		&player.Name,
 		&player.Confirmed,
+		&player.Status,
+		&player.LateEntry,
+		&player.FixedTable,
 		&player.CreatedAt,
 		&player.UpdatedAt,
 	)
@@ -264,13 +463,14 @@ func CreatePlayer(c *gin.Context) {
 
 // CreateFixture creates the complete fixture (players, rounds, and matches)
 func CreateFixture(c *gin.Context) {
+	ctx := c.Request.Context()
 	var req models.CreateFixtureRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	tx, err := database.DB.Begin()
+	tx, err := database.DB.BeginTx(ctx, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
 		return
@@ -278,15 +478,15 @@ func CreateFixture(c *gin.Context) {
 	defer tx.Rollback()
 
 	// Clear existing data
-	if _, err := tx.Exec("DELETE FROM matches"); err != nil {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM matches"); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear matches"})
 		return
 	}
-	if _, err := tx.Exec("DELETE FROM rounds"); err != nil {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM rounds"); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear rounds"})
 		return
 	}
-	if _, err := tx.Exec("DELETE FROM players"); err != nil {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM players"); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear players"})
 		return
 	}
@@ -295,9 +495,9 @@ func CreateFixture(c *gin.Context) {
 	playerMap := make(map[string]int)
 	for _, p := range req.Players {
 		var playerID int
-		err := tx.QueryRow(
-			"INSERT INTO players (name, confirmed) VALUES ($1, $2) RETURNING id",
-			p.Name, p.Confirmed,
+		err := tx.QueryRowContext(ctx,
+			"INSERT INTO players (name, confirmed, fixed_table) VALUES ($1, $2, $3) RETURNING id",
+			p.Name, p.Confirmed, p.FixedTable,
 		).Scan(&playerID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create player: " + p.Name})
@@ -323,7 +523,7 @@ func CreateFixture(c *gin.Context) {
 	// Create virtual BYE player if needed
 	if needsByePlayer {
 		var byeID int
-		err := tx.QueryRow(
+		err := tx.QueryRowContext(ctx,
 			"INSERT INTO players (name, confirmed) VALUES ($1, $2) RETURNING id",
 			"BYE", false,
 		).Scan(&byeID)
@@ -337,7 +537,7 @@ func CreateFixture(c *gin.Context) {
 	// Create rounds and matches
 	for _, r := range req.Rounds {
 		var roundID int
-		err := tx.QueryRow(
+		err := tx.QueryRowContext(ctx,
 			"INSERT INTO rounds (round_number, format) VALUES ($1, $2) RETURNING id",
 			r.RoundNumber, r.Format,
 		).Scan(&roundID)
@@ -356,7 +556,7 @@ func CreateFixture(c *gin.Context) {
 				return
 			}
 
-			_, err := tx.Exec(
+			_, err := tx.ExecContext(ctx,
 				"INSERT INTO matches (round_id, player1_id, player2_id) VALUES ($1, $2, $3)",
 				roundID, player1ID, player2ID,
 			)
@@ -365,6 +565,23 @@ func CreateFixture(c *gin.Context) {
 				return
 			}
 		}
+
+		if err := assignRoundTables(ctx, tx, roundID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign tables"})
+			return
+		}
+	}
+
+	rounds, err := fetchFixture(ctx, tx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fixture"})
+		return
+	}
+	for _, r := range rounds {
+		if err := webhooks.Enqueue(ctx, tx, webhooks.EventRoundCreated, r); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
+			return
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -372,6 +589,10 @@ func CreateFixture(c *gin.Context) {
 		return
 	}
 
+	for _, r := range rounds {
+		webhooks.Emit(webhooks.EventRoundCreated, r)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message":         "Fixture created successfully",
 		"players_created": len(req.Players),
@@ -381,10 +602,11 @@ func CreateFixture(c *gin.Context) {
 
 // ClearTournament deletes all matches and rounds, optionally players too
 func ClearTournament(c *gin.Context) {
+	ctx := c.Request.Context()
 	// Check if we should also clear players
 	clearPlayers := c.Query("clear_players") == "true"
 
-	tx, err := database.DB.Begin()
+	tx, err := database.DB.BeginTx(ctx, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
 		return
@@ -392,26 +614,26 @@ func ClearTournament(c *gin.Context) {
 	defer tx.Rollback()
 
 	// Delete player_match_stats first (foreign key constraint)
-	if _, err := tx.Exec("DELETE FROM player_match_stats"); err != nil {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM player_match_stats"); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete player stats"})
 		return
 	}
 
 	// Delete matches (foreign key constraint)
-	if _, err := tx.Exec("DELETE FROM matches"); err != nil {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM matches"); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete matches"})
 		return
 	}
 
 	// Delete rounds
-	if _, err := tx.Exec("DELETE FROM rounds"); err != nil {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM rounds"); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rounds"})
 		return
 	}
 
 	// Optionally delete players
 	if clearPlayers {
-		if _, err := tx.Exec("DELETE FROM players"); err != nil {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM players"); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete players"})
 			return
 		}
@@ -432,20 +654,24 @@ func ClearTournament(c *gin.Context) {
 
 // TogglePlayerConfirmed toggles the confirmed status of a player
 func TogglePlayerConfirmed(c *gin.Context) {
+	ctx := c.Request.Context()
 	playerID := c.Param("id")
 
 	query := `
 		UPDATE players 
 		SET confirmed = NOT confirmed, updated_at = CURRENT_TIMESTAMP 
 		WHERE id = $1 
-		RETURNING id, name, confirmed, created_at, updated_at
+		RETURNING id, name, confirmed, status, late_entry, fixed_table, created_at, updated_at
 	`
 
 	var player models.Player
-	err := database.DB.QueryRow(query, playerID).Scan(
+	err := database.DB.QueryRowContext(ctx, query, playerID).Scan(
 		&player.ID,
 		&player.Name,
 		&player.Confirmed,
+		&player.Status,
+		&player.LateEntry,
+		&player.FixedTable,
 		&player.CreatedAt,
 		&player.UpdatedAt,
 	)
@@ -463,9 +689,10 @@ func TogglePlayerConfirmed(c *gin.Context) {
 
 // GetConfirmedPlayers returns only confirmed players
 func GetConfirmedPlayers(c *gin.Context) {
-	query := `SELECT id, name, confirmed, created_at, updated_at FROM players WHERE confirmed = true ORDER BY name`
+	ctx := c.Request.Context()
+	query := `SELECT id, name, confirmed, status, late_entry, fixed_table, created_at, updated_at FROM players WHERE confirmed = true ORDER BY name`
 
-	rows, err := database.DB.Query(query)
+	rows, err := database.DB.QueryContext(ctx, query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch confirmed players"})
 		return
@@ -475,7 +702,7 @@ func GetConfirmedPlayers(c *gin.Context) {
 	players := []models.Player{}
 	for rows.Next() {
 		var p models.Player
-		err := rows.Scan(&p.ID, &p.Name, &p.Confirmed, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.ID, &p.Name, &p.Confirmed, &p.Status, &p.LateEntry, &p.FixedTable, &p.CreatedAt, &p.UpdatedAt)
 		if err != nil {
 			continue
 		}
@@ -487,13 +714,18 @@ func GetConfirmedPlayers(c *gin.Context) {
 
 // ArchiveTournament archives the current tournament data
 func ArchiveTournament(c *gin.Context) {
+	ctx := c.Request.Context()
 	var req models.ArchiveTournamentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !tournamentio.ValidMonth(req.Month) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown month '" + req.Month + "'; use a month name or number"})
+		return
+	}
 
-	tx, err := database.DB.Begin()
+	tx, err := database.DB.BeginTx(ctx, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
 		return
@@ -502,9 +734,9 @@ func ArchiveTournament(c *gin.Context) {
 
 	// Create tournament record
 	var tournamentID int
-	err = tx.QueryRow(`
-		INSERT INTO tournaments (name, month, year, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tournaments (name, month, year, start_date, end_date, status, completed_at, archived_at)
+		VALUES ($1, $2, $3, $4, $5, 'archived', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 		RETURNING id
 	`, req.Name, req.Month, req.Year, req.StartDate, req.EndDate).Scan(&tournamentID)
 	if err != nil {
@@ -512,19 +744,20 @@ func ArchiveTournament(c *gin.Context) {
 		return
 	}
 
-	// Archive current standings with position
+	// Archive current standings with position, disqualified players last
 	standingsQuery := `
 		INSERT INTO tournament_standings (
 			tournament_id, player_id, player_name, matches_played, wins, ties, losses,
-			points, total_points_scored, total_matches, final_position
+			points, total_points_scored, total_matches, final_position, status
 		)
 		SELECT 
 			$1, id, name, matches_played, wins, ties, losses,
 			points, total_points_scored, total_matches,
-			ROW_NUMBER() OVER (ORDER BY points DESC, total_points_scored DESC) as position
+			ROW_NUMBER() OVER (ORDER BY ` + finalPositionOrder("status", "points", "wins", "total_points_scored", "name") + `) as position,
+			status
 		FROM standings
 	`
-	_, err = tx.Exec(standingsQuery, tournamentID)
+	_, err = tx.ExecContext(ctx, standingsQuery, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive standings: " + err.Error()})
 		return
@@ -539,7 +772,7 @@ func ArchiveTournament(c *gin.Context) {
 	}
 	var rounds []roundData
 
-	roundsRows, err := tx.Query(`SELECT id, round_number, format FROM rounds ORDER BY round_number`)
+	roundsRows, err := tx.QueryContext(ctx, `SELECT id, round_number, format FROM rounds ORDER BY round_number`)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rounds: " + err.Error()})
 		return
@@ -560,7 +793,7 @@ func ArchiveTournament(c *gin.Context) {
 	for _, round := range rounds {
 		// Create tournament round
 		var tournamentRoundID int
-		err = tx.QueryRow(`
+		err = tx.QueryRowContext(ctx, `
 			INSERT INTO tournament_rounds (tournament_id, round_number, format)
 			VALUES ($1, $2, $3)
 			RETURNING id
@@ -571,15 +804,15 @@ func ArchiveTournament(c *gin.Context) {
 		}
 
 		// Archive matches for this round
-		_, err = tx.Exec(`
+		_, err = tx.ExecContext(ctx, `
 			INSERT INTO tournament_matches (
 				tournament_round_id, player1_id, player2_id, player1_name, player2_name,
-				score1, score2, completed
+				score1, score2, completed, result_type
 			)
 			SELECT 
 				$1, m.player1_id, m.player2_id, 
 				COALESCE(p1.name, 'Unknown'), COALESCE(p2.name, 'Unknown'),
-				m.score1, m.score2, m.completed
+				m.score1, m.score2, m.completed AND m.result_type <> 'void', m.result_type
 			FROM matches m
 			LEFT JOIN players p1 ON m.player1_id = p1.id
 			LEFT JOIN players p2 ON m.player2_id = p2.id
@@ -591,53 +824,51 @@ func ArchiveTournament(c *gin.Context) {
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit tournament archive"})
+	if err := playerstats.RefreshTournament(ctx, tx, tournamentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player statistics: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":       "Tournament archived successfully",
-		"tournament_id": tournamentID,
-	})
-}
-
-// GetTournaments returns all archived tournaments
-func GetTournaments(c *gin.Context) {
-	query := `
-		SELECT id, name, month, year, start_date, end_date, created_at, archived_at
-		FROM tournaments
-		ORDER BY year DESC, 
-			CASE month
-				WHEN 'Enero' THEN 1 WHEN 'Febrero' THEN 2 WHEN 'Marzo' THEN 3
-				WHEN 'Abril' THEN 4 WHEN 'Mayo' THEN 5 WHEN 'Junio' THEN 6
-				WHEN 'Julio' THEN 7 WHEN 'Agosto' THEN 8 WHEN 'Septiembre' THEN 9
-				WHEN 'Octubre' THEN 10 WHEN 'Noviembre' THEN 11 WHEN 'Diciembre' THEN 12
-			END DESC
-	`
+	// Infractions and corrections of the running tournament now belong to the archive
+	if _, err := tx.ExecContext(ctx, "UPDATE infractions SET tournament_id = $1 WHERE tournament_id IS NULL", tournamentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive infractions: " + err.Error()})
+		return
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE match_corrections SET tournament_id = $1 WHERE tournament_id IS NULL AND match_source = $2",
+		tournamentID, models.MatchSourceInPerson,
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive match corrections: " + err.Error()})
+		return
+	}
 
-	rows, err := database.DB.Query(query)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournaments"})
+	event := webhooks.TournamentData{
+		TournamentID: tournamentID,
+		Type:         "IN_PERSON",
+		Status:       models.TournamentStatusArchived,
+	}
+	if err := webhooks.Enqueue(ctx, tx, webhooks.EventTournamentArchived, event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
 		return
 	}
-	defer rows.Close()
 
-	tournaments := make([]models.Tournament, 0)
-	for rows.Next() {
-		var t models.Tournament
-		err := rows.Scan(&t.ID, &t.Name, &t.Month, &t.Year, &t.StartDate, &t.EndDate, &t.CreatedAt, &t.ArchivedAt)
-		if err != nil {
-			continue
-		}
-		tournaments = append(tournaments, t)
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit tournament archive"})
+		return
 	}
+	statsChanged()
+
+	webhooks.Emit(webhooks.EventTournamentArchived, event)
 
-	c.JSON(http.StatusOK, tournaments)
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Tournament archived successfully",
+		"tournament_id": tournamentID,
+	})
 }
 
 // GetTournamentStandings returns standings for a specific tournament
 func GetTournamentStandings(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
 	query := `
@@ -650,7 +881,7 @@ func GetTournamentStandings(c *gin.Context) {
 		ORDER BY final_position ASC
 	`
 
-	rows, err := database.DB.Query(query, tournamentID)
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournament standings"})
 		return
@@ -676,11 +907,12 @@ func GetTournamentStandings(c *gin.Context) {
 
 // GetTournamentRounds returns rounds and matches for a specific tournament
 func GetTournamentRounds(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
 	// Get tournament name
 	var tournamentName string
-	err := database.DB.QueryRow(`SELECT name FROM tournaments WHERE id = $1`, tournamentID).Scan(&tournamentName)
+	err := database.DB.QueryRowContext(ctx, `SELECT name FROM tournaments WHERE id = $1`, tournamentID).Scan(&tournamentName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
 		return
@@ -694,7 +926,7 @@ func GetTournamentRounds(c *gin.Context) {
 		ORDER BY round_number
 	`
 
-	rows, err := database.DB.Query(roundsQuery, tournamentID)
+	rows, err := database.DB.QueryContext(ctx, roundsQuery, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rounds"})
 		return
@@ -719,20 +951,20 @@ func GetTournamentRounds(c *gin.Context) {
 
 		// Get matches for this round
 		matchesQuery := `
-			SELECT id, player1_name, player2_name, score1, score2, completed
+			SELECT id, player1_name, player2_name, score1, score2, completed, result_type
 			FROM tournament_matches
 			WHERE tournament_round_id = $1
 			ORDER BY id
 		`
 
-		matchRows, err := database.DB.Query(matchesQuery, roundID)
+		matchRows, err := database.DB.QueryContext(ctx, matchesQuery, roundID)
 		if err != nil {
 			continue
 		}
 
 		for matchRows.Next() {
 			var match models.TournamentMatchInfo
-			err := matchRows.Scan(&match.ID, &match.Player1Name, &match.Player2Name, &match.Score1, &match.Score2, &match.Completed)
+			err := matchRows.Scan(&match.ID, &match.Player1Name, &match.Player2Name, &match.Score1, &match.Score2, &match.Completed, &match.ResultType)
 			if err != nil {
 				continue
 			}
@@ -759,6 +991,7 @@ func GetTournamentRounds(c *gin.Context) {
 
 // GetTournamentRaces returns race statistics for a specific tournament
 func GetTournamentRaces(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
 	// Get PB race counts
@@ -770,7 +1003,7 @@ func GetTournamentRaces(c *gin.Context) {
 		ORDER BY count DESC
 	`
 
-	pbRows, err := database.DB.Query(pbQuery, tournamentID)
+	pbRows, err := database.DB.QueryContext(ctx, pbQuery, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch PB races"})
 		return
@@ -797,7 +1030,7 @@ func GetTournamentRaces(c *gin.Context) {
 		ORDER BY count DESC
 	`
 
-	bfRows, err := database.DB.Query(bfQuery, tournamentID)
+	bfRows, err := database.DB.QueryContext(ctx, bfQuery, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch BF races"})
 		return
@@ -817,21 +1050,16 @@ func GetTournamentRaces(c *gin.Context) {
 
 	// Get PB race winrates
 	pbWinrateQuery := `
-		SELECT tpr.race_pb, COUNT(*) as total_matches, 
-		       SUM(CASE 
-		             WHEN m.player1_id = tpr.player_id AND m.score1 > m.score2 THEN 1
-		             WHEN m.player2_id = tpr.player_id AND m.score2 > m.score1 THEN 1
-		             WHEN m.score1 IS NOT NULL AND m.score2 IS NOT NULL AND m.score1 = m.score2 AND (m.player1_id = tpr.player_id OR m.player2_id = tpr.player_id) THEN 0.5
-		             ELSE 0 
-		           END) as win_points
+		SELECT tpr.race_pb, SUM(pfs.matches) as total_matches,
+		       SUM(pfs.wins + 0.5 * pfs.ties) as win_points
 		FROM tournament_player_races tpr
-		JOIN tournament_rounds tr ON tr.tournament_id = tpr.tournament_id
-		JOIN tournament_matches m ON m.tournament_round_id = tr.id AND tr.format = 'PB' AND (m.player1_id = tpr.player_id OR m.player2_id = tpr.player_id)
+		JOIN player_format_stats pfs ON pfs.tournament_id = tpr.tournament_id
+			AND pfs.player_id = tpr.player_id AND pfs.format = 'PB'
 		WHERE tpr.tournament_id = $1 AND tpr.race_pb IS NOT NULL AND tpr.race_pb != ''
 		GROUP BY tpr.race_pb
 	`
 
-	pbWinrateRows, err := database.DB.Query(pbWinrateQuery, tournamentID)
+	pbWinrateRows, err := database.DB.QueryContext(ctx, pbWinrateQuery, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch PB race winrates"})
 		return
@@ -856,21 +1084,16 @@ func GetTournamentRaces(c *gin.Context) {
 
 	// Get BF race winrates
 	bfWinrateQuery := `
-		SELECT tpr.race_bf, COUNT(*) as total_matches, 
-		       SUM(CASE 
-		             WHEN m.player1_id = tpr.player_id AND m.score1 > m.score2 THEN 1
-		             WHEN m.player2_id = tpr.player_id AND m.score2 > m.score1 THEN 1
-		             WHEN m.score1 IS NOT NULL AND m.score2 IS NOT NULL AND m.score1 = m.score2 AND (m.player1_id = tpr.player_id OR m.player2_id = tpr.player_id) THEN 0.5
-		             ELSE 0 
-		           END) as win_points
+		SELECT tpr.race_bf, SUM(pfs.matches) as total_matches,
+		       SUM(pfs.wins + 0.5 * pfs.ties) as win_points
 		FROM tournament_player_races tpr
-		JOIN tournament_rounds tr ON tr.tournament_id = tpr.tournament_id
-		JOIN tournament_matches m ON m.tournament_round_id = tr.id AND tr.format = 'BF' AND (m.player1_id = tpr.player_id OR m.player2_id = tpr.player_id)
+		JOIN player_format_stats pfs ON pfs.tournament_id = tpr.tournament_id
+			AND pfs.player_id = tpr.player_id AND pfs.format = 'BF'
 		WHERE tpr.tournament_id = $1 AND tpr.race_bf IS NOT NULL AND tpr.race_bf != ''
 		GROUP BY tpr.race_bf
 	`
 
-	bfWinrateRows, err := database.DB.Query(bfWinrateQuery, tournamentID)
+	bfWinrateRows, err := database.DB.QueryContext(ctx, bfWinrateQuery, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch BF race winrates"})
 		return
@@ -905,9 +1128,10 @@ func GetTournamentRaces(c *gin.Context) {
 
 // DeleteArchivedTournament deletes an archived tournament and all its associated data
 func DeleteArchivedTournament(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
-	tx, err := database.DB.Begin()
+	tx, err := database.DB.BeginTx(ctx, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
 		return
@@ -916,7 +1140,7 @@ func DeleteArchivedTournament(c *gin.Context) {
 
 	// Check if tournament exists
 	var exists bool
-	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM tournaments WHERE id = $1)", tournamentID).Scan(&exists)
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM tournaments WHERE id = $1)", tournamentID).Scan(&exists)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check tournament existence"})
 		return
@@ -927,7 +1151,7 @@ func DeleteArchivedTournament(c *gin.Context) {
 	}
 
 	// Delete tournament (CASCADE will handle related records)
-	_, err = tx.Exec("DELETE FROM tournaments WHERE id = $1", tournamentID)
+	_, err = tx.ExecContext(ctx, "DELETE FROM tournaments WHERE id = $1", tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tournament"})
 		return
@@ -937,6 +1161,7 @@ func DeleteArchivedTournament(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit deletion"})
 		return
 	}
+	statsChanged()
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Tournament deleted successfully",
@@ -945,6 +1170,7 @@ func DeleteArchivedTournament(c *gin.Context) {
 
 // GetTournamentPlayerRaces returns all players and their race selections for a specific tournament
 func GetTournamentPlayerRaces(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentIDStr := c.Param("id")
 	tournamentID, err := strconv.Atoi(tournamentIDStr)
 	if err != nil {
@@ -969,7 +1195,7 @@ func GetTournamentPlayerRaces(c *gin.Context) {
 		ORDER BY p.name
 	`
 
-	rows, err := database.DB.Query(query, tournamentID)
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player races"})
 		return
@@ -1009,6 +1235,7 @@ func GetTournamentPlayerRaces(c *gin.Context) {
 
 // UpdatePlayerRace updates race selections for a player in a specific tournament
 func UpdatePlayerRace(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentIDStr := c.Param("id")
 	tournamentID, err := strconv.Atoi(tournamentIDStr)
 	if err != nil {
@@ -1030,7 +1257,7 @@ func UpdatePlayerRace(c *gin.Context) {
 
 	// Check if record exists, if not create it
 	var exists bool
-	err = database.DB.QueryRow(
+	err = database.DB.QueryRowContext(ctx,
 		"SELECT EXISTS(SELECT 1 FROM tournament_player_races WHERE tournament_id = $1 AND player_id = $2)",
 		tournamentID, playerID,
 	).Scan(&exists)
@@ -1059,11 +1286,12 @@ func UpdatePlayerRace(c *gin.Context) {
 		args = []interface{}{tournamentID, playerID, req.PlayerName, req.RacePB, req.RaceBF, req.Notes}
 	}
 
-	_, err = database.DB.Exec(query, args...)
+	_, err = database.DB.ExecContext(ctx, query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player race"})
 		return
 	}
+	statsChanged()
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Player race updated successfully",
@@ -1072,6 +1300,7 @@ func UpdatePlayerRace(c *gin.Context) {
 
 // GetArchivedTournamentPlayers returns all players who participated in a specific archived tournament
 func GetArchivedTournamentPlayers(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentIDStr := c.Param("id")
 	tournamentID, err := strconv.Atoi(tournamentIDStr)
 	if err != nil {
@@ -1092,7 +1321,7 @@ func GetArchivedTournamentPlayers(c *gin.Context) {
 		ORDER BY player_name
 	`
 
-	rows, err := database.DB.Query(query, tournamentID)
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournament players"})
 		return
@@ -1138,9 +1367,10 @@ func GetArchivedTournamentPlayers(c *gin.Context) {
 
 // GetPremierPlayers returns all players from the premier_players table
 func GetPremierPlayers(c *gin.Context) {
+	ctx := c.Request.Context()
 	query := `SELECT id, name FROM premier_players ORDER BY name`
 
-	rows, err := database.DB.Query(query)
+	rows, err := database.DB.QueryContext(ctx, query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch players"})
 		return
@@ -1170,130 +1400,52 @@ func GetPremierPlayers(c *gin.Context) {
 	c.JSON(http.StatusOK, players)
 }
 
-// GetGlobalStandings returns aggregated standings from all archived tournaments
+// GetGlobalStandings returns aggregated standings from all archived tournaments.
+// They are read from the global_player_stats view and cached until the archive
+// changes; the ETag and Last-Modified headers let clients revalidate.
 func GetGlobalStandings(c *gin.Context) {
-	// Get all players with medal counts and most-played races from all tournaments
-	query := `
+	ctx := c.Request.Context()
+	entry, err := cache.Load(c.Request.Context(), statsCacheTag, "global-standings", func() (interface{}, error) {
+		return fetchGlobalStandings(ctx)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch global standings"})
+		return
+	}
+	cache.Serve(c, entry)
+}
+
+// fetchGlobalStandings loads the medals, most played races and winrates of every
+// premier player
+func fetchGlobalStandings(ctx context.Context) ([]models.GlobalStanding, error) {
+	rows, err := database.DB.QueryContext(ctx, `
 		SELECT 
 			pp.id,
 			pp.name,
-			COALESCE(SUM(CASE WHEN ts.final_position = 1 THEN 1 ELSE 0 END), 0) as first_place_count,
-			COALESCE(SUM(CASE WHEN ts.final_position = 2 THEN 1 ELSE 0 END), 0) as second_place_count,
-			COALESCE(SUM(CASE WHEN ts.final_position = 3 THEN 1 ELSE 0 END), 0) as third_place_count,
-			-- Most played race PB
-			(
-				SELECT race_pb
-				FROM tournament_player_races tpr2
-				WHERE tpr2.player_name = pp.name AND tpr2.race_pb IS NOT NULL AND tpr2.race_pb != ''
-				GROUP BY race_pb
-				ORDER BY COUNT(*) DESC
-				LIMIT 1
-			) as most_played_race_pb,
-			-- Most played race BF
-			(
-				SELECT race_bf
-				FROM tournament_player_races tpr3
-				WHERE tpr3.player_name = pp.name AND tpr3.race_bf IS NOT NULL AND tpr3.race_bf != ''
-				GROUP BY race_bf
-				ORDER BY COUNT(*) DESC
-				LIMIT 1
-			) as most_played_race_bf,
-			-- PB stats aggregated from all tournaments for this player
-			(
-				SELECT COALESCE(SUM(CASE 
-					WHEN tm.completed = true AND (
-						(tm.player1_id = ts_inner.player_id AND tm.score1 > tm.score2) OR
-						(tm.player2_id = ts_inner.player_id AND tm.score2 > tm.score1)
-					) THEN 1 ELSE 0 
-				END), 0)
-				FROM tournament_standings ts_inner
-				JOIN tournament_rounds tr2 ON tr2.tournament_id = ts_inner.tournament_id
-				LEFT JOIN tournament_matches tm ON tr2.id = tm.tournament_round_id
-				WHERE ts_inner.player_name = pp.name AND tr2.format = 'PB' AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id)
-			) as pb_wins,
-			(
-				SELECT COALESCE(SUM(CASE 
-					WHEN tm.completed = true AND tm.score1 = tm.score2 AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id) THEN 1 ELSE 0 
-				END), 0)
-				FROM tournament_standings ts_inner
-				JOIN tournament_rounds tr2 ON tr2.tournament_id = ts_inner.tournament_id
-				LEFT JOIN tournament_matches tm ON tr2.id = tm.tournament_round_id
-				WHERE ts_inner.player_name = pp.name AND tr2.format = 'PB' AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id)
-			) as pb_ties,
-			(
-				SELECT COALESCE(SUM(CASE 
-					WHEN tm.completed = true AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id) THEN 1 ELSE 0 
-				END), 0)
-				FROM tournament_standings ts_inner
-				JOIN tournament_rounds tr2 ON tr2.tournament_id = ts_inner.tournament_id
-				LEFT JOIN tournament_matches tm ON tr2.id = tm.tournament_round_id
-				WHERE ts_inner.player_name = pp.name AND tr2.format = 'PB' AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id)
-			) as pb_matches,
-			-- BF stats aggregated from all tournaments for this player
-			(
-				SELECT COALESCE(SUM(CASE 
-					WHEN tm.completed = true AND (
-						(tm.player1_id = ts_inner.player_id AND tm.score1 > tm.score2) OR
-						(tm.player2_id = ts_inner.player_id AND tm.score2 > tm.score1)
-					) THEN 1 ELSE 0 
-				END), 0)
-				FROM tournament_standings ts_inner
-				JOIN tournament_rounds tr2 ON tr2.tournament_id = ts_inner.tournament_id
-				LEFT JOIN tournament_matches tm ON tr2.id = tm.tournament_round_id
-				WHERE ts_inner.player_name = pp.name AND tr2.format = 'BF' AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id)
-			) as bf_wins,
-			(
-				SELECT COALESCE(SUM(CASE 
-					WHEN tm.completed = true AND tm.score1 = tm.score2 AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id) THEN 1 ELSE 0 
-				END), 0)
-				FROM tournament_standings ts_inner
-				JOIN tournament_rounds tr2 ON tr2.tournament_id = ts_inner.tournament_id
-				LEFT JOIN tournament_matches tm ON tr2.id = tm.tournament_round_id
-				WHERE ts_inner.player_name = pp.name AND tr2.format = 'BF' AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id)
-			) as bf_ties,
-			(
-				SELECT COALESCE(SUM(CASE 
-					WHEN tm.completed = true AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id) THEN 1 ELSE 0 
-				END), 0)
-				FROM tournament_standings ts_inner
-				JOIN tournament_rounds tr2 ON tr2.tournament_id = ts_inner.tournament_id
-				LEFT JOIN tournament_matches tm ON tr2.id = tm.tournament_round_id
-				WHERE ts_inner.player_name = pp.name AND tr2.format = 'BF' AND (tm.player1_id = ts_inner.player_id OR tm.player2_id = ts_inner.player_id)
-			) as bf_matches
+			COALESCE(g.first_place_count, 0),
+			COALESCE(g.second_place_count, 0),
+			COALESCE(g.third_place_count, 0),
+			g.most_played_race_pb,
+			g.most_played_race_bf,
+			COALESCE(g.pb_wins, 0),
+			COALESCE(g.pb_ties, 0),
+			COALESCE(g.pb_matches, 0),
+			COALESCE(g.bf_wins, 0),
+			COALESCE(g.bf_ties, 0),
+			COALESCE(g.bf_matches, 0)
 		FROM premier_players pp
-		LEFT JOIN tournament_standings ts ON ts.player_name = pp.name
-		GROUP BY pp.id, pp.name
-		ORDER BY first_place_count DESC, second_place_count DESC, third_place_count DESC
-	`
-
-	rows, err := database.DB.Query(query)
+		LEFT JOIN global_player_stats g ON g.player_name = pp.name
+		ORDER BY 3 DESC, 4 DESC, 5 DESC, pp.name
+	`)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch global standings"})
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
-	type GlobalStanding struct {
-		PlayerID         int     `json:"player_id"`
-		PlayerName       string  `json:"player_name"`
-		FirstPlaceCount  int     `json:"first_place_count"`
-		SecondPlaceCount int     `json:"second_place_count"`
-		ThirdPlaceCount  int     `json:"third_place_count"`
-		MostPlayedRacePB *string `json:"most_played_race_pb"`
-		MostPlayedRaceBF *string `json:"most_played_race_bf"`
-		WinratePB        float64 `json:"winrate_pb"`
-		WinateBF         float64 `json:"winrate_bf"`
-	}
-
-	standings := []GlobalStanding{}
+	standings := []models.GlobalStanding{}
 	for rows.Next() {
-		var s GlobalStanding
-		var pbWins int
-		var pbTies int
-		var pbMatches int
-		var bfWins int
-		var bfTies int
-		var bfMatches int
+		var s models.GlobalStanding
+		var pbWins, pbTies, pbMatches, bfWins, bfTies, bfMatches int
 
 		err := rows.Scan(
 			&s.PlayerID,
@@ -1311,169 +1463,67 @@ func GetGlobalStandings(c *gin.Context) {
 			&bfMatches,
 		)
 		if err != nil {
-			continue
+			return nil, err
 		}
 
-		// Calculate PB winrate: (wins + 0.5*ties) / total_matches * 100
+		// Winrate: (wins + 0.5*ties) / total_matches * 100
 		if pbMatches > 0 {
 			s.WinratePB = ((float64(pbWins) + 0.5*float64(pbTies)) / float64(pbMatches)) * 100.0
 		}
-
-		// Calculate BF winrate: (wins + 0.5*ties) / total_matches * 100
 		if bfMatches > 0 {
-			s.WinateBF = ((float64(bfWins) + 0.5*float64(bfTies)) / float64(bfMatches)) * 100.0
+			s.WinrateBF = ((float64(bfWins) + 0.5*float64(bfTies)) / float64(bfMatches)) * 100.0
 		}
 
 		standings = append(standings, s)
 	}
-
-	if standings == nil {
-		standings = []GlobalStanding{}
-	}
-
-	c.JSON(http.StatusOK, standings)
+	return standings, rows.Err()
 }
 
-// GetGlobalRaces returns aggregated race statistics from all archived tournaments
+// GetGlobalRaces returns aggregated race statistics from all archived tournaments,
+// read from the global_race_stats view and cached like GetGlobalStandings
 func GetGlobalRaces(c *gin.Context) {
-	// Get PB race counts from all tournaments
-	pbQuery := `
-		SELECT race_pb, COUNT(*) as count
-		FROM tournament_player_races
-		WHERE race_pb IS NOT NULL AND race_pb != ''
-		GROUP BY race_pb
-		ORDER BY count DESC
-	`
-
-	pbRows, err := database.DB.Query(pbQuery)
+	ctx := c.Request.Context()
+	entry, err := cache.Load(c.Request.Context(), statsCacheTag, "global-races", func() (interface{}, error) {
+		return fetchGlobalRaces(ctx)
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch global PB races"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch global races"})
 		return
 	}
-	defer pbRows.Close()
-
-	pbRaces := make(map[string]int)
-	for pbRows.Next() {
-		var race string
-		var count int
-		err := pbRows.Scan(&race, &count)
-		if err != nil {
-			continue
-		}
-		pbRaces[race] = count
-	}
-
-	// Get BF race counts from all tournaments
-	bfQuery := `
-		SELECT race_bf, COUNT(*) as count
-		FROM tournament_player_races
-		WHERE race_bf IS NOT NULL AND race_bf != ''
-		GROUP BY race_bf
-		ORDER BY count DESC
-	`
+	cache.Serve(c, entry)
+}
 
-	bfRows, err := database.DB.Query(bfQuery)
+// fetchGlobalRaces loads how often each race was played in each format and its
+// winrate, for the races with matches
+func fetchGlobalRaces(ctx context.Context) (*models.GlobalRaces, error) {
+	rows, err := database.DB.QueryContext(ctx, `SELECT format, race, picks, total_matches, win_points FROM global_race_stats`)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch global BF races"})
-		return
+		return nil, err
 	}
-	defer bfRows.Close()
-
-	bfRaces := make(map[string]int)
-	for bfRows.Next() {
-		var race string
-		var count int
-		err := bfRows.Scan(&race, &count)
-		if err != nil {
-			continue
-		}
-		bfRaces[race] = count
-	}
-
-	// Get PB race winrates from all tournaments
-	pbWinrateQuery := `
-		SELECT tpr.race_pb, COUNT(*) as total_matches, 
-		       SUM(CASE 
-		             WHEN m.player1_id = tpr.player_id AND m.score1 > m.score2 THEN 1
-		             WHEN m.player2_id = tpr.player_id AND m.score2 > m.score1 THEN 1
-		             WHEN m.score1 IS NOT NULL AND m.score2 IS NOT NULL AND m.score1 = m.score2 AND (m.player1_id = tpr.player_id OR m.player2_id = tpr.player_id) THEN 0.5
-		             ELSE 0 
-		           END) as win_points
-		FROM tournament_player_races tpr
-		JOIN tournament_rounds tr ON tr.tournament_id = tpr.tournament_id
-		JOIN tournament_matches m ON m.tournament_round_id = tr.id AND tr.format = 'PB' AND (m.player1_id = tpr.player_id OR m.player2_id = tpr.player_id)
-		WHERE tpr.race_pb IS NOT NULL AND tpr.race_pb != ''
-		GROUP BY tpr.race_pb
-	`
+	defer rows.Close()
 
-	pbWinrateRows, err := database.DB.Query(pbWinrateQuery)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch global PB race winrates"})
-		return
+	races := &models.GlobalRaces{
+		PBRaces:        map[string]int{},
+		BFRaces:        map[string]int{},
+		PBRaceWinrates: map[string]float64{},
+		BFRaceWinrates: map[string]float64{},
 	}
-	defer pbWinrateRows.Close()
-
-	pbRaceWinrates := make(map[string]float64)
-	for pbWinrateRows.Next() {
-		var race string
-		var totalMatches int
+	for rows.Next() {
+		var format, race string
+		var picks, totalMatches int
 		var winPoints float64
-		err := pbWinrateRows.Scan(&race, &totalMatches, &winPoints)
-		if err != nil {
-			continue
-		}
-		if totalMatches > 0 {
-			pbRaceWinrates[race] = (winPoints * 100.0) / float64(totalMatches)
-		} else {
-			pbRaceWinrates[race] = 0.0
+		if err := rows.Scan(&format, &race, &picks, &totalMatches, &winPoints); err != nil {
+			return nil, err
 		}
-	}
-
-	// Get BF race winrates from all tournaments
-	bfWinrateQuery := `
-		SELECT tpr.race_bf, COUNT(*) as total_matches, 
-		       SUM(CASE 
-		             WHEN m.player1_id = tpr.player_id AND m.score1 > m.score2 THEN 1
-		             WHEN m.player2_id = tpr.player_id AND m.score2 > m.score1 THEN 1
-		             WHEN m.score1 IS NOT NULL AND m.score2 IS NOT NULL AND m.score1 = m.score2 AND (m.player1_id = tpr.player_id OR m.player2_id = tpr.player_id) THEN 0.5
-		             ELSE 0 
-		           END) as win_points
-		FROM tournament_player_races tpr
-		JOIN tournament_rounds tr ON tr.tournament_id = tpr.tournament_id
-		JOIN tournament_matches m ON m.tournament_round_id = tr.id AND tr.format = 'BF' AND (m.player1_id = tpr.player_id OR m.player2_id = tpr.player_id)
-		WHERE tpr.race_bf IS NOT NULL AND tpr.race_bf != ''
-		GROUP BY tpr.race_bf
-	`
-
-	bfWinrateRows, err := database.DB.Query(bfWinrateQuery)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch global BF race winrates"})
-		return
-	}
-	defer bfWinrateRows.Close()
 
-	bfRaceWinrates := make(map[string]float64)
-	for bfWinrateRows.Next() {
-		var race string
-		var totalMatches int
-		var winPoints float64
-		err := bfWinrateRows.Scan(&race, &totalMatches, &winPoints)
-		if err != nil {
-			continue
+		counts, winrates := races.PBRaces, races.PBRaceWinrates
+		if format == "BF" {
+			counts, winrates = races.BFRaces, races.BFRaceWinrates
 		}
+		counts[race] = picks
 		if totalMatches > 0 {
-			bfRaceWinrates[race] = (winPoints * 100.0) / float64(totalMatches)
-		} else {
-			bfRaceWinrates[race] = 0.0
+			winrates[race] = (winPoints * 100.0) / float64(totalMatches)
 		}
 	}
-
-	response := gin.H{
-		"pb_races":         pbRaces,
-		"bf_races":         bfRaces,
-		"pb_race_winrates": pbRaceWinrates,
-		"bf_race_winrates": bfRaceWinrates,
-	}
-
-	c.JSON(http.StatusOK, response)
+	return races, rows.Err()
 }
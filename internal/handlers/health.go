@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// healthTimeout bounds the database checks of /health and /ready
+const healthTimeout = 2 * time.Second
+
+var (
+	// draining is closed when the server starts shutting down
+	draining  = make(chan struct{})
+	drainOnce sync.Once
+)
+
+// Drain marks the server as shutting down: /ready fails, so no new traffic is routed
+// here, and event streams end so their connections can close
+func Drain() {
+	drainOnce.Do(func() { close(draining) })
+}
+
+func isDraining() bool {
+	select {
+	case <-draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// Health answers whether the server is up and reaches the database
+func Health(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthTimeout)
+	defer cancel()
+
+	if err := database.Ping(ctx); err != nil {
+		logging.FromContext(ctx).Warn("health check failed", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "database": "unreachable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "database": "ok"})
+}
+
+// Ready answers whether the server should be sent traffic: it is not shutting down,
+// the database answers and every migration has been applied
+func Ready(c *gin.Context) {
+	if isDraining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthTimeout)
+	defer cancel()
+
+	if err := database.Ping(ctx); err != nil {
+		logging.FromContext(ctx).Warn("readiness check failed", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "database": "unreachable"})
+		return
+	}
+
+	pending, err := database.PendingMigrations(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Warn("readiness check failed", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "database": "ok", "migrations": "unknown"})
+		return
+	}
+	if len(pending) > 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "database": "ok", "migrations": "pending", "pending": pending})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "database": "ok", "migrations": "up to date"})
+}
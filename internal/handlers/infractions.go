@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/roundclock"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+// infractionColumns are the columns scanned by scanInfraction, for infractions aliased i
+// joined with tournaments aliased t
+const infractionColumns = `
+	i.id, i.tournament_id, t.name, i.match_id, i.round_number, i.player_name, i.opponent_name,
+	i.infraction_type, i.penalty, i.judge, i.notes, i.created_at
+`
+
+func scanInfraction(rows *sql.Rows) (models.Infraction, error) {
+	var i models.Infraction
+	err := rows.Scan(
+		&i.ID,
+		&i.TournamentID,
+		&i.TournamentName,
+		&i.MatchID,
+		&i.RoundNumber,
+		&i.PlayerName,
+		&i.OpponentName,
+		&i.Type,
+		&i.Penalty,
+		&i.Judge,
+		&i.Notes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+// gameLossScore gives the offender's opponent the game in progress. Matches are best
+// of three, so if three games would be counted the offender gives back a game they
+// won. A match the offender already lost does not change.
+func gameLossScore(offender, opponent int) (int, int) {
+	if opponent >= models.ForfeitWinScore {
+		return offender, opponent
+	}
+	opponent++
+	if offender+opponent > 2*models.ForfeitWinScore-1 {
+		offender--
+	}
+	return offender, opponent
+}
+
+// CreateInfraction records a judge ruling against an in-person player and applies its
+// penalty: a game loss gives the opponent a game of the match, a match loss gives them
+// the match (2-0), and a disqualification loses the match and disqualifies the player,
+// forfeiting their pending matches. Results change through the same path as
+// UpdateMatchScore.
+func CreateInfraction(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req models.CreateInfractionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.MatchID == nil && (req.Penalty == models.PenaltyGameLoss || req.Penalty == models.PenaltyMatchLoss) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("match_id is required for a %s penalty", req.Penalty)})
+		return
+	}
+	if req.MatchID == nil && req.TimeExtensionMinutes > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "match_id is required for a time extension"})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var playerName, playerStatus string
+	err = tx.QueryRowContext(ctx, "SELECT name, status FROM players WHERE id = $1 FOR UPDATE", req.PlayerID).Scan(&playerName, &playerStatus)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player"})
+		return
+	}
+	if req.Penalty == models.PenaltyDisqualification && playerStatus == models.PlayerStatusDisqualified {
+		c.JSON(http.StatusConflict, gin.H{"error": "Player is already disqualified"})
+		return
+	}
+
+	infraction := models.Infraction{
+		MatchID:    req.MatchID,
+		PlayerName: playerName,
+		Type:       req.Type,
+		Penalty:    req.Penalty,
+		Judge:      req.Judge,
+		Notes:      req.Notes,
+	}
+
+	// match.completed events queued in tx, emitted after commit
+	var completedEvents []webhooks.MatchData
+
+	if req.MatchID != nil {
+		var player1ID, player2ID, roundNumber int
+		var player1Name, player2Name string
+		var score1, score2 sql.NullInt64
+		var completed, locked bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT m.player1_id, m.player2_id, p1.name, p2.name, m.score1, m.score2, m.completed,
+				r.round_number, r.locked_at IS NOT NULL
+			FROM matches m
+			JOIN rounds r ON m.round_id = r.id
+			JOIN players p1 ON m.player1_id = p1.id
+			JOIN players p2 ON m.player2_id = p2.id
+			WHERE m.id = $1
+			FOR UPDATE OF m FOR SHARE OF r
+		`, *req.MatchID).Scan(&player1ID, &player2ID, &player1Name, &player2Name, &score1, &score2, &completed, &roundNumber, &locked)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+			return
+		}
+
+		isPlayer1 := player1ID == req.PlayerID
+		if !isPlayer1 && player2ID != req.PlayerID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Player is not in this match"})
+			return
+		}
+		if locked && req.Penalty != models.PenaltyWarning {
+			respondRoundLocked(c, roundNumber)
+			return
+		}
+		opponentName := player2Name
+		if !isPlayer1 {
+			opponentName = player1Name
+		}
+		infraction.RoundNumber = &roundNumber
+		infraction.OpponentName = &opponentName
+
+		// Scores from the offender's point of view
+		offender, opponent := int(score1.Int64), int(score2.Int64)
+		if !isPlayer1 {
+			offender, opponent = opponent, offender
+		}
+
+		resultType := models.MatchResultPlayed
+		switch req.Penalty {
+		case models.PenaltyGameLoss:
+			offender, opponent = gameLossScore(offender, opponent)
+			completed = completed || opponent == models.ForfeitWinScore
+		case models.PenaltyMatchLoss, models.PenaltyDisqualification:
+			offender, opponent = 0, models.ForfeitWinScore
+			completed = true
+			resultType = models.MatchResultForfeit
+		}
+
+		if req.Penalty != models.PenaltyWarning {
+			newScore1, newScore2 := offender, opponent
+			if !isPlayer1 {
+				newScore1, newScore2 = opponent, offender
+			}
+			if err := setMatchResult(ctx, tx, *req.MatchID, newScore1, newScore2, resultType, completed); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply penalty"})
+				return
+			}
+
+			// A penalty that decides the match is a result like any other
+			if completed {
+				match, err := fetchMatchDetail(ctx, tx, *req.MatchID)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+					return
+				}
+				event := webhooks.MatchData{Source: models.MatchSourceInPerson, Match: match}
+				if err := webhooks.Enqueue(ctx, tx, webhooks.EventMatchCompleted, event); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
+					return
+				}
+				completedEvents = append(completedEvents, event)
+			}
+		}
+
+		// Time lost to the judge call
+		if req.TimeExtensionMinutes > 0 {
+			reason := "Judge call: " + req.Type
+			if err := addMatchTimeExtension(ctx, tx, *req.MatchID, req.TimeExtensionMinutes*60, &reason); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add time extension"})
+				return
+			}
+		}
+	}
+
+	response := models.InfractionResponse{}
+	if req.Penalty == models.PenaltyDisqualification {
+		reason := "Disqualified: " + req.Type
+		if req.Notes != nil {
+			reason += " - " + *req.Notes
+		}
+		forfeited, _, events, err := withdrawInPersonPlayer(ctx, tx, req.PlayerID, models.PlayerStatusDisqualified, &reason, models.DropPolicyForfeit)
+		var lockedErr *roundLockedError
+		if errors.As(err, &lockedErr) {
+			respondRoundLocked(c, lockedErr.RoundNumber)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disqualify player"})
+			return
+		}
+		response.MatchesForfeited = forfeited
+		completedEvents = append(completedEvents, events...)
+	}
+
+	// Earlier infractions of the player, before this one is added
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE infraction_type = $2),
+			COUNT(*) FILTER (WHERE infraction_type = $2 AND tournament_id IS NULL)
+		FROM infractions
+		WHERE LOWER(TRIM(player_name)) = LOWER(TRIM($1))
+	`, playerName, req.Type).Scan(&response.PreviousTotal, &response.PreviousSameType, &response.PreviousSameTypeTournament)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch infraction history"})
+		return
+	}
+	response.RepeatOffender = response.PreviousSameType > 0
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO infractions (match_id, round_number, player_name, opponent_name, infraction_type, penalty, judge, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, infraction.MatchID, infraction.RoundNumber, infraction.PlayerName, infraction.OpponentName,
+		infraction.Type, infraction.Penalty, infraction.Judge, infraction.Notes,
+	).Scan(&infraction.ID, &infraction.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record infraction"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	response.Infraction = infraction
+
+	if req.MatchID != nil || len(completedEvents) > 0 {
+		roundclock.Notify()
+	}
+	for _, event := range completedEvents {
+		webhooks.Emit(webhooks.EventMatchCompleted, event)
+	}
+
+	if req.MatchID != nil {
+		match, err := fetchMatchDetail(ctx, database.DB, *req.MatchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+			return
+		}
+		response.Match = match
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetInfractions lists the infractions of the running in-person tournament, or of an
+// archived one with ?tournament_id=. ?round=N keeps a single round.
+func GetInfractions(c *gin.Context) {
+	ctx := c.Request.Context()
+	where := "i.tournament_id IS NULL"
+	args := []interface{}{}
+
+	if tournamentParam := c.Query("tournament_id"); tournamentParam != "" {
+		tournamentID, err := strconv.Atoi(tournamentParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+			return
+		}
+		args = append(args, tournamentID)
+		where = "i.tournament_id = $1"
+	}
+	if roundParam := c.Query("round"); roundParam != "" {
+		roundNumber, err := strconv.Atoi(roundParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round number"})
+			return
+		}
+		args = append(args, roundNumber)
+		where += fmt.Sprintf(" AND i.round_number = $%d", len(args))
+	}
+
+	query := `SELECT ` + infractionColumns + `
+		FROM infractions i
+		LEFT JOIN tournaments t ON t.id = i.tournament_id
+		WHERE ` + where
+	query += " ORDER BY i.created_at, i.id"
+
+	rows, err := database.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch infractions"})
+		return
+	}
+	defer rows.Close()
+
+	infractions := []models.Infraction{}
+	for rows.Next() {
+		i, err := scanInfraction(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan infraction"})
+			return
+		}
+		infractions = append(infractions, i)
+	}
+
+	c.JSON(http.StatusOK, infractions)
+}
+
+// GetPlayerInfractions returns every infraction of a player across tournaments, newest
+// first. Like the tournament history, the player is matched by name; the ID may be an
+// in-person player or a premier player, or ?name= can be given instead.
+func GetPlayerInfractions(c *gin.Context) {
+	ctx := c.Request.Context()
+	playerName := c.Query("name")
+	if playerName == "" {
+		playerID, err := strconv.Atoi(c.Param("player_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+			return
+		}
+		err = database.DB.QueryRowContext(ctx, "SELECT name FROM players WHERE id = $1", playerID).Scan(&playerName)
+		if err == sql.ErrNoRows {
+			err = database.DB.QueryRowContext(ctx, "SELECT name FROM premier_players WHERE id = $1", playerID).Scan(&playerName)
+		}
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player"})
+			return
+		}
+	}
+
+	rows, err := database.DB.QueryContext(ctx, `SELECT `+infractionColumns+`
+		FROM infractions i
+		LEFT JOIN tournaments t ON t.id = i.tournament_id
+		WHERE LOWER(TRIM(i.player_name)) = LOWER(TRIM($1))
+		ORDER BY i.created_at DESC, i.id DESC
+	`, playerName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch infractions"})
+		return
+	}
+	defer rows.Close()
+
+	history := models.PlayerInfractionHistory{
+		PlayerName:  playerName,
+		ByType:      map[string]int{},
+		ByPenalty:   map[string]int{},
+		Infractions: []models.Infraction{},
+	}
+	for rows.Next() {
+		i, err := scanInfraction(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan infraction"})
+			return
+		}
+		history.Total++
+		history.ByType[i.Type]++
+		history.ByPenalty[i.Penalty]++
+		history.Infractions = append(history.Infractions, i)
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import "testing"
+
+func TestGameLossScore(t *testing.T) {
+	tests := []struct {
+		name                 string
+		offender, opponent   int
+		wantOffender, wantOp int
+	}{
+		{"first game", 0, 0, 0, 1},
+		{"offender ahead", 1, 0, 1, 1},
+		{"third game", 1, 1, 1, 2},
+		{"opponent wins the match", 0, 1, 0, 2},
+		{"offender had won", 2, 0, 2, 1},
+		{"offender gives back a game", 2, 1, 1, 2},
+		{"already lost", 0, 2, 0, 2},
+		{"already lost 1-2", 1, 2, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offender, opponent := gameLossScore(tt.offender, tt.opponent)
+			if offender != tt.wantOffender || opponent != tt.wantOp {
+				t.Errorf("gameLossScore(%d, %d) = %d-%d, want %d-%d",
+					tt.offender, tt.opponent, offender, opponent, tt.wantOffender, tt.wantOp)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/notify"
+	"github.com/gin-gonic/gin"
+)
+
+// playerContactColumns are the columns scanned by scanPlayerContact, from
+// player_contacts pc joined with premier_players pp
+const playerContactColumns = `pc.player_id, pp.name, pc.email, pc.locale, pc.notify_pairings, pc.notify_deadlines,
+	pc.notify_results, pc.notify_tournaments, pc.created_at, pc.updated_at`
+
+func scanPlayerContact(row rowScanner) (models.PlayerContact, error) {
+	var pc models.PlayerContact
+	err := row.Scan(&pc.PlayerID, &pc.PlayerName, &pc.Email, &pc.Locale, &pc.NotifyPairings, &pc.NotifyDeadlines,
+		&pc.NotifyResults, &pc.NotifyTournaments, &pc.CreatedAt, &pc.UpdatedAt)
+	return pc, err
+}
+
+func fetchPlayerContact(ctx context.Context, playerID int) (models.PlayerContact, error) {
+	return scanPlayerContact(database.DB.QueryRowContext(ctx, `
+		SELECT `+playerContactColumns+`
+		FROM player_contacts pc
+		JOIN premier_players pp ON pp.id = pc.player_id
+		WHERE pc.player_id = $1
+	`, playerID))
+}
+
+// GetPlayerContact returns the email and notification preferences of a premier player
+func GetPlayerContact(c *gin.Context) {
+	ctx := c.Request.Context()
+	playerID, err := strconv.Atoi(c.Param("player_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	contact, err := fetchPlayerContact(ctx, playerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Player has no contact"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contact"})
+		return
+	}
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// UpdatePlayerContact creates or changes the contact of a premier player. Fields left
+// out keep their value; a new contact is in Spanish with every notification on.
+func UpdatePlayerContact(c *gin.Context) {
+	ctx := c.Request.Context()
+	playerID, err := strconv.Atoi(c.Param("player_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	var req models.UpdatePlayerContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var exists bool
+	if err := database.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM premier_players WHERE id = $1)", playerID).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+		return
+	}
+
+	_, err = database.DB.ExecContext(ctx, `
+		INSERT INTO player_contacts (player_id, email, locale, notify_pairings, notify_deadlines,
+			notify_results, notify_tournaments)
+		VALUES ($1, $2, COALESCE($3, 'es'), COALESCE($4, true), COALESCE($5, true),
+			COALESCE($6, true), COALESCE($7, true))
+		ON CONFLICT (player_id) DO UPDATE
+		SET email = COALESCE($2, player_contacts.email),
+			locale = COALESCE($3, player_contacts.locale),
+			notify_pairings = COALESCE($4, player_contacts.notify_pairings),
+			notify_deadlines = COALESCE($5, player_contacts.notify_deadlines),
+			notify_results = COALESCE($6, player_contacts.notify_results),
+			notify_tournaments = COALESCE($7, player_contacts.notify_tournaments)
+	`, playerID, req.Email, req.Locale, req.NotifyPairings, req.NotifyDeadlines, req.NotifyResults, req.NotifyTournaments)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save contact"})
+		return
+	}
+
+	contact, err := fetchPlayerContact(ctx, playerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contact"})
+		return
+	}
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// DeletePlayerContact removes the contact of a premier player, who gets no more
+// notifications. Notifications already queued are still sent.
+func DeletePlayerContact(c *gin.Context) {
+	ctx := c.Request.Context()
+	playerID, err := strconv.Atoi(c.Param("player_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	result, err := database.DB.ExecContext(ctx, "DELETE FROM player_contacts WHERE player_id = $1", playerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete contact"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player has no contact"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contact deleted successfully", "player_id": playerID})
+}
+
+// TestPlayerContact queues a test message to a player, whatever their preferences
+func TestPlayerContact(c *gin.Context) {
+	playerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	notificationID, err := notify.Queue(c.Request.Context(), database.DB, playerID, notify.KindAlways, notify.TemplateTest, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue test notification"})
+		return
+	}
+	if notificationID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player has no contact email"})
+		return
+	}
+	notify.Wake()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Test notification queued", "notification_id": notificationID})
+}
+
+// GetNotifications lists the latest notifications, newest first. ?status= keeps
+// pending, sent or failed ones, ?player_id= those of a player; ?limit= defaults to 50.
+func GetNotifications(c *gin.Context) {
+	ctx := c.Request.Context()
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	args := []interface{}{limit}
+	where := "TRUE"
+	if status := c.Query("status"); status != "" {
+		switch status {
+		case models.NotificationPending, models.NotificationSent, models.NotificationFailed:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, sent or failed"})
+			return
+		}
+		args = append(args, status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if value := c.Query("player_id"); value != "" {
+		playerID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+			return
+		}
+		args = append(args, playerID)
+		where += fmt.Sprintf(" AND player_id = $%d", len(args))
+	}
+
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT id, player_id, channel, recipient, template, locale, subject, body, status, attempts,
+			next_attempt_at, last_attempt_at, last_error, sent_at, created_at
+		FROM notifications
+		WHERE `+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(&n.ID, &n.PlayerID, &n.Channel, &n.Recipient, &n.Template, &n.Locale, &n.Subject, &n.Body,
+			&n.Status, &n.Attempts, &n.NextAttemptAt, &n.LastAttemptAt, &n.LastError, &n.SentAt, &n.CreatedAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan notification"})
+			return
+		}
+		notifications = append(notifications, n)
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// RetryNotification sends a failed notification again, with a fresh set of attempts
+func RetryNotification(c *gin.Context) {
+	ctx := c.Request.Context()
+	notificationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	var status string
+	err = database.DB.QueryRowContext(ctx, "SELECT status FROM notifications WHERE id = $1", notificationID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification"})
+		return
+	}
+	if status != models.NotificationFailed {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Only failed notifications can be retried (this one is %s)", status)})
+		return
+	}
+
+	_, err = database.DB.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = $1, attempts = 0, next_attempt_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3
+	`, models.NotificationPending, notificationID, models.NotificationFailed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry notification"})
+		return
+	}
+	notify.Wake()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Notification queued again", "notification_id": notificationID})
+}
@@ -0,0 +1,611 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMatchdayIntervalDays = 7
+
+// ScheduleOnlineMatchdays splits the round-robin of an online tournament into matchdays
+// (circle method) and gives each matchday a deadline, starting at first_deadline and
+// spaced interval_days apart. Deadlines set on individual matches are kept.
+func ScheduleOnlineMatchdays(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	var req models.ScheduleMatchdaysRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	intervalDays := req.IntervalDays
+	if intervalDays == 0 {
+		intervalDays = defaultMatchdayIntervalDays
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	if !requireOnlineTournamentOpen(c, tx, tournamentID) {
+		return
+	}
+
+	// Every player, withdrawn or not, so the pairings line up with the generated matches
+	playerIDs := []int{}
+	rows, err := tx.QueryContext(ctx, "SELECT player_id FROM online_tournament_players WHERE tournament_id = $1 ORDER BY player_id", tournamentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournament players"})
+		return
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan tournament player"})
+			return
+		}
+		playerIDs = append(playerIDs, id)
+	}
+	rows.Close()
+
+	if len(playerIDs) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least 2 players are required"})
+		return
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM online_tournament_matchdays WHERE tournament_id = $1", tournamentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear matchdays"})
+		return
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE online_tournament_matches SET matchday = NULL WHERE tournament_id = $1", tournamentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear matchdays"})
+		return
+	}
+
+	schedule := roundRobinSchedule(playerIDs)
+	matchdays := []models.OnlineMatchday{}
+	for i, pairs := range schedule {
+		matchday := models.OnlineMatchday{
+			TournamentID: tournamentID,
+			Matchday:     i + 1,
+			Deadline:     req.FirstDeadline.AddDate(0, 0, i*intervalDays),
+		}
+
+		for _, pair := range pairs {
+			result, err := tx.ExecContext(ctx, `
+				UPDATE online_tournament_matches
+				SET matchday = $1
+				WHERE tournament_id = $2
+					AND ((player1_id = $3 AND player2_id = $4) OR (player1_id = $4 AND player2_id = $3))
+			`, matchday.Matchday, tournamentID, pair[0], pair[1])
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign matchday"})
+				return
+			}
+			n, _ := result.RowsAffected()
+			matchday.Matches += int(n)
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO online_tournament_matchdays (tournament_id, matchday, deadline)
+			VALUES ($1, $2, $3)
+		`, tournamentID, matchday.Matchday, matchday.Deadline)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create matchday"})
+			return
+		}
+		matchdays = append(matchdays, matchday)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE online_tournament_matches otm
+		SET deadline = md.deadline, reminder_sent_at = NULL
+		FROM online_tournament_matchdays md
+		WHERE md.tournament_id = otm.tournament_id AND md.matchday = otm.matchday
+			AND otm.tournament_id = $1 AND otm.deadline_overridden = false
+	`, tournamentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply matchday deadlines"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Matchdays scheduled successfully",
+		"matchdays": matchdays,
+	})
+}
+
+// GetOnlineMatchdays returns the matchdays of an online tournament with their deadlines
+func GetOnlineMatchdays(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID := c.Param("id")
+
+	query := `
+		SELECT md.tournament_id, md.matchday, md.deadline, COUNT(otm.id)
+		FROM online_tournament_matchdays md
+		LEFT JOIN online_tournament_matches otm ON otm.tournament_id = md.tournament_id AND otm.matchday = md.matchday
+		WHERE md.tournament_id = $1
+		GROUP BY md.tournament_id, md.matchday, md.deadline
+		ORDER BY md.matchday
+	`
+
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch matchdays"})
+		return
+	}
+	defer rows.Close()
+
+	matchdays := []models.OnlineMatchday{}
+	for rows.Next() {
+		var md models.OnlineMatchday
+		if err := rows.Scan(&md.TournamentID, &md.Matchday, &md.Deadline, &md.Matches); err != nil {
+			continue
+		}
+		matchdays = append(matchdays, md)
+	}
+
+	c.JSON(http.StatusOK, matchdays)
+}
+
+// SetOnlineMatchdayDeadline changes the deadline of one matchday and of its matches
+// that don't have their own deadline
+func SetOnlineMatchdayDeadline(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	matchday, err := strconv.Atoi(c.Param("matchday"))
+	if err != nil || matchday < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid matchday"})
+		return
+	}
+
+	var req models.SetMatchdayDeadlineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	if !requireOnlineTournamentOpen(c, tx, tournamentID) {
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO online_tournament_matchdays (tournament_id, matchday, deadline)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tournament_id, matchday) DO UPDATE SET deadline = EXCLUDED.deadline
+	`, tournamentID, matchday, req.Deadline)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update matchday"})
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE online_tournament_matches
+		SET deadline = $1, reminder_sent_at = NULL
+		WHERE tournament_id = $2 AND matchday = $3 AND deadline_overridden = false
+	`, req.Deadline, tournamentID, matchday)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update match deadlines"})
+		return
+	}
+	updated, _ := result.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Matchday deadline updated successfully",
+		"matchday":        matchday,
+		"deadline":        req.Deadline,
+		"matches_updated": updated,
+	})
+}
+
+// SetOnlineMatchDeadline sets the deadline of a single match. A null deadline
+// removes the override and goes back to the matchday deadline.
+func SetOnlineMatchDeadline(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID := c.Param("matchId")
+
+	var req models.SetMatchDeadlineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var query string
+	var args []interface{}
+	if req.Deadline != nil {
+		query = `
+			UPDATE online_tournament_matches
+			SET deadline = $1, deadline_overridden = true, reminder_sent_at = NULL
+			WHERE id = $2 AND completed = false
+			RETURNING id, deadline
+		`
+		args = []interface{}{*req.Deadline, matchID}
+	} else {
+		query = `
+			UPDATE online_tournament_matches otm
+			SET deadline = (
+					SELECT md.deadline FROM online_tournament_matchdays md
+					WHERE md.tournament_id = otm.tournament_id AND md.matchday = otm.matchday
+				),
+				deadline_overridden = false,
+				reminder_sent_at = NULL
+			WHERE otm.id = $1 AND otm.completed = false
+			RETURNING otm.id, otm.deadline
+		`
+		args = []interface{}{matchID}
+	}
+
+	var id int
+	var deadline *time.Time
+	err := database.DB.QueryRowContext(ctx, query, args...).Scan(&id, &deadline)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pending match not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update match deadline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Match deadline updated successfully",
+		"match_id": id,
+		"deadline": deadline,
+	})
+}
+
+// UpdateOnlineDeadlinePolicy changes what happens to matches of an online tournament after their deadline
+func UpdateOnlineDeadlinePolicy(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID := c.Param("id")
+
+	var req models.UpdateDeadlinePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := database.DB.ExecContext(ctx,
+		"UPDATE tournaments SET deadline_policy = $1 WHERE id = $2 AND type = 'ONLINE'",
+		req.Policy, tournamentID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update deadline policy"})
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found or is not an online tournament"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Deadline policy updated successfully", "deadline_policy": req.Policy})
+}
+
+// ClaimOnlineForfeit records that a player was available but their opponent didn't show up.
+// Under the forfeit deadline policy the claimant wins the match if it is still pending at the deadline.
+func ClaimOnlineForfeit(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID := c.Param("matchId")
+
+	var req models.ClaimForfeitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var player1ID, player2ID int
+	var completed bool
+	var claimedBy sql.NullInt64
+	err := database.DB.QueryRowContext(ctx, `
+		SELECT player1_id, player2_id, completed, forfeit_claimed_by
+		FROM online_tournament_matches
+		WHERE id = $1
+	`, matchID).Scan(&player1ID, &player2ID, &completed, &claimedBy)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+
+	if req.PlayerID != player1ID && req.PlayerID != player2ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Player is not part of this match"})
+		return
+	}
+	if completed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Match is already completed"})
+		return
+	}
+	if claimedBy.Valid && int(claimedBy.Int64) != req.PlayerID {
+		c.JSON(http.StatusConflict, gin.H{"error": "The opponent already claimed a forfeit for this match"})
+		return
+	}
+
+	_, err = database.DB.ExecContext(ctx,
+		"UPDATE online_tournament_matches SET forfeit_claimed_by = $1 WHERE id = $2",
+		req.PlayerID, matchID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim forfeit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Forfeit claim recorded", "match_id": matchID, "player_id": req.PlayerID})
+}
+
+// GetOnlineExpiringMatches returns pending matches whose deadline passes within the
+// next `hours` hours (default 48), soonest first
+func GetOnlineExpiringMatches(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID := c.Param("id")
+
+	hours := 48
+	if h := c.Query("hours"); h != "" {
+		parsed, err := strconv.Atoi(h)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hours parameter"})
+			return
+		}
+		hours = parsed
+	}
+
+	query := `
+		SELECT
+			id,
+			tournament_id,
+			player1_id,
+			player2_id,
+			player1_name,
+			player2_name,
+			score1,
+			score2,
+			completed,
+			result_type,
+			match_date,
+			matchday,
+			deadline,
+			forfeit_claimed_by,
+			version,
+			created_at,
+			updated_at
+		FROM online_tournament_matches
+		WHERE tournament_id = $1 AND completed = false
+			AND deadline BETWEEN CURRENT_TIMESTAMP AND CURRENT_TIMESTAMP + make_interval(hours => $2)
+		ORDER BY deadline ASC, player1_name ASC
+	`
+
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID, hours)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch expiring matches"})
+		return
+	}
+	defer rows.Close()
+
+	matches := []models.OnlineTournamentMatch{}
+	for rows.Next() {
+		var match models.OnlineTournamentMatch
+		err := rows.Scan(
+			&match.ID,
+			&match.TournamentID,
+			&match.Player1ID,
+			&match.Player2ID,
+			&match.Player1Name,
+			&match.Player2Name,
+			&match.Score1,
+			&match.Score2,
+			&match.Completed,
+			&match.ResultType,
+			&match.MatchDate,
+			&match.Matchday,
+			&match.Deadline,
+			&match.ClaimedBy,
+			&match.Version,
+			&match.CreatedAt,
+			&match.UpdatedAt,
+		)
+		if err != nil {
+			continue
+		}
+		matches = append(matches, match)
+	}
+
+	c.JSON(http.StatusOK, matches)
+}
+
+// roundRobinSchedule splits all pairings between players into matchdays using the
+// circle method; with an odd number of players one player rests each matchday
+func roundRobinSchedule(playerIDs []int) [][][2]int {
+	ids := append([]int(nil), playerIDs...)
+	sort.Ints(ids)
+	if len(ids)%2 == 1 {
+		ids = append(ids, 0) // 0 = rest
+	}
+
+	n := len(ids)
+	schedule := make([][][2]int, 0, n-1)
+	for round := 0; round < n-1; round++ {
+		pairs := [][2]int{}
+		for i := 0; i < n/2; i++ {
+			a, b := ids[i], ids[n-1-i]
+			if a != 0 && b != 0 {
+				pairs = append(pairs, [2]int{a, b})
+			}
+		}
+		schedule = append(schedule, pairs)
+
+		// Keep the first player fixed and rotate the rest
+		last := ids[n-1]
+		copy(ids[2:], ids[1:n-1])
+		ids[1] = last
+	}
+	return schedule
+}
+
+// scheduleLateEntry gives the matches of a late entrant against opponents a matchday
+// and its deadline, once the tournament has matchdays. Like ScheduleOnlineMatchdays,
+// a player has at most one match per matchday: each match goes to the first matchday
+// still open in which neither player has a match, or to new matchdays after the last
+// one, spaced like the last two (interval_days by default).
+func scheduleLateEntry(ctx context.Context, tx *sql.Tx, tournamentID, playerID int, opponents []int) error {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT matchday, deadline FROM online_tournament_matchdays WHERE tournament_id = $1 ORDER BY matchday",
+		tournamentID,
+	)
+	if err != nil {
+		return err
+	}
+	deadlines := make(map[int]time.Time)
+	var numbers []int
+	for rows.Next() {
+		var matchday int
+		var deadline time.Time
+		if err := rows.Scan(&matchday, &deadline); err != nil {
+			rows.Close()
+			return err
+		}
+		deadlines[matchday] = deadline
+		numbers = append(numbers, matchday)
+	}
+	rows.Close()
+	if len(numbers) == 0 {
+		return nil
+	}
+
+	busy := make(map[int]map[int]bool)
+	rows, err = tx.QueryContext(ctx, `
+		SELECT matchday, player1_id, player2_id FROM online_tournament_matches
+		WHERE tournament_id = $1 AND matchday IS NOT NULL
+	`, tournamentID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var matchday, player1ID, player2ID int
+		if err := rows.Scan(&matchday, &player1ID, &player2ID); err != nil {
+			rows.Close()
+			return err
+		}
+		if busy[matchday] == nil {
+			busy[matchday] = make(map[int]bool)
+		}
+		busy[matchday][player1ID] = true
+		busy[matchday][player2ID] = true
+	}
+	rows.Close()
+
+	now := time.Now()
+	var open []int
+	for _, matchday := range numbers {
+		if deadlines[matchday].After(now) {
+			open = append(open, matchday)
+		}
+	}
+
+	last := numbers[len(numbers)-1]
+	interval := time.Duration(defaultMatchdayIntervalDays) * 24 * time.Hour
+	if len(numbers) > 1 {
+		interval = deadlines[last].Sub(deadlines[numbers[len(numbers)-2]])
+	}
+
+	for opponentID, matchday := range placeLateEntry(open, busy, last, playerID, opponents) {
+		deadline, ok := deadlines[matchday]
+		if !ok {
+			deadline = deadlines[last].Add(time.Duration(matchday-last) * interval)
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO online_tournament_matchdays (tournament_id, matchday, deadline)
+				VALUES ($1, $2, $3)
+			`, tournamentID, matchday, deadline)
+			if err != nil {
+				return err
+			}
+			deadlines[matchday] = deadline
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE online_tournament_matches
+			SET matchday = $1, deadline = $2, reminder_sent_at = NULL
+			WHERE tournament_id = $3 AND deadline_overridden = false
+				AND ((player1_id = $4 AND player2_id = $5) OR (player1_id = $5 AND player2_id = $4))
+		`, matchday, deadline, tournamentID, playerID, opponentID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placeLateEntry picks the matchday of each match of a late entrant, by opponent: the
+// first of the open matchdays in which neither player is busy, otherwise a new
+// matchday after last. busy holds the players with a match in each matchday.
+func placeLateEntry(open []int, busy map[int]map[int]bool, last, playerID int, opponents []int) map[int]int {
+	taken := make(map[int]bool) // matchdays the entrant already plays
+	for matchday, players := range busy {
+		if players[playerID] {
+			taken[matchday] = true
+		}
+	}
+
+	placed := make(map[int]int, len(opponents))
+	for _, opponentID := range opponents {
+		matchday := 0
+		for _, md := range open {
+			if !taken[md] && !busy[md][opponentID] {
+				matchday = md
+				break
+			}
+		}
+		if matchday == 0 {
+			last++
+			matchday = last
+		}
+		taken[matchday] = true
+		if busy[matchday] == nil {
+			busy[matchday] = make(map[int]bool)
+		}
+		busy[matchday][opponentID] = true
+		placed[opponentID] = matchday
+	}
+	return placed
+}
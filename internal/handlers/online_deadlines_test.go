@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlaceLateEntry(t *testing.T) {
+	// Players 1 to 3 over three matchdays, each resting once; matchday 1 is over
+	busy := func() map[int]map[int]bool {
+		return map[int]map[int]bool{
+			1: {1: true, 2: true},
+			2: {1: true, 3: true},
+			3: {2: true, 3: true},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		open      []int
+		opponents []int
+		want      map[int]int
+	}{
+		{"rest matchdays first", []int{2, 3}, []int{1, 2, 3}, map[int]int{1: 3, 2: 2, 3: 4}},
+		{"all past", nil, []int{1, 2}, map[int]int{1: 4, 2: 5}},
+		{"no opponents", []int{2, 3}, nil, map[int]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := placeLateEntry(tt.open, busy(), 3, 9, tt.opponents)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("placeLateEntry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceLateEntryOneMatchPerMatchday(t *testing.T) {
+	got := placeLateEntry([]int{1, 2}, map[int]map[int]bool{}, 2, 9, []int{1, 2, 3, 4})
+	seen := make(map[int]bool)
+	for opponent, matchday := range got {
+		if seen[matchday] {
+			t.Errorf("matchday %d holds two matches of the entrant (opponent %d)", matchday, opponent)
+		}
+		seen[matchday] = true
+	}
+	if len(got) != 4 {
+		t.Errorf("placed %d matches, want 4", len(got))
+	}
+}
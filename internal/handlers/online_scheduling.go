@@ -0,0 +1,509 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// calendarMatchDuration is the length of a match event in the calendar feed
+const calendarMatchDuration = time.Hour
+
+// CreateMatchProposal lets one of the players of a pending online match propose time slots.
+// Earlier pending proposals of the same player for the match are superseded.
+func CreateMatchProposal(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID, err := strconv.Atoi(c.Param("matchId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+
+	var req models.CreateMatchProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var player1ID, player2ID int
+	var completed bool
+	var deadline *time.Time
+	var tournamentStatus string
+	err = tx.QueryRowContext(ctx, `
+		SELECT otm.player1_id, otm.player2_id, otm.completed, otm.deadline, t.status
+		FROM online_tournament_matches otm
+		JOIN tournaments t ON t.id = otm.tournament_id
+		WHERE otm.id = $1
+		FOR UPDATE OF otm
+	`, matchID).Scan(&player1ID, &player2ID, &completed, &deadline, &tournamentStatus)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+
+	if req.PlayerID != player1ID && req.PlayerID != player2ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Player is not part of this match"})
+		return
+	}
+	if completed || tournamentStatus != models.TournamentStatusInProgress {
+		c.JSON(http.StatusConflict, gin.H{"error": "Match can no longer be scheduled"})
+		return
+	}
+
+	now := time.Now()
+	for _, slot := range req.Slots {
+		if !slot.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Slot %s is in the past", slot.Format(time.RFC3339))})
+			return
+		}
+		if deadline != nil && slot.After(*deadline) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Slot %s is after the match deadline %s", slot.Format(time.RFC3339), deadline.Format(time.RFC3339))})
+			return
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE online_match_proposals
+		SET status = 'superseded'
+		WHERE match_id = $1 AND proposed_by = $2 AND status = 'pending'
+	`, matchID, req.PlayerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to supersede previous proposals"})
+		return
+	}
+
+	proposal := models.MatchProposal{
+		MatchID:    matchID,
+		ProposedBy: req.PlayerID,
+		Status:     models.ProposalStatusPending,
+		Message:    req.Message,
+		Slots:      []models.MatchProposalSlot{},
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO online_match_proposals (match_id, proposed_by, message)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, matchID, req.PlayerID, req.Message).Scan(&proposal.ID, &proposal.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create proposal"})
+		return
+	}
+
+	for _, startsAt := range req.Slots {
+		slot := models.MatchProposalSlot{StartsAt: startsAt}
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO online_match_proposal_slots (proposal_id, starts_at)
+			VALUES ($1, $2)
+			ON CONFLICT (proposal_id, starts_at) DO NOTHING
+			RETURNING id
+		`, proposal.ID, startsAt).Scan(&slot.ID)
+		if err == sql.ErrNoRows {
+			continue // duplicate slot
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create proposal slot"})
+			return
+		}
+		proposal.Slots = append(proposal.Slots, slot)
+	}
+
+	if err := tx.QueryRowContext(ctx, "SELECT name FROM premier_players WHERE id = $1", req.PlayerID).Scan(&proposal.ProposedByName); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, proposal)
+}
+
+// GetMatchProposals returns all scheduling proposals of an online match, newest first
+func GetMatchProposals(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID := c.Param("matchId")
+
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT p.id, p.match_id, p.proposed_by, pp.name, p.status, p.message, p.accepted_slot_id, p.responded_at, p.created_at
+		FROM online_match_proposals p
+		JOIN premier_players pp ON pp.id = p.proposed_by
+		WHERE p.match_id = $1
+		ORDER BY p.created_at DESC
+	`, matchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch proposals"})
+		return
+	}
+	defer rows.Close()
+
+	proposals := []models.MatchProposal{}
+	index := make(map[int]int)
+	for rows.Next() {
+		var p models.MatchProposal
+		err := rows.Scan(&p.ID, &p.MatchID, &p.ProposedBy, &p.ProposedByName, &p.Status, &p.Message, &p.AcceptedSlotID, &p.RespondedAt, &p.CreatedAt)
+		if err != nil {
+			continue
+		}
+		p.Slots = []models.MatchProposalSlot{}
+		index[p.ID] = len(proposals)
+		proposals = append(proposals, p)
+	}
+
+	slotRows, err := database.DB.QueryContext(ctx, `
+		SELECT s.id, s.proposal_id, s.starts_at
+		FROM online_match_proposal_slots s
+		JOIN online_match_proposals p ON p.id = s.proposal_id
+		WHERE p.match_id = $1
+		ORDER BY s.starts_at
+	`, matchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch proposal slots"})
+		return
+	}
+	defer slotRows.Close()
+
+	for slotRows.Next() {
+		var slot models.MatchProposalSlot
+		var proposalID int
+		if err := slotRows.Scan(&slot.ID, &proposalID, &slot.StartsAt); err != nil {
+			continue
+		}
+		if i, ok := index[proposalID]; ok {
+			proposals[i].Slots = append(proposals[i].Slots, slot)
+		}
+	}
+
+	c.JSON(http.StatusOK, proposals)
+}
+
+// AcceptMatchProposal lets the opponent accept one slot of a proposal. The slot becomes
+// the match_date of the match and every other pending proposal of the match is superseded.
+func AcceptMatchProposal(c *gin.Context) {
+	ctx := c.Request.Context()
+	proposalID, err := strconv.Atoi(c.Param("proposalId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid proposal ID"})
+		return
+	}
+
+	var req models.AcceptMatchProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	proposal, ok := loadProposalForResponse(c, tx, proposalID)
+	if !ok {
+		return
+	}
+	if req.PlayerID == proposal.proposedBy || (req.PlayerID != proposal.player1ID && req.PlayerID != proposal.player2ID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the opponent can accept this proposal"})
+		return
+	}
+
+	var startsAt time.Time
+	err = tx.QueryRowContext(ctx,
+		"SELECT starts_at FROM online_match_proposal_slots WHERE id = $1 AND proposal_id = $2",
+		req.SlotID, proposalID,
+	).Scan(&startsAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slot does not belong to this proposal"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch slot"})
+		return
+	}
+	if !startsAt.After(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Slot is already in the past"})
+		return
+	}
+	// The deadline may have moved since the slot was proposed
+	if proposal.deadline != nil && startsAt.After(*proposal.deadline) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Slot is after the match deadline %s", proposal.deadline.Format(time.RFC3339))})
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE online_match_proposals
+		SET status = 'accepted', accepted_slot_id = $1, responded_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, req.SlotID, proposalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept proposal"})
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE online_match_proposals
+		SET status = 'superseded'
+		WHERE match_id = $1 AND id != $2 AND status = 'pending'
+	`, proposal.matchID, proposalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to supersede other proposals"})
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE online_tournament_matches SET match_date = $1 WHERE id = $2", startsAt, proposal.matchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule match"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Proposal accepted, match scheduled",
+		"match_id":   proposal.matchID,
+		"match_date": startsAt,
+	})
+}
+
+// DeclineMatchProposal lets the opponent decline a pending proposal
+func DeclineMatchProposal(c *gin.Context) {
+	closeMatchProposal(c, models.ProposalStatusDeclined)
+}
+
+// WithdrawMatchProposal lets the proposer withdraw a pending proposal
+func WithdrawMatchProposal(c *gin.Context) {
+	closeMatchProposal(c, models.ProposalStatusWithdrawn)
+}
+
+func closeMatchProposal(c *gin.Context, status string) {
+	ctx := c.Request.Context()
+	proposalID, err := strconv.Atoi(c.Param("proposalId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid proposal ID"})
+		return
+	}
+
+	var req models.RespondMatchProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	proposal, ok := loadProposalForResponse(c, tx, proposalID)
+	if !ok {
+		return
+	}
+
+	isProposer := req.PlayerID == proposal.proposedBy
+	isOpponent := !isProposer && (req.PlayerID == proposal.player1ID || req.PlayerID == proposal.player2ID)
+	if (status == models.ProposalStatusWithdrawn && !isProposer) || (status == models.ProposalStatusDeclined && !isOpponent) {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Player cannot mark this proposal as %s", status)})
+		return
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE online_match_proposals SET status = $1, responded_at = CURRENT_TIMESTAMP WHERE id = $2",
+		status, proposalID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update proposal"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Proposal " + status, "proposal_id": proposalID, "status": status})
+}
+
+type proposalForResponse struct {
+	matchID    int
+	proposedBy int
+	player1ID  int
+	player2ID  int
+	deadline   *time.Time
+}
+
+// loadProposalForResponse locks a pending proposal of a match that can still be scheduled
+// (pending, in a tournament in progress), writing the error response and returning
+// false otherwise
+func loadProposalForResponse(c *gin.Context, tx *sql.Tx, proposalID int) (proposalForResponse, bool) {
+	ctx := c.Request.Context()
+	var p proposalForResponse
+	var status, tournamentStatus string
+	var completed bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT p.match_id, p.proposed_by, p.status, otm.player1_id, otm.player2_id, otm.completed,
+			otm.deadline, t.status
+		FROM online_match_proposals p
+		JOIN online_tournament_matches otm ON otm.id = p.match_id
+		JOIN tournaments t ON t.id = otm.tournament_id
+		WHERE p.id = $1
+		FOR UPDATE OF p, otm
+	`, proposalID).Scan(&p.matchID, &p.proposedBy, &status, &p.player1ID, &p.player2ID, &completed,
+		&p.deadline, &tournamentStatus)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposal not found"})
+		return p, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch proposal"})
+		return p, false
+	}
+	if status != models.ProposalStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Proposal is already %s", status)})
+		return p, false
+	}
+	if completed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Match is already completed"})
+		return p, false
+	}
+	if tournamentStatus != models.TournamentStatusInProgress {
+		c.JSON(http.StatusConflict, gin.H{"error": "Match can no longer be scheduled"})
+		return p, false
+	}
+	return p, true
+}
+
+// GetPlayerCalendar returns an iCalendar feed with the upcoming scheduled online matches of a premier player
+func GetPlayerCalendar(c *gin.Context) {
+	ctx := c.Request.Context()
+	playerID, err := strconv.Atoi(c.Param("player_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	var playerName string
+	err = database.DB.QueryRowContext(ctx, "SELECT name FROM premier_players WHERE id = $1", playerID).Scan(&playerName)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player"})
+		return
+	}
+
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT otm.id, otm.player1_name, otm.player2_name, otm.match_date, otm.deadline, otm.updated_at, t.name, t.format
+		FROM online_tournament_matches otm
+		JOIN tournaments t ON t.id = otm.tournament_id
+		WHERE (otm.player1_id = $1 OR otm.player2_id = $1)
+			AND otm.completed = false
+			AND otm.match_date IS NOT NULL
+			AND otm.match_date >= CURRENT_TIMESTAMP - INTERVAL '1 day'
+			AND t.status = 'in_progress'
+		ORDER BY otm.match_date
+	`, playerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled matches"})
+		return
+	}
+	defer rows.Close()
+
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//Premier Mitologico//Online Matches//ES")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:"+icsEscape("Premier - "+playerName))
+
+	now := time.Now().UTC()
+	for rows.Next() {
+		var matchID int
+		var player1Name, player2Name, tournamentName string
+		var matchDate time.Time
+		var deadline *time.Time
+		var updatedAt time.Time
+		var format sql.NullString
+		if err := rows.Scan(&matchID, &player1Name, &player2Name, &matchDate, &deadline, &updatedAt, &tournamentName, &format); err != nil {
+			continue
+		}
+
+		description := tournamentName
+		if format.Valid {
+			description += " (" + format.String + ")"
+		}
+		if deadline != nil {
+			description += "\nDeadline: " + deadline.UTC().Format(time.RFC3339)
+		}
+
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, fmt.Sprintf("UID:online-match-%d@premier-mitologico", matchID))
+		writeICSLine(&b, "DTSTAMP:"+icsTime(now))
+		writeICSLine(&b, "LAST-MODIFIED:"+icsTime(updatedAt))
+		writeICSLine(&b, "DTSTART:"+icsTime(matchDate))
+		writeICSLine(&b, "DTEND:"+icsTime(matchDate.Add(calendarMatchDuration)))
+		writeICSLine(&b, "SUMMARY:"+icsEscape(fmt.Sprintf("%s vs %s", player1Name, player2Name)))
+		writeICSLine(&b, "DESCRIPTION:"+icsEscape(description))
+		writeICSLine(&b, "END:VEVENT")
+	}
+	writeICSLine(&b, "END:VCALENDAR")
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"player-%d.ics\"", playerID))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(b.String()))
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsEscape escapes text values as required by RFC 5545
+func icsEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeICSLine writes a content line folded at 75 octets with CRLF endings (RFC 5545).
+// Continuation lines start with a space, so they carry 74 octets of the line.
+func writeICSLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Don't split a multi-byte UTF-8 character
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// TestScheduledTimesKeepTheirOffset proposes a slot with a non-UTC offset, accepts it
+// the way AcceptMatchProposal does and checks that the match time is the same
+// instant. It needs TEST_DATABASE_URL, a migrated database; everything is rolled back.
+func TestScheduledTimesKeepTheirOffset(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	// A session zone unlike the offset sent, so nothing lines up by accident
+	if _, err := tx.ExecContext(ctx, "SET LOCAL TIME ZONE 'Asia/Tokyo'"); err != nil {
+		t.Fatal(err)
+	}
+
+	var tournamentID, player1ID, player2ID, matchID, proposalID, slotID int
+	inserts := []struct {
+		query string
+		id    *int
+	}{
+		{"INSERT INTO tournaments (name, month, year, type, status) VALUES ('Liga de prueba', 'Noviembre', 2026, 'ONLINE', 'in_progress') RETURNING id", &tournamentID},
+		{"INSERT INTO premier_players (name) VALUES ('Prueba Ana') RETURNING id", &player1ID},
+		{"INSERT INTO premier_players (name) VALUES ('Prueba Bruno') RETURNING id", &player2ID},
+	}
+	for _, insert := range inserts {
+		if err := tx.QueryRowContext(ctx, insert.query).Scan(insert.id); err != nil {
+			t.Fatalf("%s: %v", insert.query, err)
+		}
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO online_tournament_matches (tournament_id, player1_id, player2_id, player1_name, player2_name)
+		VALUES ($1, $2, $3, 'Prueba Ana', 'Prueba Bruno') RETURNING id
+	`, tournamentID, player1ID, player2ID).Scan(&matchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO online_match_proposals (match_id, proposed_by) VALUES ($1, $2) RETURNING id",
+		matchID, player1ID,
+	).Scan(&proposalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proposed := time.Date(2026, 11, 2, 20, 0, 0, 0, time.FixedZone("-03", -3*60*60))
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO online_match_proposal_slots (proposal_id, starts_at) VALUES ($1, $2) RETURNING id",
+		proposalID, proposed,
+	).Scan(&slotID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var startsAt time.Time
+	if err := tx.QueryRowContext(ctx, "SELECT starts_at FROM online_match_proposal_slots WHERE id = $1", slotID).Scan(&startsAt); err != nil {
+		t.Fatal(err)
+	}
+	if !startsAt.Equal(proposed) {
+		t.Fatalf("slot starts at %s, want %s", startsAt, proposed)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE online_tournament_matches SET match_date = $1 WHERE id = $2", startsAt, matchID); err != nil {
+		t.Fatal(err)
+	}
+	var matchDate time.Time
+	if err := tx.QueryRowContext(ctx, "SELECT match_date FROM online_tournament_matches WHERE id = $1", matchID).Scan(&matchDate); err != nil {
+		t.Fatal(err)
+	}
+	if !matchDate.Equal(proposed) {
+		t.Errorf("match_date %s, want %s", matchDate, proposed)
+	}
+	if got, want := icsTime(matchDate), "20261102T230000Z"; got != want {
+		t.Errorf("DTSTART %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWriteICSLineFoldsAt75Octets(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Ana vs Bruno"},
+		{"exactly 75", "DESCRIPTION:" + strings.Repeat("a", 63)},
+		{"ascii", "DESCRIPTION:" + strings.Repeat("a", 300)},
+		{"multi-byte", "DESCRIPTION:" + strings.Repeat("Iñaki vs Ñuñoa, ", 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeICSLine(&b, tt.line)
+			out := b.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("%q does not end with CRLF", out)
+			}
+
+			var unfolded strings.Builder
+			for i, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+				if len(l) > 75 {
+					t.Errorf("line %d is %d octets: %q", i, len(l), l)
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d splits a character: %q", i, l)
+				}
+				if i > 0 {
+					if !strings.HasPrefix(l, " ") {
+						t.Errorf("continuation line %d does not start with a space: %q", i, l)
+					}
+					l = l[1:]
+				}
+				unfolded.WriteString(l)
+			}
+			if unfolded.String() != tt.line {
+				t.Errorf("unfolded to %q, want %q", unfolded.String(), tt.line)
+			}
+		})
+	}
+}
@@ -4,19 +4,28 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/andreuvv/premier_mitologico/backend/internal/database"
 	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/playerstats"
+	"github.com/andreuvv/premier_mitologico/backend/internal/tournamentio"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
 	"github.com/gin-gonic/gin"
 )
 
 // CreateOnlineTournament creates a new online tournament with auto-generated match pairings
 func CreateOnlineTournament(c *gin.Context) {
+	ctx := c.Request.Context()
 	var req models.CreateOnlineTournamentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !tournamentio.ValidMonth(req.Month) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown month '" + req.Month + "'; use a month name or number"})
+		return
+	}
 
 	// Validate at least 2 players
 	if len(req.PlayerIDs) < 2 {
@@ -24,7 +33,12 @@ func CreateOnlineTournament(c *gin.Context) {
 		return
 	}
 
-	tx, err := database.DB.Begin()
+	deadlinePolicy := req.DeadlinePolicy
+	if deadlinePolicy == "" {
+		deadlinePolicy = models.DeadlinePolicyDoubleLoss
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
 		return
@@ -33,11 +47,11 @@ func CreateOnlineTournament(c *gin.Context) {
 
 	// Create tournament record
 	var tournamentID int
-	err = tx.QueryRow(`
-		INSERT INTO tournaments (name, month, year, type, format, start_date, end_date, created_at, archived_at)
-		VALUES ($1, $2, $3, 'ONLINE', $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tournaments (name, month, year, type, format, start_date, end_date, status, deadline_policy, created_at)
+		VALUES ($1, $2, $3, 'ONLINE', $4, $5, $6, 'in_progress', $7, CURRENT_TIMESTAMP)
 		RETURNING id
-	`, req.Name, req.Month, req.Year, req.Format, req.StartDate, req.EndDate).Scan(&tournamentID)
+	`, req.Name, req.Month, req.Year, req.Format, req.StartDate, req.EndDate, deadlinePolicy).Scan(&tournamentID)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tournament"})
@@ -48,7 +62,7 @@ func CreateOnlineTournament(c *gin.Context) {
 	playerMap := make(map[int]string)
 	for _, playerID := range req.PlayerIDs {
 		var name string
-		err := tx.QueryRow("SELECT name FROM premier_players WHERE id = $1", playerID).Scan(&name)
+		err := tx.QueryRowContext(ctx, "SELECT name FROM premier_players WHERE id = $1", playerID).Scan(&name)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Player with ID %d not found in premier players", playerID)})
 			return
@@ -58,7 +72,7 @@ func CreateOnlineTournament(c *gin.Context) {
 
 	// Insert tournament players
 	for _, playerID := range req.PlayerIDs {
-		_, err := tx.Exec(`
+		_, err := tx.ExecContext(ctx, `
 			INSERT INTO online_tournament_players (tournament_id, player_id, player_name)
 			VALUES ($1, $2, $3)
 		`, tournamentID, playerID, playerMap[playerID])
@@ -79,7 +93,7 @@ func CreateOnlineTournament(c *gin.Context) {
 			player1Name := playerMap[player1ID]
 			player2Name := playerMap[player2ID]
 
-			_, err := tx.Exec(`
+			_, err := tx.ExecContext(ctx, `
 				INSERT INTO online_tournament_matches 
 				(tournament_id, player1_id, player2_id, player1_name, player2_name, completed)
 				VALUES ($1, $2, $3, $4, $5, false)
@@ -110,6 +124,7 @@ func CreateOnlineTournament(c *gin.Context) {
 
 // GetOnlineTournamentMatches returns all matches for an online tournament
 func GetOnlineTournamentMatches(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
 	query := `
@@ -123,7 +138,12 @@ func GetOnlineTournamentMatches(c *gin.Context) {
 			score1,
 			score2,
 			completed,
+			result_type,
 			match_date,
+			matchday,
+			deadline,
+			forfeit_claimed_by,
+			version,
 			created_at,
 			updated_at
 		FROM online_tournament_matches
@@ -134,7 +154,7 @@ func GetOnlineTournamentMatches(c *gin.Context) {
 			player2_name ASC
 	`
 
-	rows, err := database.DB.Query(query, tournamentID)
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch matches"})
 		return
@@ -154,7 +174,12 @@ func GetOnlineTournamentMatches(c *gin.Context) {
 			&match.Score1,
 			&match.Score2,
 			&match.Completed,
+			&match.ResultType,
 			&match.MatchDate,
+			&match.Matchday,
+			&match.Deadline,
+			&match.ClaimedBy,
+			&match.Version,
 			&match.CreatedAt,
 			&match.UpdatedAt,
 		)
@@ -169,6 +194,7 @@ func GetOnlineTournamentMatches(c *gin.Context) {
 
 // GetOnlineTournamentStandings returns standings for an online tournament
 func GetOnlineTournamentStandings(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
 	query := `
@@ -176,6 +202,7 @@ func GetOnlineTournamentStandings(c *gin.Context) {
 			tournament_id,
 			player_id,
 			player_name,
+			status,
 			matches_played,
 			wins,
 			ties,
@@ -183,10 +210,10 @@ func GetOnlineTournamentStandings(c *gin.Context) {
 			points
 		FROM online_tournament_standings
 		WHERE tournament_id = $1
-		ORDER BY points DESC, wins DESC
+		ORDER BY CASE WHEN status = 'disqualified' THEN 1 ELSE 0 END, points DESC, wins DESC
 	`
 
-	rows, err := database.DB.Query(query, tournamentID)
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch standings"})
 		return
@@ -200,6 +227,7 @@ func GetOnlineTournamentStandings(c *gin.Context) {
 			&standing.TournamentID,
 			&standing.PlayerID,
 			&standing.PlayerName,
+			&standing.Status,
 			&standing.MatchesPlayed,
 			&standing.Wins,
 			&standing.Ties,
@@ -217,23 +245,69 @@ func GetOnlineTournamentStandings(c *gin.Context) {
 
 // UpdateOnlineMatchScore updates the score for a match in an online tournament
 func UpdateOnlineMatchScore(c *gin.Context) {
-	matchID := c.Param("matchId")
-	var req models.UpdateOnlineMatchScoreRequest
+	ctx := c.Request.Context()
+	matchID, err := strconv.Atoi(c.Param("matchId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
 
+	var req models.UpdateOnlineMatchScoreRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Scores can only be reported while the tournament is being played. The match is
+	// locked so concurrent updates are checked one after the other.
+	var status string
+	var version int
+	err = tx.QueryRowContext(ctx, `
+		SELECT t.status, otm.version
+		FROM online_tournament_matches otm
+		JOIN tournaments t ON t.id = otm.tournament_id
+		WHERE otm.id = $1
+		FOR UPDATE OF otm
+	`, matchID).Scan(&status, &version)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+	if status != models.TournamentStatusInProgress {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot update scores of a tournament in status '%s'", status)})
+		return
+	}
+	if !ifMatch(c, version) {
+		tx.Rollback()
+		current, err := fetchOnlineMatch(ctx, database.DB, matchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+			return
+		}
+		respondVersionConflict(c, current.Version, current)
+		return
+	}
+
 	query := `
 		UPDATE online_tournament_matches
-		SET score1 = $1, score2 = $2, completed = true, updated_at = CURRENT_TIMESTAMP
+		SET score1 = $1, score2 = $2, completed = true, result_type = 'played', updated_at = CURRENT_TIMESTAMP
 		WHERE id = $3
-		RETURNING id, tournament_id, player1_name, player2_name, score1, score2, completed
+		RETURNING id, tournament_id, player1_name, player2_name, score1, score2, completed, version
 	`
 
 	var match models.OnlineTournamentMatch
-	err := database.DB.QueryRow(query, req.Score1, req.Score2, matchID).Scan(
+	err = tx.QueryRowContext(ctx, query, req.Score1, req.Score2, matchID).Scan(
 		&match.ID,
 		&match.TournamentID,
 		&match.Player1Name,
@@ -241,26 +315,51 @@ func UpdateOnlineMatchScore(c *gin.Context) {
 		&match.Score1,
 		&match.Score2,
 		&match.Completed,
+		&match.Version,
 	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update match"})
+		return
+	}
 
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+	if err := playerstats.RefreshTournament(ctx, tx, match.TournamentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player statistics: " + err.Error()})
 		return
 	}
+
+	completed, err := fetchOnlineMatch(ctx, tx, matchID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update match"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+	event := webhooks.MatchData{Source: models.MatchSourceOnline, Match: completed}
+	if err := webhooks.Enqueue(ctx, tx, webhooks.EventMatchCompleted, event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	scoreUpdates.Inc(models.MatchSourceOnline)
+
+	statsChanged()
+
+	webhooks.Emit(webhooks.EventMatchCompleted, event)
+
+	c.Header("ETag", versionETag(match.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Match score updated successfully",
 		"match_id": match.ID,
 		"score":    fmt.Sprintf("%s %d-%d %s", match.Player1Name, *match.Score1, *match.Score2, match.Player2Name),
+		"version":  match.Version,
 	})
 }
 
 // GetOnlinePendingMatches returns only pending matches (not completed) for an online tournament
 func GetOnlinePendingMatches(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
 	query := `
@@ -274,7 +373,12 @@ func GetOnlinePendingMatches(c *gin.Context) {
 			score1,
 			score2,
 			completed,
+			result_type,
 			match_date,
+			matchday,
+			deadline,
+			forfeit_claimed_by,
+			version,
 			created_at,
 			updated_at
 		FROM online_tournament_matches
@@ -282,7 +386,7 @@ func GetOnlinePendingMatches(c *gin.Context) {
 		ORDER BY player1_name ASC, player2_name ASC
 	`
 
-	rows, err := database.DB.Query(query, tournamentID)
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending matches"})
 		return
@@ -302,7 +406,12 @@ func GetOnlinePendingMatches(c *gin.Context) {
 			&match.Score1,
 			&match.Score2,
 			&match.Completed,
+			&match.ResultType,
 			&match.MatchDate,
+			&match.Matchday,
+			&match.Deadline,
+			&match.ClaimedBy,
+			&match.Version,
 			&match.CreatedAt,
 			&match.UpdatedAt,
 		)
@@ -317,6 +426,7 @@ func GetOnlinePendingMatches(c *gin.Context) {
 
 // GetOnlineCompletedMatches returns only completed matches for an online tournament
 func GetOnlineCompletedMatches(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
 	query := `
@@ -330,7 +440,12 @@ func GetOnlineCompletedMatches(c *gin.Context) {
 			score1,
 			score2,
 			completed,
+			result_type,
 			match_date,
+			matchday,
+			deadline,
+			forfeit_claimed_by,
+			version,
 			created_at,
 			updated_at
 		FROM online_tournament_matches
@@ -338,7 +453,7 @@ func GetOnlineCompletedMatches(c *gin.Context) {
 		ORDER BY updated_at DESC, player1_name ASC, player2_name ASC
 	`
 
-	rows, err := database.DB.Query(query, tournamentID)
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch completed matches"})
 		return
@@ -358,7 +473,12 @@ func GetOnlineCompletedMatches(c *gin.Context) {
 			&match.Score1,
 			&match.Score2,
 			&match.Completed,
+			&match.ResultType,
 			&match.MatchDate,
+			&match.Matchday,
+			&match.Deadline,
+			&match.ClaimedBy,
+			&match.Version,
 			&match.CreatedAt,
 			&match.UpdatedAt,
 		)
@@ -373,9 +493,10 @@ func GetOnlineCompletedMatches(c *gin.Context) {
 
 // DeleteOnlineTournament deletes an online tournament and all its data
 func DeleteOnlineTournament(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
-	tx, err := database.DB.Begin()
+	tx, err := database.DB.BeginTx(ctx, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
 		return
@@ -383,21 +504,21 @@ func DeleteOnlineTournament(c *gin.Context) {
 	defer tx.Rollback()
 
 	// Delete tournament matches
-	_, err = tx.Exec("DELETE FROM online_tournament_matches WHERE tournament_id = $1", tournamentID)
+	_, err = tx.ExecContext(ctx, "DELETE FROM online_tournament_matches WHERE tournament_id = $1", tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete matches"})
 		return
 	}
 
 	// Delete tournament players
-	_, err = tx.Exec("DELETE FROM online_tournament_players WHERE tournament_id = $1", tournamentID)
+	_, err = tx.ExecContext(ctx, "DELETE FROM online_tournament_players WHERE tournament_id = $1", tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete players"})
 		return
 	}
 
 	// Delete tournament record
-	result, err := tx.Exec("DELETE FROM tournaments WHERE id = $1 AND type = 'ONLINE'", tournamentID)
+	result, err := tx.ExecContext(ctx, "DELETE FROM tournaments WHERE id = $1 AND type = 'ONLINE'", tournamentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tournament"})
 		return
@@ -419,6 +540,7 @@ func DeleteOnlineTournament(c *gin.Context) {
 
 // GetOnlineTournamentInfo returns tournament info (metadata)
 func GetOnlineTournamentInfo(c *gin.Context) {
+	ctx := c.Request.Context()
 	tournamentID := c.Param("id")
 
 	query := `
@@ -429,9 +551,12 @@ func GetOnlineTournamentInfo(c *gin.Context) {
 			year,
 			type,
 			format,
+			status,
+			deadline_policy,
 			start_date,
 			end_date,
 			created_at,
+			completed_at,
 			archived_at
 		FROM tournaments
 		WHERE id = $1 AND type = 'ONLINE'
@@ -439,17 +564,20 @@ func GetOnlineTournamentInfo(c *gin.Context) {
 
 	var tournament models.Tournament
 	var format sql.NullString
-	var tournamentType string
-	err := database.DB.QueryRow(query, tournamentID).Scan(
+	var tournamentType, deadlinePolicy string
+	err := database.DB.QueryRowContext(ctx, query, tournamentID).Scan(
 		&tournament.ID,
 		&tournament.Name,
 		&tournament.Month,
 		&tournament.Year,
 		&tournamentType,
 		&format,
+		&tournament.Status,
+		&deadlinePolicy,
 		&tournament.StartDate,
 		&tournament.EndDate,
 		&tournament.CreatedAt,
+		&tournament.CompletedAt,
 		&tournament.ArchivedAt,
 	)
 
@@ -463,20 +591,26 @@ func GetOnlineTournamentInfo(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":         tournament.ID,
-		"name":       tournament.Name,
-		"month":      tournament.Month,
-		"year":       tournament.Year,
-		"format":     format.String,
-		"type":       tournamentType,
-		"start_date": tournament.StartDate,
-		"end_date":   tournament.EndDate,
-		"created_at": tournament.CreatedAt,
+		"id":              tournament.ID,
+		"name":            tournament.Name,
+		"month":           tournament.Month,
+		"year":            tournament.Year,
+		"format":          format.String,
+		"type":            tournamentType,
+		"status":          tournament.Status,
+		"deadline_policy": deadlinePolicy,
+		"start_date":      tournament.StartDate,
+		"end_date":        tournament.EndDate,
+		"created_at":      tournament.CreatedAt,
+		"completed_at":    tournament.CompletedAt,
+		"archived_at":     tournament.ArchivedAt,
 	})
 }
 
-// GetAllActiveTournaments returns all active tournaments (in-person and online)
+// GetAllActiveTournaments returns all active tournaments (in-person and online):
+// those open for registration or being played
 func GetAllActiveTournaments(c *gin.Context) {
+	ctx := c.Request.Context()
 	query := `
 		SELECT 
 			id,
@@ -485,15 +619,16 @@ func GetAllActiveTournaments(c *gin.Context) {
 			year,
 			type,
 			format,
+			status,
 			start_date,
 			end_date,
 			created_at
 		FROM tournaments
-		WHERE archived_at IS NOT NULL OR archived_at = CURRENT_TIMESTAMP
+		WHERE status IN ('registration', 'in_progress')
 		ORDER BY created_at DESC
 	`
 
-	rows, err := database.DB.Query(query)
+	rows, err := database.DB.QueryContext(ctx, query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournaments"})
 		return
@@ -507,6 +642,7 @@ func GetAllActiveTournaments(c *gin.Context) {
 		Year      int     `json:"year"`
 		Type      string  `json:"type"`
 		Format    *string `json:"format"`
+		Status    string  `json:"status"`
 		StartDate *string `json:"start_date"`
 		EndDate   *string `json:"end_date"`
 		CreatedAt string  `json:"created_at"`
@@ -523,6 +659,7 @@ func GetAllActiveTournaments(c *gin.Context) {
 			&t.Year,
 			&t.Type,
 			&format,
+			&t.Status,
 			&t.StartDate,
 			&t.EndDate,
 			&t.CreatedAt,
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/apiv2"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/openapi"
+)
+
+// Common parameters
+var (
+	ifMatchHeader = openapi.Param{
+		Name:        "If-Match",
+		Type:        "string",
+		Description: "ETag of the match version the change is based on; a stale version answers 409",
+	}
+	ifNoneMatchHeader = openapi.Param{
+		Name:        "If-None-Match",
+		Type:        "string",
+		Description: "ETag of a previous answer; 304 Not Modified if it is still current",
+	}
+	printRoundQuery  = openapi.IntQuery("round", "Only this round (default: every round)")
+	printTitleQuery  = openapi.StringQuery("title", "Title of the sheet")
+	printFormatQuery = openapi.StringQuery("format", "Output format (default html)", "html", "pdf")
+	createPlayers    = openapi.BoolQuery("create_players", "Add unknown players to premier_players")
+	playerNameQuery  = openapi.StringQuery("name", "Player name, instead of the ID in the path")
+	limitQuery       = openapi.IntQuery("limit", "Maximum number of items, 1 to 500 (default 50)")
+
+	pageQueries = []openapi.Param{
+		openapi.IntQuery("limit", "Maximum number of items, 1 to 100 (default 20)"),
+		openapi.StringQuery("cursor", "next_cursor of the previous page"),
+	}
+
+	// historyQueries filter and sort the tournament history
+	historyQueries = []openapi.Param{
+		openapi.StringQuery("status", "Only tournaments with this status (default both)", models.TournamentStatusCompleted, models.TournamentStatusArchived),
+		openapi.StringQuery("type", "Only tournaments of this type", "IN_PERSON", "ONLINE"),
+		openapi.StringQuery("format", "Only online tournaments of this format and in-person ones with a round of it", "PB", "BF"),
+		openapi.IntQuery("year", "Only tournaments of this year"),
+		openapi.StringQuery("from", "Only tournaments on or after this date (YYYY-MM-DD), by start date or month"),
+		openapi.StringQuery("to", "Only tournaments on or before this date (YYYY-MM-DD), by start date or month"),
+		openapi.StringQuery("player", "Only tournaments this player is in the standings of, by name"),
+		openapi.StringQuery("q", "Only tournaments whose name contains this text"),
+		openapi.StringQuery("sort", "Order of the tournaments (default date)", "date", "name"),
+		openapi.StringQuery("order", "Direction (default desc for date, asc for name)", "asc", "desc"),
+	}
+
+	// playerIDOrName is a player ID path parameter ignored when ?name= is given
+	playerIDOrName = openapi.Param{Name: "player_id", Type: "string", Description: "Player ID (in-person or premier player)"}
+)
+
+// Endpoints describes the query parameters, bodies and responses of the handlers for
+// the OpenAPI document. Routes missing here are still documented, from their path.
+var Endpoints = []openapi.Endpoint{
+	// In-person tournament
+	{Handler: GetFixture, Response: models.FixtureResponse{}},
+	{Handler: GetStandings, Response: []models.Standing{}},
+	{Handler: GetMatch, Response: models.MatchDetail{}, Description: "The ETag header holds the match version, for If-Match on updates."},
+	{Handler: UpdateMatchScore, Body: models.UpdateScoreRequest{}, Headers: []openapi.Param{ifMatchHeader}},
+	{Handler: CreateFixture, Body: models.CreateFixtureRequest{}, Status: http.StatusCreated},
+	{Handler: ClearTournament, Query: []openapi.Param{openapi.BoolQuery("clear_players", "Delete the players too")}},
+	{Handler: CreateRound, Body: models.CreateRoundRequest{}, Status: http.StatusCreated, Response: models.FixtureRound{}},
+	{Handler: LockRound, Response: models.FixtureRound{}},
+	{Handler: UnlockRound, Response: models.FixtureRound{}},
+	{Handler: AssignRoundTables, Response: models.FixtureRound{}},
+
+	// Printouts
+	{Handler: PrintPairings, Produces: []string{"text/html", "application/pdf"}, Query: []openapi.Param{
+		printRoundQuery, printTitleQuery, printFormatQuery,
+		openapi.StringQuery("sort", "Order of the pairings (default table)", "table", "name"),
+	}},
+	{Handler: PrintStandings, Produces: []string{"text/html", "application/pdf"}, Query: []openapi.Param{printTitleQuery, printFormatQuery}},
+	{Handler: PrintResultSlips, Produces: []string{"text/html", "application/pdf"}, Query: []openapi.Param{printRoundQuery, printTitleQuery, printFormatQuery}},
+
+	// Round clocks
+	{Handler: GetCurrentRoundClock, Response: models.RoundClock{}},
+	{Handler: GetRoundClock, Response: models.RoundClock{}},
+	{Handler: StreamRoundClock, Produces: []string{"text/event-stream"}, Description: "Server-sent events with the current round clock on every change."},
+	{Handler: StartRoundClock, Body: models.StartRoundClockRequest{}, BodyOptional: true, Response: models.RoundClock{}},
+	{Handler: PauseRoundClock, Response: models.RoundClock{}},
+	{Handler: ResumeRoundClock, Response: models.RoundClock{}},
+	{Handler: ExtendRoundClock, Body: models.ExtendRoundClockRequest{}, Response: models.RoundClock{}},
+	{Handler: AddMatchTimeExtension, Body: models.MatchTimeExtensionRequest{}, Response: models.RoundClock{}},
+	{Handler: ResetRoundClock, Response: models.RoundClock{}, Description: "Clears the clock of the round; it can be started again."},
+
+	// Players
+	{Handler: GetPlayers, Response: []models.Player{}},
+	{Handler: GetPremierPlayers, Description: "Every premier player, as id and name."},
+	{Handler: GetConfirmedPlayers, Response: []models.Player{}},
+	{Handler: CreatePlayer, Body: models.CreatePlayerRequest{}, Status: http.StatusCreated, Response: models.Player{}},
+	{Handler: TogglePlayerConfirmed, Response: models.Player{}},
+	{Handler: SetPlayerFixedTable, Body: models.SetFixedTableRequest{}, Response: models.Player{}},
+	{Handler: LateEntryPlayer, Body: models.LateEntryRequest{}, Status: http.StatusCreated},
+	{Handler: DropPlayer, Body: models.WithdrawPlayerRequest{}, BodyOptional: true},
+	{Handler: DisqualifyPlayer, Body: models.WithdrawPlayerRequest{}, BodyOptional: true},
+	{Handler: GetPlayerTournamentHistory, Path: []openapi.Param{playerIDOrName}, Query: []openapi.Param{playerNameQuery},
+		Response: []PlayerTournamentHistory{}},
+
+	// Judging and corrections
+	{Handler: CreateInfraction, Body: models.CreateInfractionRequest{}, Status: http.StatusCreated, Response: models.InfractionResponse{}},
+	{Handler: GetInfractions, Response: []models.Infraction{}, Query: []openapi.Param{
+		openapi.IntQuery("tournament_id", "Infractions of an archived tournament (default: the running one)"),
+		openapi.IntQuery("round", "Only this round"),
+	}},
+	{Handler: GetPlayerInfractions, Path: []openapi.Param{playerIDOrName}, Query: []openapi.Param{playerNameQuery},
+		Response: models.PlayerInfractionHistory{}},
+	{Handler: CorrectMatch, Body: models.MatchCorrectionRequest{}, Headers: []openapi.Param{ifMatchHeader}},
+	{Handler: CorrectOnlineMatch, Body: models.MatchCorrectionRequest{}, Headers: []openapi.Param{ifMatchHeader}},
+	{Handler: CorrectArchivedMatch, Body: models.MatchCorrectionRequest{}},
+	{Handler: GetMatchCorrections, Response: []models.MatchCorrection{}, Query: []openapi.Param{
+		openapi.IntQuery("tournament_id", "Corrections of a tournament"),
+	}},
+	{Handler: RecomputeTournamentStandings, Description: "Rebuilds the standings of a completed or archived tournament from its matches."},
+
+	// Tournament history
+	{Handler: GetTournaments, Response: []models.Tournament{}, Query: append(historyQueries,
+		openapi.IntQuery("limit", "Maximum number of tournaments, 1 to 500 (default: all)"),
+		openapi.IntQuery("offset", "Tournaments to skip"),
+	), Description: "X-Total-Count holds the number of matching tournaments."},
+	{Handler: GetTournamentStandings, Response: []models.TournamentStanding{}},
+	{Handler: GetTournamentRounds, Response: models.TournamentRoundsResponse{}},
+	{Handler: GetTournamentRaces, Description: "Race counts and winrates of the tournament, for PB and BF."},
+	{Handler: GetArchivedTournamentPlayers},
+	{Handler: GetAllActiveTournaments, Description: "Online and in-person tournaments in registration or in progress."},
+	{Handler: GetGlobalStandings, Response: []models.GlobalStanding{}, Headers: []openapi.Param{ifNoneMatchHeader},
+		Description: "Cached until the archive changes; send the ETag back in If-None-Match to get 304 while it holds."},
+	{Handler: GetGlobalRaces, Response: models.GlobalRaces{}, Headers: []openapi.Param{ifNoneMatchHeader},
+		Description: "Cached until the archive changes; send the ETag back in If-None-Match to get 304 while it holds."},
+	{Handler: GetTournamentPlayerRaces, Response: []models.TournamentPlayerRace{}},
+	{Handler: UpdatePlayerRace, Body: models.UpdatePlayerRaceRequest{}},
+	{Handler: ArchiveTournament, Body: models.ArchiveTournamentRequest{}},
+	{Handler: DeleteArchivedTournament},
+	{Handler: UpdateTournamentStatus, Body: models.UpdateTournamentStatusRequest{}, Query: []openapi.Param{
+		openapi.BoolQuery("force", "Complete the tournament even with pending matches"),
+	}},
+	{Handler: ExportTournament, Response: models.TournamentBundle{}, Produces: []string{"application/zip"}, Query: []openapi.Param{
+		openapi.StringQuery("format", "JSON bundle or zip of CSV files (default json)", "json", "csv"),
+	}},
+	{Handler: ImportTournament, Body: models.TournamentBundle{}, Files: []string{"file"}, Consumes: []string{"application/zip"},
+		Status: http.StatusCreated, Response: models.TournamentImportResponse{}, Query: []openapi.Param{
+			createPlayers,
+			openapi.BoolQuery("allow_duplicate", "Import even if the same tournament exists"),
+		}},
+	{Handler: BulkImportTournaments, Files: []string{"standings", "pairings", "races"}, Status: http.StatusCreated,
+		Response: models.BulkImportReport{}, Query: []openapi.Param{
+			createPlayers,
+			openapi.BoolQuery("dry_run", "Validate and report without importing (answers 200)"),
+		}},
+
+	// Online tournaments
+	{Handler: CreateOnlineTournament, Body: models.CreateOnlineTournamentRequest{}, Status: http.StatusCreated},
+	{Handler: GetOnlineTournamentInfo},
+	{Handler: DeleteOnlineTournament},
+	{Handler: GetOnlineTournamentMatches, Response: []models.OnlineTournamentMatch{}},
+	{Handler: GetOnlinePendingMatches, Response: []models.OnlineTournamentMatch{}},
+	{Handler: GetOnlineCompletedMatches, Response: []models.OnlineTournamentMatch{}},
+	{Handler: GetOnlineExpiringMatches, Response: []models.OnlineTournamentMatch{}, Query: []openapi.Param{
+		openapi.IntQuery("hours", "Deadlines within this many hours (default 48)"),
+	}},
+	{Handler: GetOnlineTournamentStandings, Response: []models.OnlineTournamentStanding{}},
+	{Handler: GetOnlineMatch, Response: models.OnlineTournamentMatch{}, Description: "The ETag header holds the match version, for If-Match on updates."},
+	{Handler: UpdateOnlineMatchScore, Body: models.UpdateOnlineMatchScoreRequest{}, Headers: []openapi.Param{ifMatchHeader}},
+	{Handler: AddOnlineLateEntry, Body: models.OnlineLateEntryRequest{}, Status: http.StatusCreated},
+	{Handler: DropOnlinePlayer, Body: models.WithdrawPlayerRequest{}, BodyOptional: true},
+	{Handler: DisqualifyOnlinePlayer, Body: models.WithdrawPlayerRequest{}, BodyOptional: true},
+	{Handler: UpdateOnlineDeadlinePolicy, Body: models.UpdateDeadlinePolicyRequest{}},
+	{Handler: GetOnlineMatchdays, Response: []models.OnlineMatchday{}},
+	{Handler: ScheduleOnlineMatchdays, Body: models.ScheduleMatchdaysRequest{}},
+	{Handler: SetOnlineMatchdayDeadline, Body: models.SetMatchdayDeadlineRequest{}},
+	{Handler: SetOnlineMatchDeadline, Body: models.SetMatchDeadlineRequest{}},
+	{Handler: ClaimOnlineForfeit, Body: models.ClaimForfeitRequest{}},
+	{Handler: GetMatchProposals, Response: []models.MatchProposal{}},
+	{Handler: CreateMatchProposal, Body: models.CreateMatchProposalRequest{}, Status: http.StatusCreated, Response: models.MatchProposal{}},
+	{Handler: AcceptMatchProposal, Body: models.AcceptMatchProposalRequest{}},
+	{Handler: DeclineMatchProposal, Body: models.RespondMatchProposalRequest{}},
+	{Handler: WithdrawMatchProposal, Body: models.RespondMatchProposalRequest{}},
+	{Handler: GetPlayerCalendar, Produces: []string{"text/calendar"}},
+
+	// Seasons
+	{Handler: GetSeasons, Response: []models.Season{}},
+	{Handler: GetSeason, Response: models.Season{}},
+	{Handler: GetSeasonLeaderboard, Response: models.SeasonLeaderboardResponse{}},
+	{Handler: CreateSeason, Body: models.CreateSeasonRequest{}, Status: http.StatusCreated},
+	{Handler: UpdateSeason, Body: models.UpdateSeasonRequest{}},
+	{Handler: DeleteSeason},
+	{Handler: AddSeasonTournament, Body: models.AddSeasonTournamentRequest{}},
+	{Handler: RemoveSeasonTournament},
+
+	// Webhooks
+	{Handler: CreateWebhook, Body: models.CreateWebhookRequest{}, Status: http.StatusCreated, Response: models.Webhook{}},
+	{Handler: GetWebhooks, Response: WebhookList{}},
+	{Handler: UpdateWebhook, Body: models.UpdateWebhookRequest{}, Response: models.Webhook{}},
+	{Handler: DeleteWebhook},
+	{Handler: TestWebhook, Status: http.StatusAccepted},
+	{Handler: GetWebhookDeliveries, Response: []models.WebhookDelivery{}, Query: []openapi.Param{
+		openapi.StringQuery("status", "Only deliveries with this status", models.WebhookDeliveryPending,
+			models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed),
+		limitQuery,
+	}},
+	{Handler: RetryWebhookDelivery, Status: http.StatusAccepted},
+
+	// Discord
+	{Handler: DiscordInteractions, Description: "Called by Discord, signed with Ed25519 (X-Signature-Ed25519 and X-Signature-Timestamp)."},
+	{Handler: GetDiscordLinks, Response: []models.DiscordLink{}},
+	{Handler: LinkDiscordUser, Path: []openapi.Param{{Name: "discord_user_id", Type: "string"}}, Body: models.LinkDiscordRequest{},
+		Response: models.DiscordLink{}},
+	{Handler: UnlinkDiscordUser, Path: []openapi.Param{{Name: "discord_user_id", Type: "string"}}},
+	{Handler: RunDiscordCommand, Body: models.DiscordCommandRequest{}},
+
+	// Notifications
+	{Handler: GetPlayerContact, Response: models.PlayerContact{}},
+	{Handler: UpdatePlayerContact, Body: models.UpdatePlayerContactRequest{}, Response: models.PlayerContact{}},
+	{Handler: DeletePlayerContact},
+	{Handler: TestPlayerContact, Status: http.StatusAccepted},
+	{Handler: GetNotifications, Response: []models.Notification{}, Query: []openapi.Param{
+		openapi.StringQuery("status", "Only notifications with this status", models.NotificationPending,
+			models.NotificationSent, models.NotificationFailed),
+		openapi.IntQuery("player_id", "Only notifications of this premier player"),
+		limitQuery,
+	}},
+	{Handler: RetryNotification, Status: http.StatusAccepted},
+
+	// Health
+	{Handler: Health, Response: HealthStatus{}, Error: HealthStatus{}},
+	{Handler: Ready, Response: HealthStatus{}, Error: HealthStatus{}, Description: "503 while shutting down, without the database or with migrations pending."},
+
+	// API v2
+	{Handler: GetTournamentsV2, Query: append(historyQueries, pageQueries...), Response: TournamentPageV2{}, Error: apiv2.ErrorResponse{}},
+	{Handler: GetTournamentV2, Response: TournamentResponseV2{}, Error: apiv2.ErrorResponse{}},
+	{Handler: GetTournamentStandingsV2, Response: StandingsResponseV2{}, Error: apiv2.ErrorResponse{}},
+	{Handler: GetPlayersV2, Query: pageQueries, Response: PlayerPageV2{}, Error: apiv2.ErrorResponse{}},
+	{Handler: GetPremierPlayersV2, Query: pageQueries, Response: PremierPlayerPageV2{}, Error: apiv2.ErrorResponse{}},
+	{Handler: GetFixtureV2, Response: FixtureResponseV2{}, Error: apiv2.ErrorResponse{}},
+	{Handler: GetStandingsV2, Response: StandingsResponseV2{}, Error: apiv2.ErrorResponse{}},
+	{Handler: GetMatchV2, Response: MatchResponseV2{}, Error: apiv2.ErrorResponse{}, Description: "The ETag header holds the match version, for If-Match on updates."},
+}
+
+// WebhookList is the answer of GetWebhooks
+type WebhookList struct {
+	Webhooks []models.Webhook `json:"webhooks"`
+	Events   []string         `json:"events"`
+}
+
+// HealthStatus is the answer of Health and Ready, as documented
+type HealthStatus struct {
+	Status     string   `json:"status"`
+	Database   string   `json:"database,omitempty"`
+	Migrations string   `json:"migrations,omitempty"`
+	Pending    []string `json:"pending,omitempty"`
+}
+
+// API v2 envelopes, as documented; the handlers answer through apiv2.OK and apiv2.List
+type (
+	TournamentPageV2 struct {
+		Data []models.Tournament `json:"data"`
+		Meta apiv2.Meta          `json:"meta"`
+	}
+	PlayerPageV2 struct {
+		Data []models.Player `json:"data"`
+		Meta apiv2.Meta      `json:"meta"`
+	}
+	PremierPlayerPageV2 struct {
+		Data []models.PremierPlayer `json:"data"`
+		Meta apiv2.Meta             `json:"meta"`
+	}
+	TournamentResponseV2 struct {
+		Data models.Tournament `json:"data"`
+	}
+	StandingsResponseV2 struct {
+		Data []models.StandingV2 `json:"data"`
+	}
+	FixtureResponseV2 struct {
+		Data models.FixtureV2 `json:"data"`
+	}
+	MatchResponseV2 struct {
+		Data models.MatchDetail `json:"data"`
+	}
+)
@@ -2,11 +2,11 @@ package handlers
 
 import (
 	"database/sql"
-	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/logging"
 	"github.com/gin-gonic/gin"
 )
 
@@ -40,7 +40,7 @@ func GetPlayerTournamentHistory(c *gin.Context) {
 	playerIDStr := c.Param("player_id")
 	playerName := c.Query("name")
 
-	fmt.Println("GetPlayerTournamentHistory called with player_id:", playerIDStr, "player_name:", playerName)
+	logging.FromContext(c.Request.Context()).Debug("player history", "player_id", playerIDStr, "player_name", playerName)
 
 	// If player_name is provided as query param, use it directly
 	if playerName != "" {
@@ -51,19 +51,21 @@ func GetPlayerTournamentHistory(c *gin.Context) {
 	// Otherwise, try to get player name from player ID
 	playerID, err := strconv.Atoi(playerIDStr)
 	if err != nil {
-		fmt.Println("Error converting player_id to int:", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
 		return
 	}
 
 	// Try to get the player name from the active players table first
 	var pName string
-	err = database.DB.QueryRow("SELECT name FROM players WHERE id = $1", playerID).Scan(&pName)
+	ctx := c.Request.Context()
+	err = database.DB.QueryRowContext(ctx, "SELECT name FROM players WHERE id = $1", playerID).Scan(&pName)
 	if err != nil {
 		// If not found in active players, try premier_players table
-		err = database.DB.QueryRow("SELECT name FROM premier_players WHERE id = $1", playerID).Scan(&pName)
+		err = database.DB.QueryRowContext(ctx, "SELECT name FROM premier_players WHERE id = $1", playerID).Scan(&pName)
 		if err != nil {
-			fmt.Println("Error getting player name from either table:", err)
+			if err != sql.ErrNoRows {
+				logging.FromContext(ctx).Error("failed to get player name", "player_id", playerID, "error", err)
+			}
 			c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
 			return
 		}
@@ -93,44 +95,24 @@ func fetchPlayerTournamentHistory(c *gin.Context, playerName string) {
 			ts.total_points_scored,
 			tpr.race_pb,
 			tpr.race_bf,
-			-- PB format statistics
-			COALESCE(SUM(CASE 
-				WHEN tr.format = 'PB' AND tm.completed = true AND (
-					(tm.player1_id = ts.player_id AND tm.score1 > tm.score2) OR
-					(tm.player2_id = ts.player_id AND tm.score2 > tm.score1)
-				) THEN 1 ELSE 0 
-			END), 0) as pb_wins,
-			COALESCE(SUM(CASE 
-				WHEN tr.format = 'PB' AND tm.completed = true AND tm.score1 = tm.score2 AND (tm.player1_id = ts.player_id OR tm.player2_id = ts.player_id) THEN 1 ELSE 0 
-			END), 0) as pb_ties,
-			COALESCE(SUM(CASE 
-				WHEN tr.format = 'PB' AND tm.completed = true AND (tm.player1_id = ts.player_id OR tm.player2_id = ts.player_id) THEN 1 ELSE 0 
-			END), 0) as pb_matches,
-			-- BF format statistics
-			COALESCE(SUM(CASE 
-				WHEN tr.format = 'BF' AND tm.completed = true AND (
-					(tm.player1_id = ts.player_id AND tm.score1 > tm.score2) OR
-					(tm.player2_id = ts.player_id AND tm.score2 > tm.score1)
-				) THEN 1 ELSE 0 
-			END), 0) as bf_wins,
-			COALESCE(SUM(CASE 
-				WHEN tr.format = 'BF' AND tm.completed = true AND tm.score1 = tm.score2 AND (tm.player1_id = ts.player_id OR tm.player2_id = ts.player_id) THEN 1 ELSE 0 
-			END), 0) as bf_ties,
-			COALESCE(SUM(CASE 
-				WHEN tr.format = 'BF' AND tm.completed = true AND (tm.player1_id = ts.player_id OR tm.player2_id = ts.player_id) THEN 1 ELSE 0 
-			END), 0) as bf_matches
+			-- Results by format, from the player_format_stats summary
+			COALESCE(SUM(CASE WHEN pfs.format = 'PB' THEN pfs.wins END), 0) as pb_wins,
+			COALESCE(SUM(CASE WHEN pfs.format = 'PB' THEN pfs.ties END), 0) as pb_ties,
+			COALESCE(SUM(CASE WHEN pfs.format = 'PB' THEN pfs.matches END), 0) as pb_matches,
+			COALESCE(SUM(CASE WHEN pfs.format = 'BF' THEN pfs.wins END), 0) as bf_wins,
+			COALESCE(SUM(CASE WHEN pfs.format = 'BF' THEN pfs.ties END), 0) as bf_ties,
+			COALESCE(SUM(CASE WHEN pfs.format = 'BF' THEN pfs.matches END), 0) as bf_matches
 		FROM tournaments t
 		INNER JOIN tournament_standings ts ON t.id = ts.tournament_id AND ts.player_name = $1
 		LEFT JOIN tournament_player_races tpr ON t.id = tpr.tournament_id AND tpr.player_name = ts.player_name
-		LEFT JOIN tournament_rounds tr ON t.id = tr.tournament_id
-		LEFT JOIN tournament_matches tm ON tr.id = tm.tournament_round_id
-		GROUP BY t.id, t.name, t.month, t.year, ts.id, tpr.race_pb, tpr.race_bf
-		ORDER BY t.year DESC, t.month DESC
+		LEFT JOIN player_format_stats pfs ON pfs.tournament_id = t.id AND pfs.player_id = ts.player_id
+		GROUP BY t.id, t.name, t.month, t.year, t.month_start, ts.id, tpr.race_pb, tpr.race_bf
+		ORDER BY t.month_start DESC NULLS LAST, t.id DESC
 	`
 
-	rows, err := database.DB.Query(query, playerName)
+	rows, err := database.DB.QueryContext(c.Request.Context(), query, playerName)
 	if err != nil {
-		fmt.Println("Database query error:", err)
+		logging.FromContext(c.Request.Context()).Error("failed to fetch player history", "player_name", playerName, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player tournament history"})
 		return
 	}
@@ -0,0 +1,656 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/playerstats"
+	"github.com/andreuvv/premier_mitologico/backend/internal/roundclock"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+// DropPlayer marks an in-person player as dropped and resolves their pending matches
+func DropPlayer(c *gin.Context) {
+	withdrawPlayer(c, models.PlayerStatusDropped)
+}
+
+// DisqualifyPlayer marks an in-person player as disqualified and resolves their pending matches
+func DisqualifyPlayer(c *gin.Context) {
+	withdrawPlayer(c, models.PlayerStatusDisqualified)
+}
+
+func withdrawPlayer(c *gin.Context, status string) {
+	ctx := c.Request.Context()
+	playerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	// The body is optional: an empty request drops with the forfeit policy
+	var req models.WithdrawPlayerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	policy := req.Policy
+	if policy == "" {
+		policy = models.DropPolicyForfeit
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var playerName, currentStatus string
+	err = tx.QueryRowContext(ctx, "SELECT name, status FROM players WHERE id = $1 FOR UPDATE", playerID).Scan(&playerName, &currentStatus)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player"})
+		return
+	}
+	if currentStatus != models.PlayerStatusActive {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Player is already %s", currentStatus)})
+		return
+	}
+
+	forfeited, removed, events, err := withdrawInPersonPlayer(ctx, tx, playerID, status, req.Reason, policy)
+	var lockedErr *roundLockedError
+	if errors.As(err, &lockedErr) {
+		respondRoundLocked(c, lockedErr.RoundNumber)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw player: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	emitForfeits(events)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           fmt.Sprintf("Player %s is now %s", playerName, status),
+		"player_id":         playerID,
+		"status":            status,
+		"policy":            policy,
+		"matches_forfeited": forfeited,
+		"matches_removed":   removed,
+	})
+}
+
+// withdrawInPersonPlayer sets the status of an active in-person player and resolves
+// their pending matches with the given policy. Forfeits are written like any other
+// result, through setMatchResult, and match.completed is queued in tx for each; the
+// caller emits the returned events after commit (emitForfeits). A pending match in a
+// locked round fails with a *roundLockedError.
+func withdrawInPersonPlayer(ctx context.Context, tx *sql.Tx, playerID int, status string, reason *string, policy string) (forfeited, removed int, events []webhooks.MatchData, err error) {
+	_, err = tx.ExecContext(ctx, `
+		UPDATE players
+		SET status = $1, status_reason = $2, status_changed_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, status, reason, playerID)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	// The opponent only gets the forfeit win if they are still playing (BYE never wins)
+	opponentActive := func(opponentID int) (bool, error) {
+		var name, opponentStatus string
+		err := tx.QueryRowContext(ctx, "SELECT name, status FROM players WHERE id = $1", opponentID).Scan(&name, &opponentStatus)
+		if err != nil {
+			return false, err
+		}
+		return name != "BYE" && opponentStatus == models.PlayerStatusActive, nil
+	}
+
+	// Locked like UpdateMatchScore: a score sent meanwhile waits, then finds a new
+	// version and answers 409 to its If-Match
+	forfeit := func(matchID, score1, score2 int) error {
+		var roundNumber int
+		var locked bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT r.round_number, r.locked_at IS NOT NULL
+			FROM matches m
+			JOIN rounds r ON m.round_id = r.id
+			WHERE m.id = $1
+			FOR UPDATE OF m FOR SHARE OF r
+		`, matchID).Scan(&roundNumber, &locked)
+		if err != nil {
+			return err
+		}
+		if locked {
+			return &roundLockedError{RoundNumber: roundNumber}
+		}
+		if err := setMatchResult(ctx, tx, matchID, score1, score2, models.MatchResultForfeit, true); err != nil {
+			return err
+		}
+
+		match, err := fetchMatchDetail(ctx, tx, matchID)
+		if err != nil {
+			return err
+		}
+		event := webhooks.MatchData{Source: models.MatchSourceInPerson, Match: match}
+		if err := webhooks.Enqueue(ctx, tx, webhooks.EventMatchCompleted, event); err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	}
+
+	forfeited, removed, err = resolvePendingMatches(ctx, tx, "matches", 0, playerID, policy, opponentActive, forfeit)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return forfeited, removed, events, nil
+}
+
+// emitForfeits emits, after commit, the match.completed events withdrawInPersonPlayer
+// queued
+func emitForfeits(events []webhooks.MatchData) {
+	if len(events) == 0 {
+		return
+	}
+	roundclock.Notify()
+	for _, event := range events {
+		webhooks.Emit(webhooks.EventMatchCompleted, event)
+	}
+}
+
+// LateEntryPlayer registers a player after the fixture was created. The player
+// takes the BYE slot in every round that has not started yet, or gets a match
+// against BYE in rounds without one.
+func LateEntryPlayer(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req models.LateEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name == "BYE" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "BYE is a reserved player name"})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM players WHERE name = $1)", req.Name).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing player"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "Player already exists: " + req.Name})
+		return
+	}
+
+	var player models.Player
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO players (name, confirmed, late_entry)
+		VALUES ($1, true, true)
+		RETURNING id, name, confirmed, status, late_entry, fixed_table, created_at, updated_at
+	`, req.Name).Scan(
+		&player.ID,
+		&player.Name,
+		&player.Confirmed,
+		&player.Status,
+		&player.LateEntry,
+		&player.FixedTable,
+		&player.CreatedAt,
+		&player.UpdatedAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create player"})
+		return
+	}
+
+	// Rounds that have not started: no completed match yet
+	rows, err := tx.QueryContext(ctx, `
+		SELECT r.id, r.round_number
+		FROM rounds r
+		WHERE NOT EXISTS (SELECT 1 FROM matches m WHERE m.round_id = r.id AND m.completed = true)
+		ORDER BY r.round_number
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rounds"})
+		return
+	}
+
+	type pendingRound struct {
+		ID     int
+		Number int
+	}
+	var rounds []pendingRound
+	for rows.Next() {
+		var r pendingRound
+		if err := rows.Scan(&r.ID, &r.Number); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan round"})
+			return
+		}
+		rounds = append(rounds, r)
+	}
+	rows.Close()
+
+	byeID := 0
+	err = tx.QueryRowContext(ctx, "SELECT id FROM players WHERE name = 'BYE'").Scan(&byeID)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch BYE player"})
+		return
+	}
+
+	pairedRounds := []int{}
+	for _, r := range rounds {
+		// Take over an existing BYE slot if there is one
+		if byeID != 0 {
+			var matchID int
+			err := tx.QueryRowContext(ctx, `
+				UPDATE matches
+				SET player1_id = CASE WHEN player1_id = $1 THEN $2 ELSE player1_id END,
+					player2_id = CASE WHEN player2_id = $1 THEN $2 ELSE player2_id END
+				WHERE id = (
+					SELECT id FROM matches
+					WHERE round_id = $3 AND (player1_id = $1 OR player2_id = $1)
+					ORDER BY id
+					LIMIT 1
+				)
+				RETURNING id
+			`, byeID, player.ID, r.ID).Scan(&matchID)
+			if err != nil && err != sql.ErrNoRows {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pair late entry"})
+				return
+			}
+			if err == nil {
+				// The former BYE match is now a real match and needs a table
+				if err := seatLateMatch(ctx, tx, r.ID, matchID); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign table"})
+					return
+				}
+				pairedRounds = append(pairedRounds, r.Number)
+				continue
+			}
+		}
+
+		// Otherwise the late entrant gets a BYE in this round
+		if byeID == 0 {
+			err := tx.QueryRowContext(ctx,
+				"INSERT INTO players (name, confirmed) VALUES ($1, $2) RETURNING id",
+				"BYE", false,
+			).Scan(&byeID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create BYE player"})
+				return
+			}
+		}
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO matches (round_id, player1_id, player2_id) VALUES ($1, $2, $3)",
+			r.ID, player.ID, byeID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create match"})
+			return
+		}
+		pairedRounds = append(pairedRounds, r.Number)
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Late entry registered successfully",
+		"player":        player,
+		"paired_rounds": pairedRounds,
+	})
+}
+
+// DropOnlinePlayer marks a player of an online tournament as dropped and resolves their pending matches
+func DropOnlinePlayer(c *gin.Context) {
+	withdrawOnlinePlayer(c, models.PlayerStatusDropped)
+}
+
+// DisqualifyOnlinePlayer marks a player of an online tournament as disqualified and resolves their pending matches
+func DisqualifyOnlinePlayer(c *gin.Context) {
+	withdrawOnlinePlayer(c, models.PlayerStatusDisqualified)
+}
+
+func withdrawOnlinePlayer(c *gin.Context, status string) {
+	ctx := c.Request.Context()
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	playerID, err := strconv.Atoi(c.Param("player_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	// The body is optional: an empty request drops with the forfeit policy
+	var req models.WithdrawPlayerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	policy := req.Policy
+	if policy == "" {
+		policy = models.DropPolicyForfeit
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	if !requireOnlineTournamentOpen(c, tx, tournamentID) {
+		return
+	}
+
+	var playerName, currentStatus string
+	err = tx.QueryRowContext(ctx, `
+		SELECT player_name, status FROM online_tournament_players
+		WHERE tournament_id = $1 AND player_id = $2
+		FOR UPDATE
+	`, tournamentID, playerID).Scan(&playerName, &currentStatus)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player is not part of this tournament"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player"})
+		return
+	}
+	if currentStatus != models.PlayerStatusActive {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Player is already %s", currentStatus)})
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE online_tournament_players
+		SET status = $1, status_reason = $2, status_changed_at = CURRENT_TIMESTAMP
+		WHERE tournament_id = $3 AND player_id = $4
+	`, status, req.Reason, tournamentID, playerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player status"})
+		return
+	}
+
+	opponentActive := func(opponentID int) (bool, error) {
+		var opponentStatus string
+		err := tx.QueryRowContext(ctx,
+			"SELECT status FROM online_tournament_players WHERE tournament_id = $1 AND player_id = $2",
+			tournamentID, opponentID,
+		).Scan(&opponentStatus)
+		if err != nil {
+			return false, err
+		}
+		return opponentStatus == models.PlayerStatusActive, nil
+	}
+
+	forfeited, removed, err := resolvePendingMatches(ctx, tx, "online_tournament_matches", tournamentID, playerID, policy, opponentActive, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve pending matches: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           fmt.Sprintf("Player %s is now %s", playerName, status),
+		"tournament_id":     tournamentID,
+		"player_id":         playerID,
+		"status":            status,
+		"policy":            policy,
+		"matches_forfeited": forfeited,
+		"matches_removed":   removed,
+	})
+}
+
+// AddOnlineLateEntry adds a player to an online tournament that already started
+// and generates their pairings against every active player, placed in matchdays
+// with deadlines if the tournament has them
+func AddOnlineLateEntry(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	var req models.OnlineLateEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	if !requireOnlineTournamentOpen(c, tx, tournamentID) {
+		return
+	}
+
+	var playerName string
+	err = tx.QueryRowContext(ctx, "SELECT name FROM premier_players WHERE id = $1", req.PlayerID).Scan(&playerName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Player with ID %d not found in premier players", req.PlayerID)})
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO online_tournament_players (tournament_id, player_id, player_name, late_entry)
+		VALUES ($1, $2, $3, true)
+		ON CONFLICT (tournament_id, player_id) DO NOTHING
+	`, tournamentID, req.PlayerID, playerName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add player to tournament"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Player is already part of this tournament"})
+		return
+	}
+
+	// Pair the late entrant against every player still in the tournament
+	rows, err := tx.QueryContext(ctx, `
+		SELECT player_id, player_name FROM online_tournament_players
+		WHERE tournament_id = $1 AND player_id != $2 AND status = 'active'
+		ORDER BY player_name
+	`, tournamentID, req.PlayerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournament players"})
+		return
+	}
+
+	type opponent struct {
+		ID   int
+		Name string
+	}
+	var opponents []opponent
+	for rows.Next() {
+		var o opponent
+		if err := rows.Scan(&o.ID, &o.Name); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan tournament player"})
+			return
+		}
+		opponents = append(opponents, o)
+	}
+	rows.Close()
+
+	opponentIDs := make([]int, 0, len(opponents))
+	for _, o := range opponents {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO online_tournament_matches
+			(tournament_id, player1_id, player2_id, player1_name, player2_name, completed)
+			VALUES ($1, $2, $3, $4, $5, false)
+		`, tournamentID, o.ID, req.PlayerID, o.Name, playerName)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create match"})
+			return
+		}
+		opponentIDs = append(opponentIDs, o.ID)
+	}
+
+	// Matchdays and deadlines, so the new matches expire and get reminders too
+	if err := scheduleLateEntry(ctx, tx, tournamentID, req.PlayerID, opponentIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign matchdays"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":           "Late entry added successfully",
+		"tournament_id":     tournamentID,
+		"player_id":         req.PlayerID,
+		"player_name":       playerName,
+		"matches_generated": len(opponents),
+	})
+}
+
+// requireOnlineTournamentOpen checks that an online tournament exists and still accepts
+// roster changes, writing the error response and returning false otherwise
+func requireOnlineTournamentOpen(c *gin.Context, tx *sql.Tx, tournamentID int) bool {
+	ctx := c.Request.Context()
+	var status string
+	err := tx.QueryRowContext(ctx,
+		"SELECT status FROM tournaments WHERE id = $1 AND type = 'ONLINE' FOR UPDATE",
+		tournamentID,
+	).Scan(&status)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found or is not an online tournament"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournament"})
+		return false
+	}
+	if status != models.TournamentStatusRegistration && status != models.TournamentStatusInProgress {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot change players of a tournament in status '%s'", status)})
+		return false
+	}
+	return true
+}
+
+// resolvePendingMatches applies a drop policy to the pending matches of a withdrawn player
+// in the given match table ("matches" or "online_tournament_matches"). With the forfeit
+// policy the opponent wins ForfeitWinScore-0 if still active, written by forfeit (nil
+// for a plain update of the table); every other pending match is removed.
+// Online matches are scoped to tournamentID; pass 0 for the in-person matches table.
+func resolvePendingMatches(ctx context.Context, tx *sql.Tx, table string, tournamentID, playerID int, policy string, opponentActive func(int) (bool, error), forfeit func(matchID, score1, score2 int) error) (forfeited, removed int, err error) {
+	query := fmt.Sprintf(`
+		SELECT id, player1_id, player2_id FROM %s
+		WHERE completed = false AND (player1_id = $1 OR player2_id = $1)
+	`, table)
+	args := []interface{}{playerID}
+	if tournamentID != 0 {
+		query += " AND tournament_id = $2"
+		args = append(args, tournamentID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	type pendingMatch struct {
+		ID        int
+		Player1ID int
+		Player2ID int
+	}
+	var pending []pendingMatch
+	for rows.Next() {
+		var m pendingMatch
+		if err := rows.Scan(&m.ID, &m.Player1ID, &m.Player2ID); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		pending = append(pending, m)
+	}
+	rows.Close()
+
+	for _, m := range pending {
+		opponentID := m.Player1ID
+		if opponentID == playerID {
+			opponentID = m.Player2ID
+		}
+
+		awardForfeit := false
+		if policy == models.DropPolicyForfeit {
+			awardForfeit, err = opponentActive(opponentID)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		if !awardForfeit {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", table), m.ID); err != nil {
+				return 0, 0, err
+			}
+			removed++
+			continue
+		}
+
+		score1, score2 := models.ForfeitWinScore, 0
+		if m.Player1ID == playerID {
+			score1, score2 = 0, models.ForfeitWinScore
+		}
+		if forfeit != nil {
+			err = forfeit(m.ID, score1, score2)
+		} else {
+			_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+				UPDATE %s
+				SET score1 = $1, score2 = $2, completed = true, result_type = 'forfeit', updated_at = CURRENT_TIMESTAMP
+				WHERE id = $3
+			`, table), score1, score2, m.ID)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		forfeited++
+	}
+
+	if tournamentID != 0 && forfeited > 0 {
+		if err := playerstats.RefreshTournament(ctx, tx, tournamentID); err != nil {
+			return 0, 0, err
+		}
+	}
+	return forfeited, removed, nil
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/printout"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultPrintTitle heads every printed sheet unless ?title= is given
+const defaultPrintTitle = "Premier Mitológico"
+
+// PrintPairings renders the pairings of the current tournament for the wall, sorted by
+// table (default) or by player name (?sort=name). ?round=N prints a single round.
+func PrintPairings(c *gin.Context) {
+	rounds, ok := printRounds(c)
+	if !ok {
+		return
+	}
+
+	switch c.DefaultQuery("sort", "table") {
+	case "table":
+		renderPrintout(c, "pairings", printout.PairingsByTable(printTitle(c), rounds))
+	case "name":
+		renderPrintout(c, "pairings", printout.PairingsByName(printTitle(c), rounds))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be table or name"})
+	}
+}
+
+// PrintStandings renders the current standings
+func PrintStandings(c *gin.Context) {
+	ctx := c.Request.Context()
+	standings, err := fetchStandings(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch standings"})
+		return
+	}
+
+	renderPrintout(c, "standings", printout.Standings(printTitle(c), standings))
+}
+
+// PrintResultSlips renders one result slip per pending match for the players to fill in
+// and sign. ?round=N prints a single round.
+func PrintResultSlips(c *gin.Context) {
+	rounds, ok := printRounds(c)
+	if !ok {
+		return
+	}
+
+	renderPrintout(c, "slips", printout.ResultSlips(printTitle(c), rounds))
+}
+
+// printRounds loads the fixture, keeping only ?round=N when given
+func printRounds(c *gin.Context) ([]models.FixtureRound, bool) {
+	ctx := c.Request.Context()
+	rounds, err := fetchFixture(ctx, database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fixture"})
+		return nil, false
+	}
+
+	roundParam := c.Query("round")
+	if roundParam == "" {
+		return rounds, true
+	}
+	roundNumber, err := strconv.Atoi(roundParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round number"})
+		return nil, false
+	}
+	for _, r := range rounds {
+		if r.Number == roundNumber {
+			return []models.FixtureRound{r}, true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+	return nil, false
+}
+
+func printTitle(c *gin.Context) string {
+	return c.DefaultQuery("title", defaultPrintTitle)
+}
+
+// renderPrintout writes the document as HTML (default) or PDF (?format=pdf)
+func renderPrintout(c *gin.Context, name string, doc printout.Document) {
+	var buf bytes.Buffer
+
+	switch c.DefaultQuery("format", "html") {
+	case "html":
+		if err := printout.RenderHTML(&buf, doc); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render HTML"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+	case "pdf":
+		if err := printout.RenderPDF(&buf, doc); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render PDF"})
+			return
+		}
+		if round := c.Query("round"); round != "" {
+			name += "-round-" + round
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"%s.pdf\"", name))
+		c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be html or pdf"})
+	}
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/roundclock"
+	"github.com/gin-gonic/gin"
+)
+
+// clockResyncInterval is how often the clock stream resends the clock even if
+// nothing changed, so clients correct any drift of their local countdown
+const clockResyncInterval = 15 * time.Second
+
+// GetRoundClock returns the clock of a round
+func GetRoundClock(c *gin.Context) {
+	ctx := c.Request.Context()
+	roundNumber, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round number"})
+		return
+	}
+
+	clock, err := roundclock.Load(ctx, roundNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch round clock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, clock)
+}
+
+// GetCurrentRoundClock returns the clock of the round started last
+func GetCurrentRoundClock(c *gin.Context) {
+	ctx := c.Request.Context()
+	clock, err := roundclock.Current(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No round clock has been started"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch round clock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, clock)
+}
+
+// StreamRoundClock pushes the current round clock as server-sent events: once on
+// connect, on every change, when a round or match runs out of time, and every
+// few seconds to resync
+func StreamRoundClock(c *gin.Context) {
+	ctx := c.Request.Context()
+	updates, unsubscribe := roundclock.Subscribe()
+	defer unsubscribe()
+
+	resync := time.NewTicker(clockResyncInterval)
+	defer resync.Stop()
+
+	// timeUp fires when the clock last sent is due to change by itself
+	timeUp := time.NewTimer(0)
+	defer timeUp.Stop()
+	<-timeUp.C
+
+	send := func() bool {
+		clock, err := roundclock.Current(ctx)
+		if err == sql.ErrNoRows {
+			c.SSEvent("clock", gin.H{"status": models.ClockStatusNotStarted})
+			return true
+		}
+		if err != nil {
+			c.SSEvent("error", gin.H{"error": "Failed to fetch round clock"})
+			return false
+		}
+		c.SSEvent("clock", clock)
+
+		timeUp.Stop()
+		select {
+		case <-timeUp.C:
+		default:
+		}
+		if next := roundclock.NextChange(clock); next > 0 {
+			timeUp.Reset(next)
+		}
+		return true
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	// The stream outlives the server's write timeout
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	if !send() {
+		return
+	}
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-draining:
+			return false
+		case <-updates:
+		case <-resync.C:
+		case <-timeUp.C:
+		}
+		return send()
+	})
+}
+
+// StartRoundClock starts the clock of a round. The duration and the number of extra
+// turns default to ROUND_DURATION and ROUND_EXTRA_TURNS.
+func StartRoundClock(c *gin.Context) {
+	ctx := c.Request.Context()
+	roundNumber, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round number"})
+		return
+	}
+
+	// The body is optional
+	var req models.StartRoundClockRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	duration := int(roundclock.DefaultDuration().Seconds())
+	if req.DurationMinutes > 0 {
+		duration = req.DurationMinutes * 60
+	}
+	extraTurns := roundclock.DefaultExtraTurns()
+	if req.ExtraTurns != nil {
+		extraTurns = *req.ExtraTurns
+	}
+
+	result, err := database.DB.ExecContext(ctx, `
+		INSERT INTO round_clocks (round_id, duration_seconds, extra_turns, running_since, started_at)
+		SELECT id, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		FROM rounds
+		WHERE round_number = $1
+		ON CONFLICT (round_id) DO NOTHING
+	`, roundNumber, duration, extraTurns)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start round clock"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var exists bool
+		if err := database.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM rounds WHERE round_number = $1)", roundNumber).Scan(&exists); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch round"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Round clock already started"})
+		return
+	}
+
+	respondRoundClock(c, roundNumber)
+}
+
+// PauseRoundClock stops the clock of a round, keeping the time left
+func PauseRoundClock(c *gin.Context) {
+	updateRoundClock(c, `
+		UPDATE round_clocks
+		SET elapsed_seconds = elapsed_seconds + EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - running_since)),
+			running_since = NULL
+		WHERE round_id = $1 AND running_since IS NOT NULL
+	`, "Round clock is not running")
+}
+
+// ResumeRoundClock restarts a paused round clock
+func ResumeRoundClock(c *gin.Context) {
+	updateRoundClock(c, `
+		UPDATE round_clocks
+		SET running_since = CURRENT_TIMESTAMP
+		WHERE round_id = $1 AND running_since IS NULL
+	`, "Round clock is not paused")
+}
+
+// ExtendRoundClock adds time to every match of a round. A round already in extra
+// turns gets back to regular time.
+func ExtendRoundClock(c *gin.Context) {
+	var req models.ExtendRoundClockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updateRoundClock(c, `
+		UPDATE round_clocks
+		SET extension_seconds = extension_seconds + $2
+		WHERE round_id = $1
+	`, "", req.Minutes*60)
+}
+
+// ResetRoundClock removes the clock of a round, so it can be started again
+func ResetRoundClock(c *gin.Context) {
+	updateRoundClock(c, "DELETE FROM round_clocks WHERE round_id = $1", "")
+}
+
+// updateRoundClock runs a statement on the started clock of the round in the URL.
+// If the statement changes nothing, the clock was not in the expected state and
+// conflict is returned as a 409.
+func updateRoundClock(c *gin.Context, statement, conflict string, args ...interface{}) {
+	ctx := c.Request.Context()
+	roundNumber, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round number"})
+		return
+	}
+
+	var roundID int
+	var started bool
+	err = database.DB.QueryRowContext(ctx, `
+		SELECT r.id, EXISTS (SELECT 1 FROM round_clocks rc WHERE rc.round_id = r.id)
+		FROM rounds r
+		WHERE r.round_number = $1
+	`, roundNumber).Scan(&roundID, &started)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch round"})
+		return
+	}
+	if !started {
+		c.JSON(http.StatusConflict, gin.H{"error": "Round clock has not been started"})
+		return
+	}
+
+	result, err := database.DB.ExecContext(ctx, statement, append([]interface{}{roundID}, args...)...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update round clock"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 && conflict != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+		return
+	}
+
+	respondRoundClock(c, roundNumber)
+}
+
+// AddMatchTimeExtension gives a single match extra time, e.g. after a judge call
+func AddMatchTimeExtension(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+
+	var req models.MatchTimeExtensionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var roundNumber int
+	var completed bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT r.round_number, m.completed
+		FROM matches m
+		JOIN rounds r ON m.round_id = r.id
+		WHERE m.id = $1
+		FOR UPDATE OF m
+	`, matchID).Scan(&roundNumber, &completed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch match"})
+		return
+	}
+	if completed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Match already has a result"})
+		return
+	}
+
+	if err := addMatchTimeExtension(ctx, tx, matchID, req.Minutes*60, req.Reason); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add time extension"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	respondRoundClock(c, roundNumber)
+}
+
+func addMatchTimeExtension(ctx context.Context, tx *sql.Tx, matchID, seconds int, reason *string) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO match_time_extensions (match_id, seconds, reason) VALUES ($1, $2, $3)",
+		matchID, seconds, reason,
+	)
+	return err
+}
+
+// respondRoundClock notifies the clock followers and returns the round clock
+func respondRoundClock(c *gin.Context, roundNumber int) {
+	ctx := c.Request.Context()
+	roundclock.Notify()
+
+	clock, err := roundclock.Load(ctx, roundNumber)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch round clock"})
+		return
+	}
+	c.JSON(http.StatusOK, clock)
+}
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+// respondRoundLocked rejects a result change in a locked round
+func respondRoundLocked(c *gin.Context, roundNumber int) {
+	c.JSON(http.StatusConflict, gin.H{
+		"error":        fmt.Sprintf("Round %d is locked; its results can only change through a correction", roundNumber),
+		"round_number": roundNumber,
+	})
+}
+
+// roundLockedError is returned when a result would change in a locked round
+type roundLockedError struct {
+	RoundNumber int
+}
+
+func (e *roundLockedError) Error() string {
+	return fmt.Sprintf("round %d is locked", e.RoundNumber)
+}
+
+// respondFixtureRound returns a round of the fixture with its matches and progress
+func respondFixtureRound(c *gin.Context, status, roundNumber int) {
+	ctx := c.Request.Context()
+	rounds, err := fetchFixture(ctx, database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fixture"})
+		return
+	}
+	for _, r := range rounds {
+		if r.Number == roundNumber {
+			c.JSON(status, r)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+}
+
+// enqueueFixtureRound loads a round changed in tx and queues event for it in tx.
+// Once tx is committed, the round is emitted and returned.
+func enqueueFixtureRound(c *gin.Context, tx *sql.Tx, roundNumber int, event string) (models.FixtureRound, bool) {
+	ctx := c.Request.Context()
+	rounds, err := fetchFixture(ctx, tx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fixture"})
+		return models.FixtureRound{}, false
+	}
+	for _, r := range rounds {
+		if r.Number == roundNumber {
+			if err := webhooks.Enqueue(ctx, tx, event, r); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
+				return models.FixtureRound{}, false
+			}
+			return r, true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+	return models.FixtureRound{}, false
+}
+
+// CreateRound pairs the next round of the running tournament, e.g. a Swiss round
+// paired from the current standings. The previous round must be complete; BYE is
+// created if a match needs it.
+func CreateRound(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req models.CreateRoundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// One round is paired at a time
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE rounds IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lock rounds"})
+		return
+	}
+
+	var lastRound int
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(round_number), 0) FROM rounds").Scan(&lastRound); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rounds"})
+		return
+	}
+	if req.RoundNumber <= lastRound {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Round %d already exists", req.RoundNumber)})
+		return
+	}
+	if req.RoundNumber != lastRound+1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The next round is round %d", lastRound+1)})
+		return
+	}
+	if lastRound > 0 {
+		var status string
+		var total, completed int
+		err := tx.QueryRowContext(ctx,
+			"SELECT status, total_matches, completed_matches FROM round_progress WHERE round_number = $1",
+			lastRound,
+		).Scan(&status, &total, &completed)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch round progress"})
+			return
+		}
+		if status != models.RoundStatusComplete && status != models.RoundStatusLocked {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":             fmt.Sprintf("Round %d is not complete yet (%d of %d matches have a result)", lastRound, completed, total),
+				"round_number":      lastRound,
+				"completed_matches": completed,
+				"total_matches":     total,
+			})
+			return
+		}
+	}
+
+	// Players by name; every player can only be paired once and must still be playing
+	playerIDs := make(map[string]int)
+	playerStatus := make(map[string]string)
+	rows, err := tx.QueryContext(ctx, "SELECT id, name, status FROM players")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch players"})
+		return
+	}
+	for rows.Next() {
+		var id int
+		var name, status string
+		if err := rows.Scan(&id, &name, &status); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan player"})
+			return
+		}
+		playerIDs[name] = id
+		playerStatus[name] = status
+	}
+	rows.Close()
+
+	paired := make(map[string]bool)
+	for _, m := range req.Matches {
+		if m.Player1Name == m.Player2Name {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A player cannot be paired against themselves"})
+			return
+		}
+		for _, name := range []string{m.Player1Name, m.Player2Name} {
+			if name == "BYE" {
+				continue
+			}
+			if _, ok := playerIDs[name]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Player not found: " + name})
+				return
+			}
+			if playerStatus[name] != models.PlayerStatusActive {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s is no longer playing (%s)", name, playerStatus[name])})
+				return
+			}
+			if paired[name] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " is paired more than once"})
+				return
+			}
+			paired[name] = true
+		}
+	}
+
+	// Create virtual BYE player if needed
+	if _, ok := playerIDs["BYE"]; !ok && needsBye(req.Matches) {
+		var byeID int
+		err := tx.QueryRowContext(ctx,
+			"INSERT INTO players (name, confirmed) VALUES ($1, $2) RETURNING id",
+			"BYE", false,
+		).Scan(&byeID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create BYE player"})
+			return
+		}
+		playerIDs["BYE"] = byeID
+	}
+
+	var roundID int
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO rounds (round_number, format) VALUES ($1, $2) RETURNING id",
+		req.RoundNumber, req.Format,
+	).Scan(&roundID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create round"})
+		return
+	}
+
+	for _, m := range req.Matches {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO matches (round_id, player1_id, player2_id) VALUES ($1, $2, $3)",
+			roundID, playerIDs[m.Player1Name], playerIDs[m.Player2Name],
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create match"})
+			return
+		}
+	}
+
+	if err := assignRoundTables(ctx, tx, roundID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign tables"})
+		return
+	}
+
+	round, ok := enqueueFixtureRound(c, tx, req.RoundNumber, webhooks.EventRoundCreated)
+	if !ok {
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	webhooks.Emit(webhooks.EventRoundCreated, round)
+	c.JSON(http.StatusCreated, round)
+}
+
+func needsBye(matches []models.RoundPairing) bool {
+	for _, m := range matches {
+		if m.Player1Name == "BYE" || m.Player2Name == "BYE" {
+			return true
+		}
+	}
+	return false
+}
+
+// LockRound closes a complete round: its scores can no longer be entered or changed
+// by penalties, only corrected
+func LockRound(c *gin.Context) {
+	ctx := c.Request.Context()
+	roundNumber, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round number"})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Score updates hold a share lock on their round until they commit
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM rounds WHERE round_number = $1 FOR UPDATE", roundNumber); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lock round"})
+		return
+	}
+
+	var status string
+	var total, completed int
+	err = tx.QueryRowContext(ctx,
+		"SELECT status, total_matches, completed_matches FROM round_progress WHERE round_number = $1",
+		roundNumber,
+	).Scan(&status, &total, &completed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch round progress"})
+		return
+	}
+	switch status {
+	case models.RoundStatusLocked:
+		c.JSON(http.StatusConflict, gin.H{"error": "Round is already locked"})
+		return
+	case models.RoundStatusPending, models.RoundStatusInProgress:
+		c.JSON(http.StatusConflict, gin.H{
+			"error":             fmt.Sprintf("Round %d is not complete yet (%d of %d matches have a result)", roundNumber, completed, total),
+			"completed_matches": completed,
+			"total_matches":     total,
+		})
+		return
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE rounds SET locked_at = CURRENT_TIMESTAMP WHERE round_number = $1", roundNumber); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lock round"})
+		return
+	}
+
+	round, ok := enqueueFixtureRound(c, tx, roundNumber, webhooks.EventRoundLocked)
+	if !ok {
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	webhooks.Emit(webhooks.EventRoundLocked, round)
+	c.JSON(http.StatusOK, round)
+}
+
+// UnlockRound opens a locked round again, e.g. to replay a match
+func UnlockRound(c *gin.Context) {
+	ctx := c.Request.Context()
+	roundNumber, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round number"})
+		return
+	}
+
+	result, err := database.DB.ExecContext(ctx,
+		"UPDATE rounds SET locked_at = NULL WHERE round_number = $1 AND locked_at IS NOT NULL",
+		roundNumber,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock round"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var exists bool
+		if err := database.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM rounds WHERE round_number = $1)", roundNumber).Scan(&exists); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch round"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Round is not locked"})
+		return
+	}
+
+	respondFixtureRound(c, http.StatusOK, roundNumber)
+}
@@ -0,0 +1,532 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// defaultSeasonPointsTable is used when a season is created without its own points table
+var defaultSeasonPointsTable = []int{10, 8, 6, 5, 4, 3, 2, 1}
+
+const defaultQualifierSlots = 8
+
+// CreateSeason creates a new season, optionally attaching tournaments to it
+func CreateSeason(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req models.CreateSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pointsTable := req.PointsTable
+	if len(pointsTable) == 0 {
+		pointsTable = defaultSeasonPointsTable
+	}
+	if err := validatePointsTable(pointsTable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	qualifierSlots := defaultQualifierSlots
+	if req.QualifierSlots != nil {
+		qualifierSlots = *req.QualifierSlots
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var seasonID int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO seasons (name, year, points_table, participation_points, best_n_results, qualifier_slots)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, req.Name, req.Year, pq.Array(pointsTable), req.ParticipationPoints, req.BestNResults, qualifierSlots).Scan(&seasonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create season"})
+		return
+	}
+
+	for _, tournamentID := range req.TournamentIDs {
+		if err := addTournamentToSeason(ctx, tx, seasonID, tournamentID); err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Tournament with ID %d not found", tournamentID)})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add tournament to season"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":           "Season created successfully",
+		"season_id":         seasonID,
+		"tournaments_added": len(req.TournamentIDs),
+	})
+}
+
+// GetSeasons returns all seasons, newest first
+func GetSeasons(c *gin.Context) {
+	ctx := c.Request.Context()
+	query := `
+		SELECT id, name, year, points_table, participation_points, best_n_results, qualifier_slots, created_at, updated_at
+		FROM seasons
+		ORDER BY year DESC, id DESC
+	`
+
+	rows, err := database.DB.QueryContext(ctx, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch seasons"})
+		return
+	}
+	defer rows.Close()
+
+	seasons := []models.Season{}
+	for rows.Next() {
+		s, err := scanSeason(rows)
+		if err != nil {
+			continue
+		}
+		seasons = append(seasons, s)
+	}
+
+	c.JSON(http.StatusOK, seasons)
+}
+
+// GetSeason returns a season with its configuration and tournaments
+func GetSeason(c *gin.Context) {
+	ctx := c.Request.Context()
+	seasonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+		return
+	}
+
+	season, err := fetchSeason(ctx, seasonID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch season"})
+		return
+	}
+
+	tournaments, err := fetchSeasonTournaments(ctx, seasonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch season tournaments"})
+		return
+	}
+	season.Tournaments = tournaments
+
+	c.JSON(http.StatusOK, season)
+}
+
+// UpdateSeason updates the name and scoring configuration of a season
+func UpdateSeason(c *gin.Context) {
+	ctx := c.Request.Context()
+	seasonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+		return
+	}
+
+	var req models.UpdateSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	season, err := fetchSeason(ctx, seasonID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch season"})
+		return
+	}
+
+	if req.Name != nil {
+		season.Name = *req.Name
+	}
+	if req.PointsTable != nil {
+		if err := validatePointsTable(req.PointsTable); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		season.PointsTable = req.PointsTable
+	}
+	if req.ParticipationPoints != nil {
+		season.ParticipationPoints = *req.ParticipationPoints
+	}
+	if req.BestNResults != nil {
+		if *req.BestNResults == 0 {
+			season.BestNResults = nil
+		} else {
+			season.BestNResults = req.BestNResults
+		}
+	}
+	if req.QualifierSlots != nil {
+		season.QualifierSlots = *req.QualifierSlots
+	}
+
+	_, err = database.DB.ExecContext(ctx, `
+		UPDATE seasons
+		SET name = $1, points_table = $2, participation_points = $3, best_n_results = $4, qualifier_slots = $5
+		WHERE id = $6
+	`, season.Name, pq.Array(season.PointsTable), season.ParticipationPoints, season.BestNResults, season.QualifierSlots, seasonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update season"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Season updated successfully"})
+}
+
+// DeleteSeason deletes a season; its tournaments are left untouched
+func DeleteSeason(c *gin.Context) {
+	ctx := c.Request.Context()
+	seasonID := c.Param("id")
+
+	result, err := database.DB.ExecContext(ctx, "DELETE FROM seasons WHERE id = $1", seasonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete season"})
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Season deleted successfully"})
+}
+
+// AddSeasonTournament attaches an existing tournament (in-person or online) to a season
+func AddSeasonTournament(c *gin.Context) {
+	ctx := c.Request.Context()
+	seasonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+		return
+	}
+
+	var req models.AddSeasonTournamentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM seasons WHERE id = $1)", seasonID).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check season existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+		return
+	}
+
+	if err := addTournamentToSeason(ctx, tx, seasonID, req.TournamentID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add tournament to season"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tournament added to season successfully"})
+}
+
+// RemoveSeasonTournament detaches a tournament from a season
+func RemoveSeasonTournament(c *gin.Context) {
+	ctx := c.Request.Context()
+	seasonID := c.Param("id")
+	tournamentID := c.Param("tournament_id")
+
+	result, err := database.DB.ExecContext(ctx,
+		"DELETE FROM season_tournaments WHERE season_id = $1 AND tournament_id = $2",
+		seasonID, tournamentID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove tournament from season"})
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament is not part of this season"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tournament removed from season successfully"})
+}
+
+// GetSeasonLeaderboard returns the circuit leaderboard for a season and who qualifies for the finals
+func GetSeasonLeaderboard(c *gin.Context) {
+	ctx := c.Request.Context()
+	seasonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+		return
+	}
+
+	season, err := fetchSeason(ctx, seasonID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch season"})
+		return
+	}
+
+	// Final positions per tournament. Archived tournaments use the frozen
+	// tournament_standings; online tournaments that have not been frozen yet
+	// are ranked from the live online standings view.
+	// Players are matched by name, as in the rest of the history endpoints.
+	query := `
+		SELECT t.id, t.name, ts.player_name, ts.final_position
+		FROM season_tournaments st
+		JOIN tournaments t ON t.id = st.tournament_id
+		JOIN tournament_standings ts ON ts.tournament_id = t.id
+		WHERE st.season_id = $1 AND ts.final_position IS NOT NULL
+
+		UNION ALL
+
+		SELECT t.id, t.name, ots.player_name,
+			ROW_NUMBER() OVER (PARTITION BY ots.tournament_id ORDER BY ots.points DESC, ots.wins DESC)::INTEGER
+		FROM season_tournaments st
+		JOIN tournaments t ON t.id = st.tournament_id AND t.type = 'ONLINE'
+		JOIN online_tournament_standings ots ON ots.tournament_id = t.id
+		WHERE st.season_id = $1
+			AND NOT EXISTS (SELECT 1 FROM tournament_standings ts2 WHERE ts2.tournament_id = t.id)
+	`
+
+	rows, err := database.DB.QueryContext(ctx, query, seasonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch season results"})
+		return
+	}
+	defer rows.Close()
+
+	entries := make(map[string]*models.SeasonLeaderboardEntry)
+	for rows.Next() {
+		var r models.SeasonResult
+		var playerName string
+		if err := rows.Scan(&r.TournamentID, &r.TournamentName, &playerName, &r.FinalPosition); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error scanning season results"})
+			return
+		}
+		if playerName == "BYE" {
+			continue
+		}
+		r.Points = seasonPointsForPosition(season, r.FinalPosition)
+
+		entry, exists := entries[playerName]
+		if !exists {
+			entry = &models.SeasonLeaderboardEntry{PlayerName: playerName, Results: []models.SeasonResult{}}
+			entries[playerName] = entry
+		}
+		entry.Results = append(entry.Results, r)
+	}
+
+	leaderboard := buildSeasonLeaderboard(season, entries)
+
+	c.JSON(http.StatusOK, models.SeasonLeaderboardResponse{
+		SeasonID:       season.ID,
+		SeasonName:     season.Name,
+		BestNResults:   season.BestNResults,
+		QualifierSlots: season.QualifierSlots,
+		Leaderboard:    leaderboard,
+	})
+}
+
+// buildSeasonLeaderboard applies best-N counting, sorts the players and marks the qualifiers
+func buildSeasonLeaderboard(season models.Season, entries map[string]*models.SeasonLeaderboardEntry) []models.SeasonLeaderboardEntry {
+	leaderboard := make([]models.SeasonLeaderboardEntry, 0, len(entries))
+	for _, entry := range entries {
+		// Best results first so the first N are the ones that count
+		sort.SliceStable(entry.Results, func(i, j int) bool {
+			if entry.Results[i].Points != entry.Results[j].Points {
+				return entry.Results[i].Points > entry.Results[j].Points
+			}
+			return entry.Results[i].FinalPosition < entry.Results[j].FinalPosition
+		})
+
+		entry.TournamentsPlayed = len(entry.Results)
+		for i := range entry.Results {
+			if entry.BestFinish == 0 || entry.Results[i].FinalPosition < entry.BestFinish {
+				entry.BestFinish = entry.Results[i].FinalPosition
+			}
+			if season.BestNResults == nil || i < *season.BestNResults {
+				entry.Results[i].Counted = true
+				entry.TotalPoints += entry.Results[i].Points
+			}
+		}
+		leaderboard = append(leaderboard, *entry)
+	}
+
+	// Tiebreakers: total points, best single finish, tournaments played, name
+	sort.Slice(leaderboard, func(i, j int) bool {
+		a, b := leaderboard[i], leaderboard[j]
+		if a.TotalPoints != b.TotalPoints {
+			return a.TotalPoints > b.TotalPoints
+		}
+		if a.BestFinish != b.BestFinish {
+			return a.BestFinish < b.BestFinish
+		}
+		if a.TournamentsPlayed != b.TournamentsPlayed {
+			return a.TournamentsPlayed > b.TournamentsPlayed
+		}
+		return a.PlayerName < b.PlayerName
+	})
+
+	for i := range leaderboard {
+		leaderboard[i].Position = i + 1
+		leaderboard[i].Qualified = i < season.QualifierSlots
+	}
+
+	return leaderboard
+}
+
+// seasonPointsForPosition returns the circuit points earned for a final position
+func seasonPointsForPosition(season models.Season, position int) int {
+	points := season.ParticipationPoints
+	if position >= 1 && position <= len(season.PointsTable) {
+		points += season.PointsTable[position-1]
+	}
+	return points
+}
+
+func validatePointsTable(pointsTable []int) error {
+	for i, points := range pointsTable {
+		if points < 0 {
+			return fmt.Errorf("points_table entry for position %d must not be negative", i+1)
+		}
+	}
+	return nil
+}
+
+// addTournamentToSeason links a tournament to a season, returning sql.ErrNoRows if the tournament doesn't exist
+func addTournamentToSeason(ctx context.Context, tx *sql.Tx, seasonID, tournamentID int) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM tournaments WHERE id = $1)", tournamentID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO season_tournaments (season_id, tournament_id)
+		VALUES ($1, $2)
+		ON CONFLICT (season_id, tournament_id) DO NOTHING
+	`, seasonID, tournamentID)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSeason(row rowScanner) (models.Season, error) {
+	var s models.Season
+	var pointsTable pq.Int64Array
+	var bestN sql.NullInt64
+	err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&s.Year,
+		&pointsTable,
+		&s.ParticipationPoints,
+		&bestN,
+		&s.QualifierSlots,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return s, err
+	}
+
+	s.PointsTable = make([]int, len(pointsTable))
+	for i, p := range pointsTable {
+		s.PointsTable[i] = int(p)
+	}
+	if bestN.Valid {
+		n := int(bestN.Int64)
+		s.BestNResults = &n
+	}
+	return s, nil
+}
+
+func fetchSeason(ctx context.Context, seasonID int) (models.Season, error) {
+	row := database.DB.QueryRowContext(ctx, `
+		SELECT id, name, year, points_table, participation_points, best_n_results, qualifier_slots, created_at, updated_at
+		FROM seasons
+		WHERE id = $1
+	`, seasonID)
+	return scanSeason(row)
+}
+
+func fetchSeasonTournaments(ctx context.Context, seasonID int) ([]models.SeasonTournament, error) {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT t.id, t.name, t.month, t.year, t.type, t.format
+		FROM season_tournaments st
+		JOIN tournaments t ON t.id = st.tournament_id
+		WHERE st.season_id = $1
+		ORDER BY COALESCE(t.start_date, t.created_at::date), t.id
+	`, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tournaments := []models.SeasonTournament{}
+	for rows.Next() {
+		var t models.SeasonTournament
+		var format sql.NullString
+		if err := rows.Scan(&t.TournamentID, &t.Name, &t.Month, &t.Year, &t.Type, &format); err != nil {
+			return nil, err
+		}
+		if format.Valid {
+			t.Format = &format.String
+		}
+		tournaments = append(tournaments, t)
+	}
+	return tournaments, rows.Err()
+}
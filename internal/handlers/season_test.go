@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+)
+
+func TestBuildSeasonLeaderboard(t *testing.T) {
+	// Final positions of every player, one per tournament
+	finishes := map[string][]int{
+		"Ana":   {3, 1, 2},
+		"Bruno": {2, 1},
+		"Carla": {4, 4},
+		"Ariel": {4, 4},
+	}
+	bestTwo := 2
+
+	type row struct {
+		Name      string
+		Total     int
+		Best      int
+		Played    int
+		Qualified bool
+		Counted   []int // final positions of the counted results
+	}
+	tests := []struct {
+		name         string
+		bestNResults *int
+		want         []row
+	}{
+		{
+			name:         "best two results",
+			bestNResults: &bestTwo,
+			want: []row{
+				// Ana and Bruno tie on points and best finish; Ana played more
+				{"Ana", 18, 1, 3, true, []int{1, 2}},
+				{"Bruno", 18, 1, 2, true, []int{1, 2}},
+				// Same results, ordered by name
+				{"Ariel", 2, 4, 2, false, []int{4, 4}},
+				{"Carla", 2, 4, 2, false, []int{4, 4}},
+			},
+		},
+		{
+			name: "every result counts",
+			want: []row{
+				{"Ana", 22, 1, 3, true, []int{1, 2, 3}},
+				{"Bruno", 18, 1, 2, true, []int{1, 2}},
+				{"Ariel", 2, 4, 2, false, []int{4, 4}},
+				{"Carla", 2, 4, 2, false, []int{4, 4}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			season := models.Season{
+				PointsTable:         []int{10, 6, 3},
+				ParticipationPoints: 1,
+				BestNResults:        tt.bestNResults,
+				QualifierSlots:      2,
+			}
+			entries := make(map[string]*models.SeasonLeaderboardEntry)
+			for name, positions := range finishes {
+				entry := &models.SeasonLeaderboardEntry{PlayerName: name}
+				for i, position := range positions {
+					entry.Results = append(entry.Results, models.SeasonResult{
+						TournamentID:  i + 1,
+						FinalPosition: position,
+						Points:        seasonPointsForPosition(season, position),
+					})
+				}
+				entries[name] = entry
+			}
+
+			leaderboard := buildSeasonLeaderboard(season, entries)
+
+			var got []row
+			for i, e := range leaderboard {
+				if e.Position != i+1 {
+					t.Errorf("%s is at index %d with position %d", e.PlayerName, i, e.Position)
+				}
+				r := row{e.PlayerName, e.TotalPoints, e.BestFinish, e.TournamentsPlayed, e.Qualified, nil}
+				for _, result := range e.Results {
+					if result.Counted {
+						r.Counted = append(r.Counted, result.FinalPosition)
+					}
+				}
+				got = append(got, r)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildSeasonLeaderboard() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeasonPointsForPosition(t *testing.T) {
+	season := models.Season{PointsTable: []int{10, 6, 3}, ParticipationPoints: 1}
+	for position, want := range map[int]int{1: 11, 3: 4, 4: 1, 0: 1} {
+		if got := seasonPointsForPosition(season, position); got != want {
+			t.Errorf("seasonPointsForPosition(%d) = %d, want %d", position, got, want)
+		}
+	}
+}
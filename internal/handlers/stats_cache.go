@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/cache"
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+)
+
+// statsCacheTag tags the cached responses built from the archived tournaments
+const statsCacheTag = "stats"
+
+// statsViews are the materialized views of the archived tournaments
+var statsViews = []string{"global_player_stats", "global_race_stats"}
+
+// statsDirty holds a pending refresh of the statistics
+var statsDirty = make(chan struct{}, 1)
+
+// statsChanged marks the statistics stale after a committed change to the archive.
+// The views are refreshed in the background, once for changes close together, and
+// the cached responses dropped after; until then the previous statistics are served.
+func statsChanged() {
+	select {
+	case statsDirty <- struct{}{}:
+	default:
+	}
+}
+
+// StartStatsRefresher refreshes the statistics once, for changes made while the
+// server was down, and then after every change until ctx is done
+func StartStatsRefresher(ctx context.Context) {
+	statsChanged()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-statsDirty:
+				if err := RefreshStats(ctx); err != nil {
+					slog.Error("failed to refresh statistics", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// RefreshStats recomputes the statistics views and drops the cached responses. The
+// views stay readable while they are refreshed.
+func RefreshStats(ctx context.Context) error {
+	for _, view := range statsViews {
+		if _, err := database.DB.ExecContext(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY "+view); err != nil {
+			return err
+		}
+	}
+	cache.Invalidate(ctx, statsCacheTag)
+	return nil
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// seatedMatch is a match of a round waiting for a table
+type seatedMatch struct {
+	ID          int
+	Bye         bool
+	Position    int   // best standings position of its two players
+	FixedTables []int // fixed tables of its players, if any
+}
+
+// seatMatches gives every match a table number. Matches with a player who has a fixed
+// table sit there; the rest fill the free tables from 1 up, the best standings first.
+// When two matches want the same fixed table, the one higher in the standings gets it.
+// Matches against BYE get no table.
+func seatMatches(matches []seatedMatch) map[int]int {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Position < matches[j].Position
+	})
+
+	tables := make(map[int]int)
+	taken := make(map[int]bool)
+	for _, m := range matches {
+		if m.Bye {
+			continue
+		}
+		for _, t := range m.FixedTables {
+			if !taken[t] {
+				taken[t] = true
+				tables[m.ID] = t
+				break
+			}
+		}
+	}
+
+	next := 1
+	for _, m := range matches {
+		if _, seated := tables[m.ID]; seated || m.Bye {
+			continue
+		}
+		for taken[next] {
+			next++
+		}
+		taken[next] = true
+		tables[m.ID] = next
+	}
+	return tables
+}
+
+// assignRoundTables (re)numbers the tables of a round from the current standings
+func assignRoundTables(ctx context.Context, tx *sql.Tx, roundID int) error {
+	rows, err := tx.QueryContext(ctx, `
+		WITH ranking AS (
+			SELECT id, RANK() OVER (ORDER BY points DESC, total_points_scored DESC) AS position
+			FROM standings
+		)
+		SELECT
+			m.id,
+			p1.name = 'BYE' OR p2.name = 'BYE',
+			LEAST(COALESCE(r1.position, 2147483647), COALESCE(r2.position, 2147483647)),
+			p1.fixed_table,
+			p2.fixed_table
+		FROM matches m
+		JOIN players p1 ON m.player1_id = p1.id
+		JOIN players p2 ON m.player2_id = p2.id
+		LEFT JOIN ranking r1 ON r1.id = p1.id
+		LEFT JOIN ranking r2 ON r2.id = p2.id
+		WHERE m.round_id = $1
+		ORDER BY m.id
+	`, roundID)
+	if err != nil {
+		return err
+	}
+
+	var matches []seatedMatch
+	for rows.Next() {
+		var m seatedMatch
+		var fixed1, fixed2 sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.Bye, &m.Position, &fixed1, &fixed2); err != nil {
+			rows.Close()
+			return err
+		}
+		for _, f := range []sql.NullInt64{fixed1, fixed2} {
+			if f.Valid {
+				m.FixedTables = append(m.FixedTables, int(f.Int64))
+			}
+		}
+		sort.Ints(m.FixedTables)
+		matches = append(matches, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Clear first so the unique (round, table) index does not trip while renumbering
+	if _, err := tx.ExecContext(ctx, "UPDATE matches SET table_number = NULL WHERE round_id = $1", roundID); err != nil {
+		return err
+	}
+	for matchID, table := range seatMatches(matches) {
+		if _, err := tx.ExecContext(ctx, "UPDATE matches SET table_number = $1 WHERE id = $2", table, matchID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seatNextRound seats the round after the one of matchID once every match of that
+// round has a result, as long as the next round has not started
+func seatNextRound(ctx context.Context, tx *sql.Tx, matchID int) error {
+	var nextRoundID int
+	err := tx.QueryRowContext(ctx, `
+		SELECT nr.id
+		FROM matches m
+		JOIN rounds r ON m.round_id = r.id
+		JOIN rounds nr ON nr.round_number = r.round_number + 1
+		WHERE m.id = $1
+		  AND NOT EXISTS (SELECT 1 FROM matches pm WHERE pm.round_id = r.id AND pm.completed = false)
+		  AND NOT EXISTS (SELECT 1 FROM matches nm WHERE nm.round_id = nr.id AND nm.completed = true)
+	`, matchID).Scan(&nextRoundID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return assignRoundTables(ctx, tx, nextRoundID)
+}
+
+// seatLateMatch gives a table to a match that became playable after the round was
+// seated (a late entry taking over a BYE): a fixed table of its players if it is
+// free, otherwise the lowest free table
+func seatLateMatch(ctx context.Context, tx *sql.Tx, roundID, matchID int) error {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT table_number FROM matches WHERE round_id = $1 AND id <> $2 AND table_number IS NOT NULL",
+		roundID, matchID,
+	)
+	if err != nil {
+		return err
+	}
+	taken := make(map[int]bool)
+	for rows.Next() {
+		var t int
+		if err := rows.Scan(&t); err != nil {
+			rows.Close()
+			return err
+		}
+		taken[t] = true
+	}
+	rows.Close()
+
+	var fixed1, fixed2 sql.NullInt64
+	err = tx.QueryRowContext(ctx, `
+		SELECT p1.fixed_table, p2.fixed_table
+		FROM matches m
+		JOIN players p1 ON m.player1_id = p1.id
+		JOIN players p2 ON m.player2_id = p2.id
+		WHERE m.id = $1
+	`, matchID).Scan(&fixed1, &fixed2)
+	if err != nil {
+		return err
+	}
+
+	table := 0
+	for _, f := range []sql.NullInt64{fixed1, fixed2} {
+		if f.Valid && !taken[int(f.Int64)] {
+			table = int(f.Int64)
+			break
+		}
+	}
+	if table == 0 {
+		table = 1
+		for taken[table] {
+			table++
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE matches SET table_number = $1 WHERE id = $2", table, matchID)
+	return err
+}
+
+// AssignRoundTables renumbers the tables of a round from the current standings and
+// fixed seating, e.g. after changing a fixed table or correcting a result
+func AssignRoundTables(c *gin.Context) {
+	ctx := c.Request.Context()
+	roundNumber, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid round number"})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var roundID int
+	var started bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT r.id, EXISTS (SELECT 1 FROM matches m WHERE m.round_id = r.id AND m.completed = true)
+		FROM rounds r
+		WHERE r.round_number = $1
+	`, roundNumber).Scan(&roundID, &started)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch round"})
+		return
+	}
+	if started {
+		c.JSON(http.StatusConflict, gin.H{"error": "Round already has results, tables can no longer change"})
+		return
+	}
+
+	if err := assignRoundTables(ctx, tx, roundID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign tables"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	respondFixtureRound(c, http.StatusOK, roundNumber)
+}
+
+// SetPlayerFixedTable gives a player the same table in every round (accessibility
+// seating), or clears it with null. Rounds already seated keep their tables until
+// they are reassigned.
+func SetPlayerFixedTable(c *gin.Context) {
+	ctx := c.Request.Context()
+	playerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return
+	}
+
+	var req models.SetFixedTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := `
+		UPDATE players
+		SET fixed_table = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING id, name, confirmed, status, late_entry, fixed_table, created_at, updated_at
+	`
+
+	var player models.Player
+	err = database.DB.QueryRowContext(ctx, query, req.FixedTable, playerID).Scan(
+		&player.ID,
+		&player.Name,
+		&player.Confirmed,
+		&player.Status,
+		&player.LateEntry,
+		&player.FixedTable,
+		&player.CreatedAt,
+		&player.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player"})
+		return
+	}
+
+	c.JSON(http.StatusOK, player)
+}
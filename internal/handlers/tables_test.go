@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeatMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		matches []seatedMatch
+		want    map[int]int // match ID -> table
+	}{
+		{
+			name: "standings order",
+			matches: []seatedMatch{
+				{ID: 1, Position: 5},
+				{ID: 2, Position: 1},
+				{ID: 3, Position: 3},
+			},
+			want: map[int]int{2: 1, 3: 2, 1: 3},
+		},
+		{
+			name: "fixed table is skipped by the others",
+			matches: []seatedMatch{
+				{ID: 1, Position: 1},
+				{ID: 2, Position: 2, FixedTables: []int{1}},
+				{ID: 3, Position: 3},
+			},
+			want: map[int]int{2: 1, 1: 2, 3: 3},
+		},
+		{
+			name: "fixed table beyond the round",
+			matches: []seatedMatch{
+				{ID: 1, Position: 1},
+				{ID: 2, Position: 2, FixedTables: []int{7}},
+			},
+			want: map[int]int{1: 1, 2: 7},
+		},
+		{
+			name: "same fixed table goes to the better standing",
+			matches: []seatedMatch{
+				{ID: 1, Position: 4, FixedTables: []int{2}},
+				{ID: 2, Position: 1, FixedTables: []int{2}},
+			},
+			want: map[int]int{2: 2, 1: 1},
+		},
+		{
+			name: "second fixed table of a match",
+			matches: []seatedMatch{
+				{ID: 1, Position: 1, FixedTables: []int{3}},
+				{ID: 2, Position: 2, FixedTables: []int{3, 4}},
+			},
+			want: map[int]int{1: 3, 2: 4},
+		},
+		{
+			name: "bye gets no table",
+			matches: []seatedMatch{
+				{ID: 1, Position: 1, Bye: true, FixedTables: []int{1}},
+				{ID: 2, Position: 2},
+			},
+			want: map[int]int{2: 1},
+		},
+		{
+			name: "no matches",
+			want: map[int]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seatMatches(tt.matches); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("seatMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
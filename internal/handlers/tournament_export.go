@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/tournamentio"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize limits the size of an uploaded tournament bundle
+const maxImportSize = 10 << 20
+
+// ExportTournament returns a self-contained bundle of a tournament, as JSON (default)
+// or as a zip archive with one CSV file per table (?format=csv)
+func ExportTournament(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	bundle, err := tournamentio.Export(ctx, tournamentID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export tournament: " + err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"tournament-%d.json\"", tournamentID))
+		c.JSON(http.StatusOK, bundle)
+	case "csv":
+		var buf bytes.Buffer
+		if err := tournamentio.WriteZip(&buf, bundle); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write CSV archive"})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"tournament-%d.zip\"", tournamentID))
+		c.Data(http.StatusOK, "application/zip", buf.Bytes())
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+	}
+}
+
+// ImportTournament recreates a tournament from an export bundle. The bundle can be sent
+// as a JSON body, as a zip body (Content-Type: application/zip) or as a multipart "file".
+// ?create_players=true adds unknown players to premier_players; ?allow_duplicate=true
+// imports even if a tournament with the same name, month, year and type exists.
+func ImportTournament(c *gin.Context) {
+	ctx := c.Request.Context()
+	bundle, err := readImportBundle(c)
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+
+	opts := tournamentio.Options{
+		CreateMissingPlayers: c.Query("create_players") == "true",
+		AllowDuplicate:       c.Query("allow_duplicate") == "true",
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tournamentio.Import(ctx, tx, bundle, opts)
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit tournament import"})
+		return
+	}
+	statsChanged()
+
+	c.JSON(http.StatusCreated, models.TournamentImportResponse{
+		Message:        "Tournament imported successfully",
+		TournamentID:   result.TournamentID,
+		PlayersMatched: result.PlayersMatched,
+		PlayersCreated: result.PlayersCreated,
+		Rounds:         result.Rounds,
+		Matches:        result.Matches,
+	})
+}
+
+// readImportBundle decodes the bundle from a JSON or zip body, or from a multipart upload
+func readImportBundle(c *gin.Context) (*models.TournamentBundle, error) {
+	var data []byte
+	var filename string
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return nil, &tournamentio.ValidationError{Problems: []string{"file is required"}}
+		}
+		if file.Size > maxImportSize {
+			return nil, &tournamentio.ValidationError{Problems: []string{"file is too large"}}
+		}
+		f, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if data, err = io.ReadAll(f); err != nil {
+			return nil, err
+		}
+		filename = strings.ToLower(file.Filename)
+	} else {
+		var err error
+		data, err = io.ReadAll(io.LimitReader(c.Request.Body, maxImportSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxImportSize {
+			return nil, &tournamentio.ValidationError{Problems: []string{"body is too large"}}
+		}
+	}
+
+	if c.ContentType() == "application/zip" || strings.HasSuffix(filename, ".zip") {
+		return tournamentio.ReadZip(data)
+	}
+
+	var bundle models.TournamentBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, &tournamentio.ValidationError{Problems: []string{"invalid JSON: " + err.Error()}}
+	}
+	return &bundle, nil
+}
+
+func respondImportError(c *gin.Context, err error) {
+	var validationErr *tournamentio.ValidationError
+	var unresolvedErr *tournamentio.UnresolvedPlayersError
+
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament bundle", "problems": validationErr.Problems})
+	case errors.As(err, &unresolvedErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":           "Some players don't exist; use ?create_players=true to create them",
+			"unknown_players": unresolvedErr.Names,
+		})
+	case errors.Is(err, tournamentio.ErrDuplicateTournament):
+		c.JSON(http.StatusConflict, gin.H{"error": "Tournament already exists; use ?allow_duplicate=true to import it anyway"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import tournament: " + err.Error()})
+	}
+}
+
+// BulkImportTournaments imports historical in-person tournaments from spreadsheet CSV
+// files uploaded as multipart fields "standings", "pairings" and "races".
+// ?dry_run=true validates and reports without writing anything.
+func BulkImportTournaments(c *gin.Context) {
+	ctx := c.Request.Context()
+	var files tournamentio.BulkFiles
+	for field, target := range map[string]*io.Reader{
+		"standings": &files.Standings,
+		"pairings":  &files.Pairings,
+		"races":     &files.Races,
+	} {
+		file, err := c.FormFile(field)
+		if err == http.ErrMissingFile {
+			continue
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid upload: " + err.Error()})
+			return
+		}
+		if file.Size > maxImportSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": field + " file is too large"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read " + field + " file"})
+			return
+		}
+		defer f.Close()
+		*target = f
+	}
+
+	opts := tournamentio.BulkOptions{
+		CreateMissingPlayers: c.Query("create_players") == "true",
+		DryRun:               c.Query("dry_run") == "true",
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	report, err := tournamentio.BulkImport(ctx, tx, files, opts)
+	var validationErr *tournamentio.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, report)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import tournaments: " + err.Error()})
+		return
+	}
+
+	if opts.DryRun {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit tournament import"})
+		return
+	}
+	statsChanged()
+
+	c.JSON(http.StatusCreated, report)
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/apiv2"
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// tournamentColumns are the columns of tournaments t read by scanTournament
+const tournamentColumns = `t.id, t.name, t.month, TO_CHAR(t.month_start, 'YYYY-MM-DD'), t.year, t.type, t.format,
+	t.status, t.start_date, t.end_date, t.created_at, t.completed_at, t.archived_at`
+
+// scanTournament reads tournamentColumns, followed by the extra columns given
+func scanTournament(row rowScanner, extra ...interface{}) (models.Tournament, error) {
+	var t models.Tournament
+	dest := []interface{}{
+		&t.ID, &t.Name, &t.Month, &t.MonthStart, &t.Year, &t.Type, &t.Format,
+		&t.Status, &t.StartDate, &t.EndDate, &t.CreatedAt, &t.CompletedAt, &t.ArchivedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return t, err
+}
+
+// tournamentDate is the date the history is sorted and filtered by: the start date,
+// else the first day of the month
+const tournamentDate = `COALESCE(t.start_date, t.month_start, t.created_at::date)`
+
+// tournamentSort is a ?sort= option of the history
+type tournamentSort struct {
+	expr string // expression ordered by, before the ID
+	cast string // SQL type of the expression, for cursors
+	desc bool   // default direction
+}
+
+var tournamentSorts = map[string]tournamentSort{
+	"date": {expr: tournamentDate, cast: "date", desc: true},
+	"name": {expr: "LOWER(t.name)", cast: "text"},
+}
+
+// tournamentQuery is the filtered and sorted history asked by a request
+type tournamentQuery struct {
+	where []string
+	args  []interface{}
+	sort  string
+	desc  bool
+}
+
+// arg adds a query argument and returns its placeholder
+func (q *tournamentQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *tournamentQuery) whereClause() string {
+	return strings.Join(q.where, " AND ")
+}
+
+func (q *tournamentQuery) orderBy() string {
+	dir := " ASC"
+	if q.desc {
+		dir = " DESC"
+	}
+	return tournamentSorts[q.sort].expr + dir + ", t.id" + dir
+}
+
+// parseTournamentQuery reads the filters and sort of the history:
+//
+//	?status=completed|archived  (default both)
+//	?type=IN_PERSON|ONLINE
+//	?format=PB|BF               (online tournaments of the format, in-person ones with a round of it)
+//	?year=2025
+//	?from=2025-01-01&to=2025-12-31  (inclusive, on the start date or month)
+//	?player=Ana                 (tournaments in whose standings the player is)
+//	?q=copa                     (name contains, case-insensitive)
+//	?sort=date|name&order=asc|desc  (default date, newest first)
+func parseTournamentQuery(c *gin.Context) (*tournamentQuery, error) {
+	q := &tournamentQuery{sort: c.DefaultQuery("sort", "date")}
+
+	switch status := c.Query("status"); status {
+	case "":
+		q.where = append(q.where, "t.status IN ('completed', 'archived')")
+	case models.TournamentStatusCompleted, models.TournamentStatusArchived:
+		q.where = append(q.where, "t.status = "+q.arg(status))
+	default:
+		return nil, errors.New("status must be completed or archived")
+	}
+
+	if value := c.Query("type"); value != "" {
+		tournamentType := strings.ToUpper(value)
+		if tournamentType != "IN_PERSON" && tournamentType != "ONLINE" {
+			return nil, errors.New("type must be IN_PERSON or ONLINE")
+		}
+		q.where = append(q.where, "t.type = "+q.arg(tournamentType))
+	}
+
+	if value := c.Query("format"); value != "" {
+		format := strings.ToUpper(value)
+		if format != "PB" && format != "BF" {
+			return nil, errors.New("format must be PB or BF")
+		}
+		p := q.arg(format)
+		q.where = append(q.where, "(t.format = "+p+
+			" OR EXISTS (SELECT 1 FROM tournament_rounds tr WHERE tr.tournament_id = t.id AND tr.format = "+p+"))")
+	}
+
+	if value := c.Query("year"); value != "" {
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("year must be a number")
+		}
+		q.where = append(q.where, "t.year = "+q.arg(year))
+	}
+
+	for _, bound := range []struct{ name, op string }{{"from", ">="}, {"to", "<="}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return nil, errors.New(bound.name + " must be a date (YYYY-MM-DD)")
+		}
+		q.where = append(q.where, tournamentDate+" "+bound.op+" "+q.arg(value)+"::date")
+	}
+
+	if player := strings.TrimSpace(c.Query("player")); player != "" {
+		q.where = append(q.where, "EXISTS (SELECT 1 FROM tournament_standings ts WHERE ts.tournament_id = t.id AND LOWER(ts.player_name) = LOWER("+q.arg(player)+"))")
+	}
+
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(search)
+		q.where = append(q.where, "t.name ILIKE "+q.arg("%"+escaped+"%"))
+	}
+
+	sort, ok := tournamentSorts[q.sort]
+	if !ok {
+		return nil, errors.New("sort must be date or name")
+	}
+	q.desc = sort.desc
+	switch c.Query("order") {
+	case "":
+	case "asc":
+		q.desc = false
+	case "desc":
+		q.desc = true
+	default:
+		return nil, errors.New("order must be asc or desc")
+	}
+	return q, nil
+}
+
+// GetTournaments returns the finished (completed or archived) tournaments, newest
+// first by default. See parseTournamentQuery for the filters and sorts; ?limit= and
+// ?offset= page the list, with the number of matching tournaments in X-Total-Count.
+func GetTournaments(c *gin.Context) {
+	ctx := c.Request.Context()
+	q, err := parseTournamentQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := `SELECT ` + tournamentColumns + `, COUNT(*) OVER()
+		FROM tournaments t
+		WHERE ` + q.whereClause() + `
+		ORDER BY ` + q.orderBy()
+	filterArgs := len(q.args)
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		query += " LIMIT " + q.arg(limit)
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+			return
+		}
+		query += " OFFSET " + q.arg(offset)
+	}
+
+	rows, err := database.DB.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournaments"})
+		return
+	}
+	defer rows.Close()
+
+	tournaments := make([]models.Tournament, 0)
+	total := 0
+	for rows.Next() {
+		t, err := scanTournament(rows, &total)
+		if err != nil {
+			continue
+		}
+		tournaments = append(tournaments, t)
+	}
+
+	// A page past the end has no row to carry the count
+	if len(tournaments) == 0 && c.Query("offset") != "" {
+		err := database.DB.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM tournaments t WHERE "+q.whereClause(),
+			q.args[:filterArgs]...,
+		).Scan(&total)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tournaments"})
+			return
+		}
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.JSON(http.StatusOK, tournaments)
+}
+
+// tournamentCursor is the position of a tournament in a sorted history
+type tournamentCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// GetTournamentsV2 returns a page of the finished tournaments, with the filters and
+// sorts of GetTournaments
+func GetTournamentsV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	page, ok := apiv2.ParsePage(c)
+	if !ok {
+		return
+	}
+	q, err := parseTournamentQuery(c)
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	sort := tournamentSorts[q.sort]
+	if page.Cursor != "" {
+		var after tournamentCursor
+		if err := apiv2.DecodeCursor(page.Cursor, &after); err != nil || after.Sort != q.sort || after.Desc != q.desc {
+			apiv2.Fail(c, apiv2.CodeInvalidRequest, "Invalid cursor for this sort")
+			return
+		}
+		op := " > "
+		if q.desc {
+			op = " < "
+		}
+		q.where = append(q.where, "("+sort.expr+", t.id)"+op+"("+q.arg(after.Value)+"::"+sort.cast+", "+q.arg(after.ID)+")")
+	}
+
+	query := `SELECT ` + tournamentColumns + `, (` + sort.expr + `)::text
+		FROM tournaments t
+		WHERE ` + q.whereClause() + `
+		ORDER BY ` + q.orderBy() + `
+		LIMIT ` + q.arg(page.Limit+1)
+
+	rows, err := database.DB.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to fetch tournaments")
+		return
+	}
+	defer rows.Close()
+
+	tournaments := []models.Tournament{}
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		t, err := scanTournament(rows, &key)
+		if err != nil {
+			apiv2.Fail(c, apiv2.CodeInternal, "Failed to read tournaments")
+			return
+		}
+		tournaments = append(tournaments, t)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to read tournaments")
+		return
+	}
+
+	meta := apiv2.Meta{Limit: page.Limit}
+	if len(tournaments) > page.Limit {
+		tournaments = tournaments[:page.Limit]
+		meta.HasMore = true
+		meta.NextCursor = apiv2.EncodeCursor(tournamentCursor{
+			Sort:  q.sort,
+			Desc:  q.desc,
+			Value: keys[page.Limit-1],
+			ID:    tournaments[page.Limit-1].ID,
+		})
+	}
+	apiv2.List(c, tournaments, meta)
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+// tournamentStatusTransitions lists the statuses a tournament may move to from each status
+var tournamentStatusTransitions = map[string][]string{
+	models.TournamentStatusDraft:        {models.TournamentStatusRegistration, models.TournamentStatusInProgress},
+	models.TournamentStatusRegistration: {models.TournamentStatusDraft, models.TournamentStatusInProgress},
+	models.TournamentStatusInProgress:   {models.TournamentStatusCompleted},
+	models.TournamentStatusCompleted:    {models.TournamentStatusInProgress, models.TournamentStatusArchived},
+	models.TournamentStatusArchived:     {},
+}
+
+func canTransitionTournament(from, to string) bool {
+	for _, allowed := range tournamentStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateTournamentStatus moves a tournament through its lifecycle.
+// Completing an online tournament freezes its standings into tournament_standings;
+// reopening it (completed -> in_progress) discards the frozen standings again.
+// Completing with pending matches requires ?force=true.
+func UpdateTournamentStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	var req models.UpdateTournamentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	force := c.Query("force") == "true"
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var currentStatus, tournamentType string
+	err = tx.QueryRowContext(ctx,
+		"SELECT status, type FROM tournaments WHERE id = $1 FOR UPDATE",
+		tournamentID,
+	).Scan(&currentStatus, &tournamentType)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournament"})
+		return
+	}
+
+	if !canTransitionTournament(currentStatus, req.Status) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          fmt.Sprintf("Cannot change tournament status from '%s' to '%s'", currentStatus, req.Status),
+			"current_status": currentStatus,
+			"allowed":        tournamentStatusTransitions[currentStatus],
+		})
+		return
+	}
+
+	isOnline := tournamentType == "ONLINE"
+
+	switch req.Status {
+	case models.TournamentStatusCompleted:
+		if isOnline {
+			var pending int
+			err := tx.QueryRowContext(ctx,
+				"SELECT COUNT(*) FROM online_tournament_matches WHERE tournament_id = $1 AND completed = false",
+				tournamentID,
+			).Scan(&pending)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count pending matches"})
+				return
+			}
+			if pending > 0 && !force {
+				c.JSON(http.StatusConflict, gin.H{
+					"error":           fmt.Sprintf("Tournament still has %d pending match(es); use ?force=true to complete anyway", pending),
+					"pending_matches": pending,
+				})
+				return
+			}
+
+			if err := freezeOnlineStandings(ctx, tx, tournamentID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to freeze standings: " + err.Error()})
+				return
+			}
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE tournaments SET status = $1, completed_at = CURRENT_TIMESTAMP WHERE id = $2",
+			req.Status, tournamentID,
+		)
+
+	case models.TournamentStatusArchived:
+		_, err = tx.ExecContext(ctx,
+			"UPDATE tournaments SET status = $1, archived_at = CURRENT_TIMESTAMP WHERE id = $2",
+			req.Status, tournamentID,
+		)
+
+	case models.TournamentStatusInProgress:
+		// Reopening a completed online tournament: the standings are live again
+		if isOnline && currentStatus == models.TournamentStatusCompleted {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM tournament_standings WHERE tournament_id = $1", tournamentID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to discard frozen standings"})
+				return
+			}
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE tournaments SET status = $1, completed_at = NULL WHERE id = $2",
+			req.Status, tournamentID,
+		)
+
+	default:
+		_, err = tx.ExecContext(ctx, "UPDATE tournaments SET status = $1 WHERE id = $2", req.Status, tournamentID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tournament status"})
+		return
+	}
+
+	changed := webhooks.TournamentData{
+		TournamentID:   tournamentID,
+		Type:           tournamentType,
+		Status:         req.Status,
+		PreviousStatus: currentStatus,
+	}
+	if err := webhooks.Enqueue(ctx, tx, webhooks.EventTournamentStatusChanged, changed); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
+		return
+	}
+	event := webhooks.TournamentData{TournamentID: tournamentID, Type: tournamentType, Status: req.Status}
+	var followUp string
+	switch {
+	case req.Status == models.TournamentStatusArchived:
+		followUp = webhooks.EventTournamentArchived
+	case req.Status == models.TournamentStatusCompleted && isOnline:
+		followUp = webhooks.EventOnlineTournamentCompleted
+	}
+	if followUp != "" {
+		if err := webhooks.Enqueue(ctx, tx, followUp, event); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue webhook event"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	// The statistics count the finished tournaments
+	finished := map[string]bool{models.TournamentStatusCompleted: true, models.TournamentStatusArchived: true}
+	if finished[req.Status] || finished[currentStatus] {
+		statsChanged()
+	}
+
+	webhooks.Emit(webhooks.EventTournamentStatusChanged, changed)
+	if followUp != "" {
+		webhooks.Emit(followUp, event)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Tournament status updated successfully",
+		"tournament_id":   tournamentID,
+		"previous_status": currentStatus,
+		"status":          req.Status,
+	})
+}
+
+// finalPositionOrder is the ORDER BY of the final positions of a tournament, over the
+// given columns: disqualified players last, then by points, wins, games won and name
+func finalPositionOrder(status, points, wins, scored, name string) string {
+	return fmt.Sprintf("CASE WHEN %s = '%s' THEN 1 ELSE 0 END, %s DESC, %s DESC, %s DESC, %s",
+		status, models.PlayerStatusDisqualified, points, wins, scored, name)
+}
+
+// freezeOnlineStandings copies the live online standings of a tournament into
+// tournament_standings, assigning final positions and keeping the player status
+func freezeOnlineStandings(ctx context.Context, tx *sql.Tx, tournamentID int) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tournament_standings WHERE tournament_id = $1", tournamentID); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO tournament_standings (
+			tournament_id, player_id, player_name, matches_played, wins, ties, losses,
+			points, total_points_scored, total_matches, final_position, status
+		)
+		SELECT
+			ots.tournament_id, ots.player_id, ots.player_name, ots.matches_played, ots.wins, ots.ties, ots.losses,
+			ots.points, COALESCE(sc.scored, 0), COALESCE(sc.games, 0),
+			ROW_NUMBER() OVER (ORDER BY `+finalPositionOrder("ots.status", "ots.points", "ots.wins", "COALESCE(sc.scored, 0)", "ots.player_name")+`),
+			ots.status
+		FROM online_tournament_standings ots
+		LEFT JOIN (
+			SELECT
+				otp.player_id,
+				SUM(CASE
+					WHEN otm.result_type = 'forfeit' THEN 0
+					WHEN otm.player1_id = otp.player_id THEN otm.score1
+					ELSE otm.score2
+				END) as scored,
+				SUM(CASE WHEN otm.result_type = 'forfeit' THEN 0 ELSE otm.score1 + otm.score2 END) as games
+			FROM online_tournament_players otp
+			JOIN online_tournament_matches otm ON otm.tournament_id = otp.tournament_id
+				AND (otm.player1_id = otp.player_id OR otm.player2_id = otp.player_id)
+				AND otm.completed = true AND otm.result_type <> 'void'
+			WHERE otp.tournament_id = $1
+			GROUP BY otp.player_id
+		) sc ON sc.player_id = ots.player_id
+		WHERE ots.tournament_id = $1
+	`, tournamentID)
+	return err
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/apiv2"
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// nameCursor is the sort key of lists ordered by name
+type nameCursor struct {
+	Name string `json:"n"`
+	ID   int    `json:"id"`
+}
+
+// pathID reads an integer path parameter, answering invalid_request otherwise
+func pathID(c *gin.Context, name, what string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInvalidRequest, "Invalid "+what+" ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// GetTournamentV2 returns a tournament of any status
+func GetTournamentV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID, ok := pathID(c, "id", "tournament")
+	if !ok {
+		return
+	}
+
+	t, err := scanTournament(database.DB.QueryRowContext(ctx, `SELECT `+tournamentColumns+` FROM tournaments t WHERE t.id = $1`, tournamentID))
+	if err == sql.ErrNoRows {
+		apiv2.Fail(c, apiv2.CodeNotFound, "Tournament not found")
+		return
+	}
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to fetch tournament")
+		return
+	}
+	apiv2.OK(c, t)
+}
+
+// GetTournamentStandingsV2 returns the final standings of a tournament
+func GetTournamentStandingsV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	tournamentID, ok := pathID(c, "id", "tournament")
+	if !ok {
+		return
+	}
+
+	var exists bool
+	if err := database.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM tournaments WHERE id = $1)", tournamentID).Scan(&exists); err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to fetch tournament standings")
+		return
+	}
+	if !exists {
+		apiv2.Fail(c, apiv2.CodeNotFound, "Tournament not found")
+		return
+	}
+
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT ts.final_position, ts.player_id, ts.player_name, ts.matches_played, ts.wins, ts.ties, ts.losses,
+			ts.points, ts.total_points_scored, ts.total_matches, tpr.race_pb, tpr.race_bf
+		FROM tournament_standings ts
+		LEFT JOIN tournament_player_races tpr ON ts.tournament_id = tpr.tournament_id AND ts.player_id = tpr.player_id
+		WHERE ts.tournament_id = $1
+		ORDER BY ts.final_position, ts.player_id
+	`, tournamentID)
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to fetch tournament standings")
+		return
+	}
+	defer rows.Close()
+
+	standings := []models.StandingV2{}
+	for rows.Next() {
+		var s models.StandingV2
+		err := rows.Scan(
+			&s.Position, &s.PlayerID, &s.PlayerName, &s.MatchesPlayed, &s.Wins, &s.Ties, &s.Losses,
+			&s.Points, &s.TotalPointsScored, &s.TotalMatches, &s.RacePB, &s.RaceBF,
+		)
+		if err != nil {
+			apiv2.Fail(c, apiv2.CodeInternal, "Failed to read tournament standings")
+			return
+		}
+		standings = append(standings, s)
+	}
+	if err := rows.Err(); err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to read tournament standings")
+		return
+	}
+	apiv2.OK(c, standings)
+}
+
+// GetPlayersV2 returns a page of the players of the current tournament, by name
+func GetPlayersV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	page, ok := apiv2.ParsePage(c)
+	if !ok {
+		return
+	}
+	var after *nameCursor
+	if page.Cursor != "" {
+		after = &nameCursor{}
+		if err := apiv2.DecodeCursor(page.Cursor, after); err != nil {
+			apiv2.Fail(c, apiv2.CodeInvalidRequest, "Invalid cursor")
+			return
+		}
+	}
+
+	query, args := pageByName(`SELECT id, name, confirmed, status, late_entry, fixed_table, created_at, updated_at FROM players`, after, page.Limit)
+	rows, err := database.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to fetch players")
+		return
+	}
+	defer rows.Close()
+
+	players := []models.Player{}
+	for rows.Next() {
+		var p models.Player
+		if err := rows.Scan(&p.ID, &p.Name, &p.Confirmed, &p.Status, &p.LateEntry, &p.FixedTable, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			apiv2.Fail(c, apiv2.CodeInternal, "Failed to read players")
+			return
+		}
+		players = append(players, p)
+	}
+	if err := rows.Err(); err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to read players")
+		return
+	}
+
+	meta := apiv2.Meta{Limit: page.Limit}
+	if len(players) > page.Limit {
+		players = players[:page.Limit]
+		last := players[page.Limit-1]
+		meta.HasMore = true
+		meta.NextCursor = apiv2.EncodeCursor(nameCursor{Name: last.Name, ID: last.ID})
+	}
+	apiv2.List(c, players, meta)
+}
+
+// GetPremierPlayersV2 returns a page of the online tournament players, by name
+func GetPremierPlayersV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	page, ok := apiv2.ParsePage(c)
+	if !ok {
+		return
+	}
+	var after *nameCursor
+	if page.Cursor != "" {
+		after = &nameCursor{}
+		if err := apiv2.DecodeCursor(page.Cursor, after); err != nil {
+			apiv2.Fail(c, apiv2.CodeInvalidRequest, "Invalid cursor")
+			return
+		}
+	}
+
+	query, args := pageByName(`SELECT id, name FROM premier_players`, after, page.Limit)
+	rows, err := database.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to fetch players")
+		return
+	}
+	defer rows.Close()
+
+	players := []models.PremierPlayer{}
+	for rows.Next() {
+		var p models.PremierPlayer
+		if err := rows.Scan(&p.ID, &p.Name); err != nil {
+			apiv2.Fail(c, apiv2.CodeInternal, "Failed to read players")
+			return
+		}
+		players = append(players, p)
+	}
+	if err := rows.Err(); err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to read players")
+		return
+	}
+
+	meta := apiv2.Meta{Limit: page.Limit}
+	if len(players) > page.Limit {
+		players = players[:page.Limit]
+		last := players[page.Limit-1]
+		meta.HasMore = true
+		meta.NextCursor = apiv2.EncodeCursor(nameCursor{Name: last.Name, ID: last.ID})
+	}
+	apiv2.List(c, players, meta)
+}
+
+// pageByName completes a SELECT of a table with id and name columns into the query
+// of a page ordered by name, fetching one row more than limit to tell if more follow
+func pageByName(query string, after *nameCursor, limit int) (string, []interface{}) {
+	if after == nil {
+		return query + " ORDER BY name, id LIMIT $1", []interface{}{limit + 1}
+	}
+	return query + " WHERE (name, id) > ($1, $2) ORDER BY name, id LIMIT $3", []interface{}{after.Name, after.ID, limit + 1}
+}
+
+// GetFixtureV2 returns the rounds of the current tournament with their progress
+func GetFixtureV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	rounds, err := fetchFixtureV2(ctx)
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to fetch fixture")
+		return
+	}
+
+	progress := fixtureProgress(rounds)
+	fixture := models.FixtureV2{
+		Rounds:           make([]models.RoundV2, len(rounds)),
+		CurrentRound:     progress.CurrentRound,
+		CompletedRounds:  progress.CompletedRounds,
+		TotalMatches:     progress.TotalMatches,
+		CompletedMatches: progress.CompletedMatches,
+	}
+	for i, r := range rounds {
+		fixture.Rounds[i] = models.RoundV2{
+			RoundNumber:      r.Number,
+			Format:           r.Format,
+			Status:           r.Status,
+			TotalMatches:     r.TotalMatches,
+			CompletedMatches: r.CompletedMatches,
+			LockedAt:         r.LockedAt,
+			Matches:          r.Matches,
+		}
+	}
+	apiv2.OK(c, fixture)
+}
+
+// fetchFixtureV2 loads the rounds of the current tournament like fetchFixture, but
+// keeps the rounds without matches and fails on rows it cannot read
+func fetchFixtureV2(ctx context.Context) ([]models.FixtureRound, error) {
+	rows, err := database.DB.QueryContext(ctx, fixtureQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rounds := []models.FixtureRound{}
+	for rows.Next() {
+		var round models.FixtureRound
+		var matchID, version sql.NullInt64
+		var player1, player2 sql.NullString
+		var completed sql.NullBool
+		var updatedAt sql.NullTime
+		var match models.MatchDetail
+
+		err := rows.Scan(
+			&round.Number,
+			&round.Format,
+			&round.Status,
+			&round.TotalMatches,
+			&round.CompletedMatches,
+			&round.LockedAt,
+			&matchID,
+			&match.TableNumber,
+			&player1,
+			&player2,
+			&match.Score1,
+			&match.Score2,
+			&completed,
+			&match.ResultType,
+			&version,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rounds) == 0 || rounds[len(rounds)-1].Number != round.Number {
+			round.Matches = []models.MatchDetail{}
+			rounds = append(rounds, round)
+		}
+		if !matchID.Valid {
+			continue // a round without matches
+		}
+
+		match.ID = int(matchID.Int64)
+		match.RoundNumber = round.Number
+		match.Format = round.Format
+		match.Player1Name = player1.String
+		match.Player2Name = player2.String
+		match.Completed = completed.Bool
+		match.Version = int(version.Int64)
+		match.UpdatedAt = updatedAt.Time
+		last := &rounds[len(rounds)-1]
+		last.Matches = append(last.Matches, match)
+	}
+	return rounds, rows.Err()
+}
+
+// GetStandingsV2 returns the standings of the current tournament
+func GetStandingsV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	standings, err := fetchStandingsV2(ctx)
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to fetch standings")
+		return
+	}
+
+	rows := make([]models.StandingV2, len(standings))
+	for i, s := range standings {
+		rows[i] = models.StandingV2{
+			Position:          i + 1,
+			PlayerID:          s.ID,
+			PlayerName:        s.Name,
+			Status:            s.Status,
+			MatchesPlayed:     s.MatchesPlayed,
+			Wins:              s.Wins,
+			Ties:              s.Ties,
+			Losses:            s.Losses,
+			Points:            s.Points,
+			TotalPointsScored: s.TotalPointsScored,
+			TotalMatches:      s.TotalMatches,
+		}
+	}
+	apiv2.OK(c, rows)
+}
+
+// fetchStandingsV2 loads the current standings like fetchStandings, counting zero
+// for players without matches instead of leaving them out
+func fetchStandingsV2(ctx context.Context) ([]models.Standing, error) {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT id, name, status, COALESCE(matches_played, 0), COALESCE(wins, 0), COALESCE(ties, 0),
+			COALESCE(losses, 0), COALESCE(points, 0), COALESCE(total_points_scored, 0), COALESCE(total_matches, 0)
+		FROM standings
+		ORDER BY CASE WHEN status = 'disqualified' THEN 1 ELSE 0 END, points DESC NULLS LAST,
+			total_points_scored DESC NULLS LAST, name, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	standings := []models.Standing{}
+	for rows.Next() {
+		var s models.Standing
+		err := rows.Scan(
+			&s.ID, &s.Name, &s.Status, &s.MatchesPlayed, &s.Wins, &s.Ties,
+			&s.Losses, &s.Points, &s.TotalPointsScored, &s.TotalMatches,
+		)
+		if err != nil {
+			return nil, err
+		}
+		standings = append(standings, s)
+	}
+	return standings, rows.Err()
+}
+
+// GetMatchV2 returns a match of the current tournament with its version as ETag
+func GetMatchV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	matchID, ok := pathID(c, "id", "match")
+	if !ok {
+		return
+	}
+
+	match, err := fetchMatchDetail(ctx, database.DB, matchID)
+	if err == sql.ErrNoRows {
+		apiv2.Fail(c, apiv2.CodeNotFound, "Match not found")
+		return
+	}
+	if err != nil {
+		apiv2.Fail(c, apiv2.CodeInternal, "Failed to fetch match")
+		return
+	}
+
+	c.Header("ETag", versionETag(match.Version))
+	apiv2.OK(c, match)
+}
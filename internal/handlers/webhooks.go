@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// webhookColumns are the columns scanned by scanWebhook, without the secret
+const webhookColumns = "id, url, events, active, description, created_at, updated_at"
+
+func scanWebhook(row rowScanner) (models.Webhook, error) {
+	var w models.Webhook
+	var events pq.StringArray
+	err := row.Scan(&w.ID, &w.URL, &events, &w.Active, &w.Description, &w.CreatedAt, &w.UpdatedAt)
+	w.Events = events
+	return w, err
+}
+
+// checkWebhookEvents rejects events a webhook cannot subscribe to
+func checkWebhookEvents(c *gin.Context, events []string) bool {
+	for _, e := range events {
+		if !webhooks.IsEvent(e) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  fmt.Sprintf("Unknown event '%s'", e),
+				"events": webhooks.Events,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// CreateWebhook registers a URL to be called on tournament events. The response is
+// the only time the secret is returned.
+func CreateWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkWebhookEvents(c, req.Events) {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhooks.NewSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+			return
+		}
+	}
+
+	webhook, err := scanWebhook(database.DB.QueryRowContext(ctx, `
+		INSERT INTO webhooks (url, secret, events, description)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+webhookColumns,
+		req.URL, secret, pq.Array(req.Events), req.Description,
+	))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	webhook.Secret = secret
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// GetWebhooks lists the registered webhooks
+func GetWebhooks(c *gin.Context) {
+	ctx := c.Request.Context()
+	rows, err := database.DB.QueryContext(ctx, "SELECT "+webhookColumns+" FROM webhooks ORDER BY id")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan webhook"})
+			return
+		}
+		list = append(list, w)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": list, "events": webhooks.Events})
+}
+
+// UpdateWebhook changes the URL, events, description or active flag of a webhook, and
+// rotates its secret with rotate_secret: true (the new secret is in the response)
+func UpdateWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkWebhookEvents(c, req.Events) {
+		return
+	}
+
+	var secret *string
+	if req.RotateSecret {
+		s, err := webhooks.NewSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+			return
+		}
+		secret = &s
+	}
+	var events interface{}
+	if len(req.Events) > 0 {
+		events = pq.Array(req.Events)
+	}
+
+	webhook, err := scanWebhook(database.DB.QueryRowContext(ctx, `
+		UPDATE webhooks
+		SET url = COALESCE($1, url),
+			events = COALESCE($2, events),
+			active = COALESCE($3, active),
+			description = COALESCE($4, description),
+			secret = COALESCE($5, secret)
+		WHERE id = $6
+		RETURNING `+webhookColumns,
+		req.URL, events, req.Active, req.Description, secret, webhookID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+	if secret != nil {
+		webhook.Secret = *secret
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook removes a webhook with its queued deliveries
+func DeleteWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	result, err := database.DB.ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1", webhookID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully", "webhook_id": webhookID})
+}
+
+// TestWebhook queues a ping to a single webhook, whatever its events, to check the
+// receiver and its signature verification
+func TestWebhook(c *gin.Context) {
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	deliveryID, err := webhooks.EnqueueFor(c.Request.Context(), database.DB, webhookID, webhooks.EventPing, gin.H{"webhook_id": webhookID})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue ping"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Ping queued", "delivery_id": deliveryID})
+}
+
+// GetWebhookDeliveries lists the latest deliveries of a webhook, newest first.
+// ?status= keeps pending, delivered or failed ones; ?limit= defaults to 50.
+func GetWebhookDeliveries(c *gin.Context) {
+	ctx := c.Request.Context()
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	args := []interface{}{webhookID, limit}
+	where := "webhook_id = $1"
+	if status := c.Query("status"); status != "" {
+		switch status {
+		case models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, delivered or failed"})
+			return
+		}
+		args = append(args, status)
+		where += " AND status = $3"
+	}
+
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT id, webhook_id, event, payload, status, attempts, next_attempt_at, last_attempt_at,
+			response_status, last_error, delivered_at, created_at
+		FROM webhook_deliveries
+		WHERE `+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastAttemptAt, &d.ResponseStatus, &d.LastError, &d.DeliveredAt, &d.CreatedAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan delivery"})
+			return
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RetryWebhookDelivery sends a failed delivery again, with a fresh set of attempts
+func RetryWebhookDelivery(c *gin.Context) {
+	ctx := c.Request.Context()
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	var status string
+	err = database.DB.QueryRowContext(ctx,
+		"SELECT status FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2",
+		deliveryID, webhookID,
+	).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch delivery"})
+		return
+	}
+	if status != models.WebhookDeliveryFailed {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Only failed deliveries can be retried (this one is %s)", status)})
+		return
+	}
+
+	_, err = database.DB.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = 0, next_attempt_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3
+	`, models.WebhookDeliveryPending, deliveryID, models.WebhookDeliveryFailed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry delivery"})
+		return
+	}
+	webhooks.Wake()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Delivery queued again", "delivery_id": deliveryID})
+}
@@ -0,0 +1,75 @@
+// Package logging sets up structured logs with log/slog, and carries the ID of the
+// request being served in its context so every line logged for it can be found.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Config selects the level and format of the logs
+type Config struct {
+	Level  slog.Level
+	Format string // json or text
+}
+
+// ConfigFromEnv reads LOG_LEVEL (debug, info, warn or error; default info) and
+// LOG_FORMAT (json or text; default json)
+func ConfigFromEnv() Config {
+	cfg := Config{Level: slog.LevelInfo, Format: "json"}
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		if err := cfg.Level.UnmarshalText([]byte(value)); err != nil {
+			slog.Warn("invalid LOG_LEVEL, using info", "value", value)
+		}
+	}
+	switch format := strings.ToLower(os.Getenv("LOG_FORMAT")); format {
+	case "", "json":
+	case "text":
+		cfg.Format = "text"
+	default:
+		slog.Warn("invalid LOG_FORMAT, using json", "value", format)
+	}
+	return cfg
+}
+
+// Setup makes slog, and the standard log package through it, write to stderr with cfg
+func Setup(cfg Config) {
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+	var handler slog.Handler = slog.NewJSONHandler(os.Stderr, opts)
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+type requestIDKey struct{}
+
+// NewRequestID returns a random ID for a request
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of ctx, or "" outside a request
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext returns the default logger, with the request ID of ctx if it has one
+func FromContext(ctx context.Context) *slog.Logger {
+	if id := RequestID(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
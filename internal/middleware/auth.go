@@ -1,15 +1,28 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"net/http"
 	"os"
 
+	"github.com/andreuvv/premier_mitologico/backend/internal/ratelimit"
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware validates API key for protected routes
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware validates API key for protected routes. A client IP that sends too
+// many wrong keys is locked out with 429 until its failures expire, and requests
+// with the key are limited per key.
+func AuthMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		ip := c.ClientIP()
+		if wait := limiter.Blocked(ctx, "auth", ip, limiter.AuthFailures); wait > 0 {
+			tooManyRequests(c, wait)
+			return
+		}
+
 		apiKey := c.GetHeader("X-API-Key")
 
 		expectedKey := os.Getenv("API_KEY")
@@ -19,12 +32,20 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if apiKey != expectedKey {
+		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(expectedKey)) != 1 {
+			limiter.Take(ctx, "auth", ip, limiter.AuthFailures)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing API key"})
 			c.Abort()
 			return
 		}
 
+		// Buckets are keyed by a hash so keys are not kept in the store
+		sum := sha256.Sum256([]byte(apiKey))
+		if wait := limiter.Take(ctx, "key", hex.EncodeToString(sum[:8]), limiter.PerKey); wait > 0 {
+			tooManyRequests(c, wait)
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/logging"
+	"github.com/andreuvv/premier_mitologico/backend/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	requestDuration = metrics.NewHistogramVec("http_request_duration_seconds",
+		"Time to serve a request, by route", metrics.DefaultBuckets, "method", "route")
+	requestsTotal = metrics.NewCounterVec("http_requests_total",
+		"Requests served, by route and status", "method", "route", "status")
+	requestErrors = metrics.NewCounterVec("http_request_errors_total",
+		"Requests answered with a 4xx or 5xx status, by route and class", "method", "route", "class")
+)
+
+// RequestIDMiddleware gives every request an ID, taken from X-Request-ID when the
+// client or a proxy sends a sane one, and returns it in X-Request-ID. The ID is in the
+// request context, so logging.FromContext tags every line logged for the request.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if !validRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		c.Set("request_id", id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+// validRequestID accepts up to 64 letters, digits, dashes and underscores
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// LoggerMiddleware logs every request once it is served, and records its latency and
+// status in the metrics by route (the route pattern, not the path, so IDs don't make
+// series of their own)
+func LoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		elapsed := time.Since(start)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		status := c.Writer.Status()
+
+		requestDuration.Observe(elapsed.Seconds(), method, route)
+		requestsTotal.Inc(method, route, strconv.Itoa(status))
+		if status >= 400 {
+			requestErrors.Inc(method, route, strconv.Itoa(status/100)+"xx")
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Int64("duration_ms", elapsed.Milliseconds()),
+			slog.String("ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		logging.FromContext(c.Request.Context()).LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
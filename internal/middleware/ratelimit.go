@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/apiv2"
+	"github.com/andreuvv/premier_mitologico/backend/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware limits the requests of every client IP
+func RateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if wait := limiter.Take(c.Request.Context(), "ip", c.ClientIP(), limiter.PerIP); wait > 0 {
+			tooManyRequests(c, wait)
+			return
+		}
+		c.Next()
+	}
+}
+
+// TrustProxies makes c.ClientIP read X-Forwarded-For only when the connection comes
+// from one of proxies (comma-separated IPs or CIDRs). With no proxies the header is
+// ignored, so clients cannot choose the IP they are limited and locked out by.
+func TrustProxies(router *gin.Engine, proxies string) error {
+	if strings.TrimSpace(proxies) == "" {
+		return router.SetTrustedProxies(nil)
+	}
+	var list []string
+	for _, proxy := range strings.Split(proxies, ",") {
+		list = append(list, strings.TrimSpace(proxy))
+	}
+	return router.SetTrustedProxies(list)
+}
+
+// tooManyRequests aborts with 429 and the seconds to wait in Retry-After
+func tooManyRequests(c *gin.Context, wait time.Duration) {
+	retryAfter := ratelimit.RetryAfter(wait)
+	c.Header("Retry-After", retryAfter)
+	message := fmt.Sprintf("Too many requests, retry in %s seconds", retryAfter)
+	if strings.HasPrefix(c.Request.URL.Path, apiv2.Prefix+"/") {
+		apiv2.Fail(c, apiv2.CodeRateLimited, message)
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": message})
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(t *testing.T, proxies string, cfg ratelimit.Config) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("API_KEY", "secret")
+
+	limiter := ratelimit.New(ratelimit.NewMemory(), cfg)
+	router := gin.New()
+	if err := TrustProxies(router, proxies); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(RateLimitMiddleware(limiter))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/fixture", ok)
+	router.POST("/api/fixture", AuthMiddleware(limiter), ok)
+	return router
+}
+
+func serve(router *gin.Engine, method, remoteAddr, forwardedFor, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/fixture", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSpoofedForwardedForDoesNotResetIPBucket(t *testing.T) {
+	router := newTestRouter(t, "", ratelimit.Config{PerIP: ratelimit.Limit{Requests: 3, Per: time.Minute}})
+
+	for i := 0; i < 3; i++ {
+		if w := serve(router, http.MethodGet, "203.0.113.7:4000", "10.0.0."+strconv.Itoa(i), ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i, w.Code)
+		}
+	}
+	w := serve(router, http.MethodGet, "203.0.113.7:4000", "10.0.0.99", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d with a new X-Forwarded-For, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+
+	if w := serve(router, http.MethodGet, "198.51.100.1:4000", "", ""); w.Code != http.StatusOK {
+		t.Errorf("other client: status %d, want 200", w.Code)
+	}
+}
+
+func TestSpoofedForwardedForDoesNotResetLockout(t *testing.T) {
+	router := newTestRouter(t, "", ratelimit.Config{AuthFailures: ratelimit.Limit{Requests: 2, Per: time.Hour}})
+
+	for i := 0; i < 2; i++ {
+		if w := serve(router, http.MethodPost, "203.0.113.7:4000", "10.0.0."+strconv.Itoa(i), "guess"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("guess %d: status %d, want 401", i, w.Code)
+		}
+	}
+	if w := serve(router, http.MethodPost, "203.0.113.7:4000", "10.0.0.99", "secret"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("locked out client with a new X-Forwarded-For: status %d, want 429", w.Code)
+	}
+	if w := serve(router, http.MethodPost, "198.51.100.1:4000", "", "secret"); w.Code != http.StatusOK {
+		t.Errorf("other client: status %d, want 200", w.Code)
+	}
+}
+
+func TestTrustedProxyForwardsClientIP(t *testing.T) {
+	router := newTestRouter(t, "192.0.2.10", ratelimit.Config{PerIP: ratelimit.Limit{Requests: 1, Per: time.Minute}})
+
+	if w := serve(router, http.MethodGet, "192.0.2.10:4000", "203.0.113.7", ""); w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "192.0.2.10:4000", "203.0.113.8", ""); w.Code != http.StatusOK {
+		t.Errorf("second client behind the proxy: status %d, want 200", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "192.0.2.10:4000", "203.0.113.7", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("first client again: status %d, want 429", w.Code)
+	}
+}
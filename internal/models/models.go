@@ -1,15 +1,66 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Player struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Confirmed bool      `json:"confirmed"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Confirmed  bool      `json:"confirmed"`
+	Status     string    `json:"status"`
+	LateEntry  bool      `json:"late_entry"`
+	FixedTable *int      `json:"fixed_table"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// Player participation statuses (in-person and online)
+const (
+	PlayerStatusActive       = "active"
+	PlayerStatusDropped      = "dropped"
+	PlayerStatusDisqualified = "disqualified"
+)
+
+// What happens to the pending matches of a player who drops or is disqualified
+const (
+	DropPolicyForfeit = "forfeit" // the opponent wins by forfeit
+	DropPolicyRemove  = "remove"  // the match is deleted
+)
+
+// How a match result was decided
+const (
+	MatchResultPlayed     = "played"
+	MatchResultForfeit    = "forfeit"
+	MatchResultDoubleLoss = "double_loss" // online only: both players get a loss
+	MatchResultVoid       = "void"        // not played: no result for either player
+)
+
+// How a match result is corrected
+const (
+	CorrectionScore  = "score"  // the result is replaced by a played score
+	CorrectionReopen = "reopen" // the result is cleared and the match is pending again
+	CorrectionVoid   = "void"   // the result is cleared and the match is not played
+)
+
+// Where a corrected match lives
+const (
+	MatchSourceInPerson = "in_person" // the running in-person tournament
+	MatchSourceOnline   = "online"
+	MatchSourceArchived = "archived" // an archived in-person tournament
+)
+
+// What happens to an online match that passes its deadline
+const (
+	DeadlinePolicyDoubleLoss = "double_loss" // both players get a loss
+	DeadlinePolicyForfeit    = "forfeit"     // the player who claimed the forfeit wins, otherwise double loss
+	DeadlinePolicyNone       = "none"        // the match stays pending
+)
+
+// ForfeitWinScore is the score awarded to the opponent of a forfeiting player (2-0)
+const ForfeitWinScore = 2
+
 type Round struct {
 	ID          int       `json:"id"`
 	RoundNumber int       `json:"round_number"`
@@ -34,17 +85,21 @@ type MatchDetail struct {
 	ID          int       `json:"id"`
 	RoundNumber int       `json:"round_number"`
 	Format      string    `json:"format"`
+	TableNumber *int      `json:"table_number"`
 	Player1Name string    `json:"player1_name"`
 	Player2Name string    `json:"player2_name"`
 	Score1      *int      `json:"score1"`
 	Score2      *int      `json:"score2"`
 	Completed   bool      `json:"completed"`
+	ResultType  string    `json:"result_type"`
+	Version     int       `json:"version"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type Standing struct {
 	ID                int    `json:"id"`
 	Name              string `json:"name"`
+	Status            string `json:"status"`
 	MatchesPlayed     int    `json:"matches_played"`
 	Wins              int    `json:"wins"`
 	Ties              int    `json:"ties"`
@@ -56,13 +111,26 @@ type Standing struct {
 
 // Request/Response DTOs
 type CreatePlayerRequest struct {
-	Name      string `json:"name" binding:"required"`
-	Confirmed bool   `json:"confirmed"`
+	Name       string `json:"name" binding:"required"`
+	Confirmed  bool   `json:"confirmed"`
+	FixedTable *int   `json:"fixed_table" binding:"omitempty,min=1"`
+}
+
+// SetFixedTableRequest gives a player the same table in every round; null clears it
+type SetFixedTableRequest struct {
+	FixedTable *int `json:"fixed_table" binding:"omitempty,min=1"`
 }
 
+// CreateRoundRequest pairs the next round of the running tournament
 type CreateRoundRequest struct {
-	RoundNumber int    `json:"round_number" binding:"required"`
-	Format      string `json:"format" binding:"required,oneof=PB BF"`
+	RoundNumber int            `json:"round_number" binding:"required,min=1"`
+	Format      string         `json:"format" binding:"required,oneof=PB BF"`
+	Matches     []RoundPairing `json:"matches" binding:"required,min=1,dive"`
+}
+
+type RoundPairing struct {
+	Player1Name string `json:"player1_name" binding:"required"`
+	Player2Name string `json:"player2_name" binding:"required"`
 }
 
 type CreateMatchRequest struct {
@@ -71,19 +139,76 @@ type CreateMatchRequest struct {
 	Player2ID int `json:"player2_id" binding:"required"`
 }
 
+type WithdrawPlayerRequest struct {
+	Policy string  `json:"policy" binding:"omitempty,oneof=forfeit remove"` // defaults to forfeit
+	Reason *string `json:"reason"`
+}
+
+type LateEntryRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
 type UpdateScoreRequest struct {
 	Score1 int `json:"score1" binding:"gte=0"`
 	Score2 int `json:"score2" binding:"gte=0"`
 }
 
+// MatchCorrectionRequest corrects a match result; the scores are required to
+// correct the score
+type MatchCorrectionRequest struct {
+	Action string  `json:"action" binding:"required,oneof=score reopen void"`
+	Score1 *int    `json:"score1" binding:"omitempty,gte=0"`
+	Score2 *int    `json:"score2" binding:"omitempty,gte=0"`
+	Reason *string `json:"reason"`
+}
+
+// MatchCorrection is a logged correction, with the result it replaced
+type MatchCorrection struct {
+	ID                 int       `json:"id"`
+	TournamentID       *int      `json:"tournament_id"`
+	MatchSource        string    `json:"match_source"`
+	MatchID            int       `json:"match_id"`
+	RoundNumber        *int      `json:"round_number"`
+	Player1Name        string    `json:"player1_name"`
+	Player2Name        string    `json:"player2_name"`
+	Action             string    `json:"action"`
+	PreviousScore1     *int      `json:"previous_score1"`
+	PreviousScore2     *int      `json:"previous_score2"`
+	PreviousCompleted  bool      `json:"previous_completed"`
+	PreviousResultType string    `json:"previous_result_type"`
+	Score1             *int      `json:"score1"`
+	Score2             *int      `json:"score2"`
+	Reason             *string   `json:"reason"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// Round lifecycle: a round is pending, in progress or complete following its match
+// results, and locked once the organizer closes it
+const (
+	RoundStatusPending    = "pending"
+	RoundStatusInProgress = "in_progress"
+	RoundStatusComplete   = "complete"
+	RoundStatusLocked     = "locked"
+)
+
 type FixtureRound struct {
-	Number  int           `json:"number"`
-	Format  string        `json:"format"`
-	Matches []MatchDetail `json:"matches"`
+	Number           int           `json:"number"`
+	Format           string        `json:"format"`
+	Status           string        `json:"status"`
+	TotalMatches     int           `json:"total_matches"`
+	CompletedMatches int           `json:"completed_matches"`
+	LockedAt         *time.Time    `json:"locked_at"`
+	Matches          []MatchDetail `json:"matches"`
 }
 
+// FixtureResponse is the fixture with its progress. CurrentRound is the first round
+// that is not complete yet, or null when every round is.
 type FixtureResponse struct {
-	Rounds []FixtureRound `json:"rounds"`
+	Rounds           []FixtureRound `json:"rounds"`
+	CurrentRound     *int           `json:"current_round"`
+	CompletedRounds  int            `json:"completed_rounds"`
+	TotalMatches     int            `json:"total_matches"`
+	CompletedMatches int            `json:"completed_matches"`
 }
 
 type CreateFixtureRequest struct {
@@ -98,16 +223,34 @@ type CreateFixtureRequest struct {
 	} `json:"rounds" binding:"required"`
 }
 
+// Tournament lifecycle statuses
+const (
+	TournamentStatusDraft        = "draft"
+	TournamentStatusRegistration = "registration"
+	TournamentStatusInProgress   = "in_progress"
+	TournamentStatusCompleted    = "completed"
+	TournamentStatusArchived     = "archived"
+)
+
 // Tournament archive models
 type Tournament struct {
-	ID         int       `json:"id"`
-	Name       string    `json:"name"`
-	Month      string    `json:"month"`
-	Year       int       `json:"year"`
-	StartDate  *string   `json:"start_date"`
-	EndDate    *string   `json:"end_date"`
-	CreatedAt  time.Time `json:"created_at"`
-	ArchivedAt time.Time `json:"archived_at"`
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Month       string     `json:"month"`
+	MonthStart  *string    `json:"month_start"` // first day of the month, YYYY-MM-DD
+	Year        int        `json:"year"`
+	Type        string     `json:"type"`
+	Format      *string    `json:"format"`
+	Status      string     `json:"status"`
+	StartDate   *string    `json:"start_date"`
+	EndDate     *string    `json:"end_date"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+}
+
+type UpdateTournamentStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft registration in_progress completed archived"`
 }
 
 type TournamentStanding struct {
@@ -169,6 +312,7 @@ type TournamentMatchInfo struct {
 	Score1      *int   `json:"score1"`
 	Score2      *int   `json:"score2"`
 	Completed   bool   `json:"completed"`
+	ResultType  string `json:"result_type"`
 }
 
 type TournamentRoundsResponse struct {
@@ -197,13 +341,14 @@ type UpdatePlayerRaceRequest struct {
 
 // Online tournament models
 type CreateOnlineTournamentRequest struct {
-	Name      string  `json:"name" binding:"required"`
-	Month     string  `json:"month" binding:"required"`
-	Year      int     `json:"year" binding:"required"`
-	Format    string  `json:"format" binding:"required,oneof=PB BF"`
-	PlayerIDs []int   `json:"player_ids" binding:"required"`
-	StartDate *string `json:"start_date"`
-	EndDate   *string `json:"end_date"`
+	Name           string  `json:"name" binding:"required"`
+	Month          string  `json:"month" binding:"required"`
+	Year           int     `json:"year" binding:"required"`
+	Format         string  `json:"format" binding:"required,oneof=PB BF"`
+	PlayerIDs      []int   `json:"player_ids" binding:"required"`
+	StartDate      *string `json:"start_date"`
+	EndDate        *string `json:"end_date"`
+	DeadlinePolicy string  `json:"deadline_policy" binding:"omitempty,oneof=double_loss forfeit none"`
 }
 
 type OnlineTournamentMatch struct {
@@ -216,7 +361,12 @@ type OnlineTournamentMatch struct {
 	Score1       *int       `json:"score1"`
 	Score2       *int       `json:"score2"`
 	Completed    bool       `json:"completed"`
+	ResultType   string     `json:"result_type"`
 	MatchDate    *time.Time `json:"match_date"`
+	Matchday     *int       `json:"matchday"`
+	Deadline     *time.Time `json:"deadline"`
+	ClaimedBy    *int       `json:"forfeit_claimed_by"`
+	Version      int        `json:"version"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 }
@@ -225,6 +375,7 @@ type OnlineTournamentStanding struct {
 	TournamentID  int    `json:"tournament_id"`
 	PlayerID      int    `json:"player_id"`
 	PlayerName    string `json:"player_name"`
+	Status        string `json:"status"`
 	MatchesPlayed int    `json:"matches_played"`
 	Wins          int    `json:"wins"`
 	Ties          int    `json:"ties"`
@@ -232,7 +383,562 @@ type OnlineTournamentStanding struct {
 	Points        int    `json:"points"`
 }
 
+type OnlineLateEntryRequest struct {
+	PlayerID int `json:"player_id" binding:"required"`
+}
+
 type UpdateOnlineMatchScoreRequest struct {
 	Score1 int `json:"score1" binding:"gte=0"`
 	Score2 int `json:"score2" binding:"gte=0"`
 }
+
+// Season models
+type Season struct {
+	ID                  int                `json:"id"`
+	Name                string             `json:"name"`
+	Year                int                `json:"year"`
+	PointsTable         []int              `json:"points_table"`
+	ParticipationPoints int                `json:"participation_points"`
+	BestNResults        *int               `json:"best_n_results"`
+	QualifierSlots      int                `json:"qualifier_slots"`
+	Tournaments         []SeasonTournament `json:"tournaments,omitempty"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+}
+
+type SeasonTournament struct {
+	TournamentID int     `json:"tournament_id"`
+	Name         string  `json:"name"`
+	Month        string  `json:"month"`
+	Year         int     `json:"year"`
+	Type         string  `json:"type"`
+	Format       *string `json:"format"`
+}
+
+type CreateSeasonRequest struct {
+	Name                string `json:"name" binding:"required"`
+	Year                int    `json:"year" binding:"required"`
+	PointsTable         []int  `json:"points_table"`
+	ParticipationPoints int    `json:"participation_points" binding:"gte=0"`
+	BestNResults        *int   `json:"best_n_results" binding:"omitempty,gt=0"`
+	QualifierSlots      *int   `json:"qualifier_slots" binding:"omitempty,gte=0"`
+	TournamentIDs       []int  `json:"tournament_ids"`
+}
+
+type UpdateSeasonRequest struct {
+	Name                *string `json:"name"`
+	PointsTable         []int   `json:"points_table"`
+	ParticipationPoints *int    `json:"participation_points" binding:"omitempty,gte=0"`
+	BestNResults        *int    `json:"best_n_results" binding:"omitempty,gte=0"` // 0 clears the limit
+	QualifierSlots      *int    `json:"qualifier_slots" binding:"omitempty,gte=0"`
+}
+
+type AddSeasonTournamentRequest struct {
+	TournamentID int `json:"tournament_id" binding:"required"`
+}
+
+type SeasonResult struct {
+	TournamentID   int    `json:"tournament_id"`
+	TournamentName string `json:"tournament_name"`
+	FinalPosition  int    `json:"final_position"`
+	Points         int    `json:"points"`
+	Counted        bool   `json:"counted"`
+}
+
+type SeasonLeaderboardEntry struct {
+	Position          int            `json:"position"`
+	PlayerName        string         `json:"player_name"`
+	TotalPoints       int            `json:"total_points"`
+	TournamentsPlayed int            `json:"tournaments_played"`
+	BestFinish        int            `json:"best_finish"`
+	Qualified         bool           `json:"qualified"`
+	Results           []SeasonResult `json:"results"`
+}
+
+type SeasonLeaderboardResponse struct {
+	SeasonID       int                      `json:"season_id"`
+	SeasonName     string                   `json:"season_name"`
+	BestNResults   *int                     `json:"best_n_results"`
+	QualifierSlots int                      `json:"qualifier_slots"`
+	Leaderboard    []SeasonLeaderboardEntry `json:"leaderboard"`
+}
+
+// Online match deadline models
+type OnlineMatchday struct {
+	TournamentID int       `json:"tournament_id"`
+	Matchday     int       `json:"matchday"`
+	Deadline     time.Time `json:"deadline"`
+	Matches      int       `json:"matches"`
+}
+
+type ScheduleMatchdaysRequest struct {
+	FirstDeadline time.Time `json:"first_deadline" binding:"required"`
+	IntervalDays  int       `json:"interval_days" binding:"omitempty,gt=0"` // defaults to 7
+}
+
+type SetMatchdayDeadlineRequest struct {
+	Deadline time.Time `json:"deadline" binding:"required"`
+}
+
+type SetMatchDeadlineRequest struct {
+	Deadline *time.Time `json:"deadline"` // null goes back to the matchday deadline
+}
+
+type UpdateDeadlinePolicyRequest struct {
+	Policy string `json:"policy" binding:"required,oneof=double_loss forfeit none"`
+}
+
+type ClaimForfeitRequest struct {
+	PlayerID int `json:"player_id" binding:"required"`
+}
+
+// Online match scheduling models
+const (
+	ProposalStatusPending    = "pending"
+	ProposalStatusAccepted   = "accepted"
+	ProposalStatusDeclined   = "declined"
+	ProposalStatusWithdrawn  = "withdrawn"
+	ProposalStatusSuperseded = "superseded"
+)
+
+type MatchProposal struct {
+	ID             int                 `json:"id"`
+	MatchID        int                 `json:"match_id"`
+	ProposedBy     int                 `json:"proposed_by"`
+	ProposedByName string              `json:"proposed_by_name"`
+	Status         string              `json:"status"`
+	Message        *string             `json:"message"`
+	AcceptedSlotID *int                `json:"accepted_slot_id"`
+	Slots          []MatchProposalSlot `json:"slots"`
+	RespondedAt    *time.Time          `json:"responded_at"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+type MatchProposalSlot struct {
+	ID       int       `json:"id"`
+	StartsAt time.Time `json:"starts_at"`
+}
+
+type CreateMatchProposalRequest struct {
+	PlayerID int         `json:"player_id" binding:"required"`
+	Slots    []time.Time `json:"slots" binding:"required,min=1,max=5"`
+	Message  *string     `json:"message"`
+}
+
+type AcceptMatchProposalRequest struct {
+	PlayerID int `json:"player_id" binding:"required"`
+	SlotID   int `json:"slot_id" binding:"required"`
+}
+
+type RespondMatchProposalRequest struct {
+	PlayerID int `json:"player_id" binding:"required"`
+}
+
+// Tournament export/import models
+const TournamentBundleVersion = 1
+
+// TournamentBundle is a self-contained copy of an archived or online tournament.
+// Players are identified by name, so a bundle can be imported into another instance.
+type TournamentBundle struct {
+	Version    int                `json:"version"`
+	ExportedAt time.Time          `json:"exported_at"`
+	Tournament BundleTournament   `json:"tournament"`
+	Players    []BundlePlayer     `json:"players"`
+	Standings  []BundleStanding   `json:"standings"`
+	Rounds     []BundleRound      `json:"rounds"`
+	Matchdays  []BundleMatchday   `json:"matchdays"`
+	Matches    []BundleMatch      `json:"matches"`
+	Races      []BundlePlayerRace `json:"races"`
+}
+
+type BundleTournament struct {
+	Name           string     `json:"name"`
+	Month          string     `json:"month"`
+	Year           int        `json:"year"`
+	Type           string     `json:"type"`
+	Format         *string    `json:"format"`
+	Status         string     `json:"status"`
+	DeadlinePolicy string     `json:"deadline_policy"`
+	StartDate      *string    `json:"start_date"`
+	EndDate        *string    `json:"end_date"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	ArchivedAt     *time.Time `json:"archived_at"`
+}
+
+type BundlePlayer struct {
+	Name         string  `json:"name"`
+	Status       string  `json:"status"`
+	StatusReason *string `json:"status_reason"`
+	LateEntry    bool    `json:"late_entry"`
+}
+
+type BundleStanding struct {
+	PlayerName        string `json:"player_name"`
+	MatchesPlayed     int    `json:"matches_played"`
+	Wins              int    `json:"wins"`
+	Ties              int    `json:"ties"`
+	Losses            int    `json:"losses"`
+	Points            int    `json:"points"`
+	TotalPointsScored int    `json:"total_points_scored"`
+	TotalMatches      int    `json:"total_matches"`
+	FinalPosition     *int   `json:"final_position"`
+}
+
+type BundleRound struct {
+	RoundNumber int    `json:"round_number"`
+	Format      string `json:"format"`
+}
+
+type BundleMatchday struct {
+	Matchday int       `json:"matchday"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// BundleMatch is a match of a round (RoundNumber set) or of an online league (RoundNumber nil)
+type BundleMatch struct {
+	RoundNumber *int       `json:"round_number"`
+	Player1Name string     `json:"player1_name"`
+	Player2Name string     `json:"player2_name"`
+	Score1      *int       `json:"score1"`
+	Score2      *int       `json:"score2"`
+	Completed   bool       `json:"completed"`
+	ResultType  string     `json:"result_type"`
+	MatchDate   *time.Time `json:"match_date"`
+	Matchday    *int       `json:"matchday"`
+	Deadline    *time.Time `json:"deadline"`
+}
+
+type BundlePlayerRace struct {
+	PlayerName string  `json:"player_name"`
+	RacePB     *string `json:"race_pb"`
+	RaceBF     *string `json:"race_bf"`
+	Notes      *string `json:"notes"`
+}
+
+type TournamentImportResponse struct {
+	Message        string   `json:"message"`
+	TournamentID   int      `json:"tournament_id"`
+	PlayersMatched int      `json:"players_matched"`
+	PlayersCreated []string `json:"players_created"`
+	Rounds         int      `json:"rounds"`
+	Matches        int      `json:"matches"`
+}
+
+// Bulk import models
+const (
+	BulkImportImported  = "imported"
+	BulkImportDuplicate = "duplicate"
+)
+
+type BulkImportReport struct {
+	DryRun      bool                   `json:"dry_run"`
+	Tournaments []BulkImportTournament `json:"tournaments"`
+	Errors      []string               `json:"errors"`
+}
+
+type BulkImportTournament struct {
+	Name           string   `json:"name"`
+	Month          string   `json:"month"`
+	Year           int      `json:"year"`
+	Status         string   `json:"status"`
+	TournamentID   *int     `json:"tournament_id"`
+	Players        int      `json:"players"`
+	PlayersCreated []string `json:"players_created"`
+	Standings      int      `json:"standings"`
+	Rounds         int      `json:"rounds"`
+	Matches        int      `json:"matches"`
+	Races          int      `json:"races"`
+}
+
+// Round clock models
+const (
+	ClockStatusNotStarted = "not_started"
+	ClockStatusRunning    = "running"
+	ClockStatusPaused     = "paused"
+	ClockStatusExtraTurns = "extra_turns"
+	ClockStatusCompleted  = "completed" // match clocks only: the result is in
+)
+
+// RoundClock is the timer of an in-person round. RemainingSeconds is measured at
+// ServerTime; clients count down from it while Status is running.
+type RoundClock struct {
+	RoundNumber      int          `json:"round_number"`
+	Format           string       `json:"format"`
+	Status           string       `json:"status"`
+	DurationSeconds  int          `json:"duration_seconds"`
+	ExtensionSeconds int          `json:"extension_seconds"`
+	RemainingSeconds int          `json:"remaining_seconds"`
+	ExtraTurns       int          `json:"extra_turns"`
+	StartedAt        *time.Time   `json:"started_at"`
+	ServerTime       time.Time    `json:"server_time"`
+	Matches          []MatchClock `json:"matches"`
+}
+
+// MatchClock is the time left for one match: the round time plus its own extensions
+type MatchClock struct {
+	MatchID          int    `json:"match_id"`
+	TableNumber      *int   `json:"table_number"`
+	Player1Name      string `json:"player1_name"`
+	Player2Name      string `json:"player2_name"`
+	Status           string `json:"status"`
+	ExtensionSeconds int    `json:"extension_seconds"`
+	RemainingSeconds int    `json:"remaining_seconds"`
+}
+
+type StartRoundClockRequest struct {
+	DurationMinutes int  `json:"duration_minutes" binding:"omitempty,min=1,max=240"`
+	ExtraTurns      *int `json:"extra_turns" binding:"omitempty,min=0,max=10"`
+}
+
+type ExtendRoundClockRequest struct {
+	Minutes int `json:"minutes" binding:"required,min=1,max=60"`
+}
+
+type MatchTimeExtensionRequest struct {
+	Minutes int     `json:"minutes" binding:"required,min=1,max=60"`
+	Reason  *string `json:"reason"`
+}
+
+// Judging models
+const (
+	PenaltyWarning          = "warning"
+	PenaltyGameLoss         = "game_loss"
+	PenaltyMatchLoss        = "match_loss"
+	PenaltyDisqualification = "disqualification"
+)
+
+// Infraction is a judge ruling against a player. TournamentID is nil while the
+// in-person tournament is running.
+type Infraction struct {
+	ID             int       `json:"id"`
+	TournamentID   *int      `json:"tournament_id"`
+	TournamentName *string   `json:"tournament_name,omitempty"`
+	MatchID        *int      `json:"match_id"`
+	RoundNumber    *int      `json:"round_number"`
+	PlayerName     string    `json:"player_name"`
+	OpponentName   *string   `json:"opponent_name"`
+	Type           string    `json:"type"`
+	Penalty        string    `json:"penalty"`
+	Judge          string    `json:"judge"`
+	Notes          *string   `json:"notes"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type CreateInfractionRequest struct {
+	PlayerID             int     `json:"player_id" binding:"required"`
+	MatchID              *int    `json:"match_id"`
+	Type                 string  `json:"type" binding:"required,oneof=tardiness slow_play game_rule_violation procedural_error deck_problem marked_cards unsporting_conduct cheating other"`
+	Penalty              string  `json:"penalty" binding:"required,oneof=warning game_loss match_loss disqualification"`
+	Judge                string  `json:"judge" binding:"required"`
+	Notes                *string `json:"notes"`
+	TimeExtensionMinutes int     `json:"time_extension_minutes" binding:"omitempty,min=1,max=60"`
+}
+
+// InfractionResponse is a recorded infraction with the player's previous ones.
+// RepeatOffender is set when the player already had an infraction of the same type.
+type InfractionResponse struct {
+	Infraction                 Infraction   `json:"infraction"`
+	Match                      *MatchDetail `json:"match"`
+	PreviousSameType           int          `json:"previous_same_type"`
+	PreviousSameTypeTournament int          `json:"previous_same_type_tournament"`
+	PreviousTotal              int          `json:"previous_total"`
+	RepeatOffender             bool         `json:"repeat_offender"`
+	MatchesForfeited           int          `json:"matches_forfeited"`
+}
+
+// PlayerInfractionHistory lists every infraction of a player across tournaments
+type PlayerInfractionHistory struct {
+	PlayerName  string         `json:"player_name"`
+	Total       int            `json:"total"`
+	ByType      map[string]int `json:"by_type"`
+	ByPenalty   map[string]int `json:"by_penalty"`
+	Infractions []Infraction   `json:"infractions"`
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed" // gave up after the maximum number of attempts
+)
+
+// Webhook is a URL called on tournament events. The secret is only returned when
+// the webhook is created or its secret rotated.
+type Webhook struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateWebhookRequest registers a webhook; a secret is generated if none is given
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=128"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Description *string  `json:"description"`
+}
+
+// UpdateWebhookRequest changes the given fields of a webhook
+type UpdateWebhookRequest struct {
+	URL          *string  `json:"url" binding:"omitempty,url"`
+	Events       []string `json:"events" binding:"omitempty,min=1"`
+	Active       *bool    `json:"active"`
+	Description  *string  `json:"description"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	ResponseStatus *int            `json:"response_status"`
+	LastError      *string         `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// DiscordLink ties a Discord account to a premier player, for the Discord bot
+type DiscordLink struct {
+	DiscordUserID   string    `json:"discord_user_id"`
+	PlayerID        int       `json:"player_id"`
+	PlayerName      string    `json:"player_name"`
+	DiscordUsername *string   `json:"discord_username"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type LinkDiscordRequest struct {
+	PlayerID        int     `json:"player_id" binding:"required"`
+	DiscordUsername *string `json:"discord_username"`
+}
+
+// DiscordCommandRequest runs a bot command as a Discord user, e.g. "/report 2-1"
+type DiscordCommandRequest struct {
+	DiscordUserID string `json:"discord_user_id" binding:"required"`
+	Text          string `json:"text" binding:"required"`
+}
+
+// Notification statuses
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed" // gave up after the maximum number of attempts
+)
+
+// PlayerContact is how and about what a premier player wants to be notified
+type PlayerContact struct {
+	PlayerID          int       `json:"player_id"`
+	PlayerName        string    `json:"player_name"`
+	Email             *string   `json:"email"`
+	Locale            string    `json:"locale"`
+	NotifyPairings    bool      `json:"notify_pairings"`
+	NotifyDeadlines   bool      `json:"notify_deadlines"`
+	NotifyResults     bool      `json:"notify_results"`
+	NotifyTournaments bool      `json:"notify_tournaments"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// UpdatePlayerContactRequest changes the given contact fields; a new contact starts
+// in Spanish with every notification on
+type UpdatePlayerContactRequest struct {
+	Email             *string `json:"email" binding:"omitempty,email"`
+	Locale            *string `json:"locale" binding:"omitempty,oneof=es en"`
+	NotifyPairings    *bool   `json:"notify_pairings"`
+	NotifyDeadlines   *bool   `json:"notify_deadlines"`
+	NotifyResults     *bool   `json:"notify_results"`
+	NotifyTournaments *bool   `json:"notify_tournaments"`
+}
+
+type Notification struct {
+	ID            int        `json:"id"`
+	PlayerID      *int       `json:"player_id"`
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Template      string     `json:"template"`
+	Locale        string     `json:"locale"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	LastError     *string    `json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// GlobalStanding is the all-time record of a premier player
+type GlobalStanding struct {
+	PlayerID         int     `json:"player_id"`
+	PlayerName       string  `json:"player_name"`
+	FirstPlaceCount  int     `json:"first_place_count"`
+	SecondPlaceCount int     `json:"second_place_count"`
+	ThirdPlaceCount  int     `json:"third_place_count"`
+	MostPlayedRacePB *string `json:"most_played_race_pb"`
+	MostPlayedRaceBF *string `json:"most_played_race_bf"`
+	WinratePB        float64 `json:"winrate_pb"`
+	WinrateBF        float64 `json:"winrate_bf"`
+}
+
+// GlobalRaces counts the races played in each format, with their winrate in percent
+type GlobalRaces struct {
+	PBRaces        map[string]int     `json:"pb_races"`
+	BFRaces        map[string]int     `json:"bf_races"`
+	PBRaceWinrates map[string]float64 `json:"pb_race_winrates"`
+	BFRaceWinrates map[string]float64 `json:"bf_race_winrates"`
+}
+
+// API v2 shapes. Players are always player_id/player_name, rounds round_number, and
+// standings carry their position.
+
+// PremierPlayer is a player of the online tournaments
+type PremierPlayer struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// StandingV2 is a row of the current or of an archived tournament's standings
+type StandingV2 struct {
+	Position          int     `json:"position"`
+	PlayerID          int     `json:"player_id"`
+	PlayerName        string  `json:"player_name"`
+	Status            string  `json:"status,omitempty"`
+	MatchesPlayed     int     `json:"matches_played"`
+	Wins              int     `json:"wins"`
+	Ties              int     `json:"ties"`
+	Losses            int     `json:"losses"`
+	Points            int     `json:"points"`
+	TotalPointsScored int     `json:"total_points_scored"`
+	TotalMatches      int     `json:"total_matches"`
+	RacePB            *string `json:"race_pb,omitempty"`
+	RaceBF            *string `json:"race_bf,omitempty"`
+}
+
+type RoundV2 struct {
+	RoundNumber      int           `json:"round_number"`
+	Format           string        `json:"format"`
+	Status           string        `json:"status"`
+	TotalMatches     int           `json:"total_matches"`
+	CompletedMatches int           `json:"completed_matches"`
+	LockedAt         *time.Time    `json:"locked_at"`
+	Matches          []MatchDetail `json:"matches"`
+}
+
+type FixtureV2 struct {
+	Rounds           []RoundV2 `json:"rounds"`
+	CurrentRound     *int      `json:"current_round"`
+	CompletedRounds  int       `json:"completed_rounds"`
+	TotalMatches     int       `json:"total_matches"`
+	CompletedMatches int       `json:"completed_matches"`
+}
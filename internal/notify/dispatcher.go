@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/outbox"
+)
+
+// ConfigFromEnv reads NOTIFY_DISPATCH_INTERVAL (default 30s), NOTIFY_TIMEOUT
+// (default 30s) and NOTIFY_MAX_ATTEMPTS (default 5). Retries back off from 1m up
+// to 6h.
+func ConfigFromEnv() outbox.Config {
+	return outbox.ConfigFromEnv("NOTIFY", outbox.Config{
+		Interval:    30 * time.Second,
+		Timeout:     30 * time.Second,
+		MaxAttempts: 5,
+		BackoffBase: time.Minute,
+		BackoffCap:  6 * time.Hour,
+	})
+}
+
+// notifications is the notifications queue
+var notifications = outbox.New[Message]("notification", outbox.Table[Message]{
+	Name:    "notifications",
+	Columns: "q.recipient, q.subject, q.body",
+	Fields: func(m *Message) []interface{} {
+		return []interface{}{&m.To, &m.Subject, &m.Body}
+	},
+	SentStatus: models.NotificationSent,
+	SentColumn: "sent_at",
+})
+
+// Wake tells the dispatcher that notifications are due
+func Wake() {
+	notifications.Wake()
+}
+
+// Start runs the dispatcher in a background goroutine until ctx is cancelled
+func Start(ctx context.Context, cfg outbox.Config, sender Sender) {
+	notifications.Start(ctx, cfg, send(sender))
+}
+
+// send sends notifications with sender
+func send(sender Sender) outbox.SendFunc[Message] {
+	return func(ctx context.Context, item outbox.Item[Message]) (int, error) {
+		msg := item.Data
+		msg.ID = item.ID
+		return 0, sender.Send(ctx, msg)
+	}
+}
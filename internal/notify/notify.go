@@ -0,0 +1,265 @@
+// Package notify emails players about their tournaments: published pairings,
+// approaching online deadlines, reported results and tournament status changes.
+// Players choose the notifications they get and their language in player_contacts.
+// Messages are rendered from templates when queued in the notifications table and
+// sent by a background dispatcher with retries.
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/webhooks"
+)
+
+// Kinds of notification a player can turn off
+const (
+	KindPairings    = "pairings"
+	KindDeadlines   = "deadlines"
+	KindResults     = "results"
+	KindTournaments = "tournaments"
+	KindAlways      = "" // sent whatever the preferences, e.g. tests
+)
+
+// preferenceColumns maps every kind to its player_contacts column
+var preferenceColumns = map[string]string{
+	KindPairings:    "notify_pairings",
+	KindDeadlines:   "notify_deadlines",
+	KindResults:     "notify_results",
+	KindTournaments: "notify_tournaments",
+	KindAlways:      "true",
+}
+
+// Queue renders a template for a player in their language and queues it in db, if
+// the player has an email and wants that kind of notification. It returns the queued
+// notification ID, or 0 when nothing was queued. "Player" is added to data. Call Wake
+// once the notification is committed.
+func Queue(ctx context.Context, db database.Querier, playerID int, kind, name string, data map[string]interface{}) (int, error) {
+	column, ok := preferenceColumns[kind]
+	if !ok {
+		return 0, fmt.Errorf("unknown notification kind %q", kind)
+	}
+
+	var email, locale, playerName string
+	err := db.QueryRowContext(ctx, `
+		SELECT pc.email, pc.locale, pp.name
+		FROM player_contacts pc
+		JOIN premier_players pp ON pp.id = pc.player_id
+		WHERE pc.player_id = $1 AND pc.email IS NOT NULL AND `+column,
+		playerID,
+	).Scan(&email, &locale, &playerName)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["Player"] = playerName
+	subject, body, err := Render(locale, name, data)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO notifications (player_id, recipient, template, locale, subject, body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, playerID, email, name, locale, subject, body).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// QueueEvent is a webhooks queue: it queues the notifications of a tournament event
+// in the transaction of the change that caused it
+func QueueEvent(ctx context.Context, tx database.Querier, event string, data interface{}) error {
+	switch event {
+	case webhooks.EventRoundCreated:
+		if round, ok := data.(models.FixtureRound); ok {
+			return pairingsPublished(ctx, tx, round)
+		}
+	case webhooks.EventMatchCompleted:
+		if d, ok := data.(webhooks.MatchData); ok {
+			switch m := d.Match.(type) {
+			case *models.OnlineTournamentMatch:
+				return resultReported(ctx, tx, *m)
+			case models.OnlineTournamentMatch:
+				return resultReported(ctx, tx, m)
+			}
+		}
+	case webhooks.EventTournamentStatusChanged:
+		if t, ok := data.(webhooks.TournamentData); ok && t.Type == "ONLINE" {
+			return tournamentStatusChanged(ctx, tx, t)
+		}
+	}
+	return nil
+}
+
+// OnEvent is a webhooks listener waking the dispatcher for the notifications
+// QueueEvent queued
+func OnEvent(event string, data interface{}) {
+	Wake()
+}
+
+// pairingsPublished tells every player of a new in-person round their opponent and
+// table. In-person players are matched to premier players by name.
+func pairingsPublished(ctx context.Context, tx database.Querier, round models.FixtureRound) error {
+	for _, m := range round.Matches {
+		table := ""
+		if m.TableNumber != nil {
+			table = strconv.Itoa(*m.TableNumber)
+		}
+		for _, p := range [][2]string{{m.Player1Name, m.Player2Name}, {m.Player2Name, m.Player1Name}} {
+			player, opponent := p[0], p[1]
+			if player == "BYE" {
+				continue
+			}
+			playerID, err := premierPlayerID(ctx, tx, player)
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			_, err = Queue(ctx, tx, playerID, KindPairings, TemplatePairings, map[string]interface{}{
+				"Round":    round.Number,
+				"Format":   round.Format,
+				"Opponent": opponent,
+				"Table":    table,
+				"Bye":      opponent == "BYE",
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeadlineReminder is a scheduler reminder hook telling both players that their
+// online match is due soon. A failure is only logged.
+func DeadlineReminder(m models.OnlineTournamentMatch) {
+	ctx := context.Background()
+	tournament, err := tournamentName(ctx, database.DB, m.TournamentID)
+	if err != nil {
+		slog.Warn("failed to fetch tournament", "tournament_id", m.TournamentID, "error", err)
+		return
+	}
+	deadline := ""
+	if m.Deadline != nil {
+		deadline = m.Deadline.In(time.Local).Format("02/01/2006 15:04 MST")
+	}
+	matchday := ""
+	if m.Matchday != nil {
+		matchday = strconv.Itoa(*m.Matchday)
+	}
+
+	for _, p := range []struct {
+		id       int
+		opponent string
+	}{{m.Player1ID, m.Player2Name}, {m.Player2ID, m.Player1Name}} {
+		_, err := Queue(ctx, database.DB, p.id, KindDeadlines, TemplateDeadline, map[string]interface{}{
+			"Opponent":   p.opponent,
+			"Tournament": tournament,
+			"Matchday":   matchday,
+			"Deadline":   deadline,
+		})
+		if err != nil {
+			slog.Warn("failed to queue notification", "template", TemplateDeadline, "player_id", p.id, "error", err)
+		}
+	}
+	Wake()
+}
+
+// resultReported tells both players the result entered for their online match, so a
+// wrong report by the opponent is noticed
+func resultReported(ctx context.Context, tx database.Querier, m models.OnlineTournamentMatch) error {
+	tournament, err := tournamentName(ctx, tx, m.TournamentID)
+	if err != nil {
+		return err
+	}
+
+	result := fmt.Sprintf("%s vs %s", m.Player1Name, m.Player2Name)
+	if m.Score1 != nil && m.Score2 != nil {
+		result = fmt.Sprintf("%s %d-%d %s", m.Player1Name, *m.Score1, *m.Score2, m.Player2Name)
+	}
+	for _, p := range []struct {
+		id       int
+		opponent string
+	}{{m.Player1ID, m.Player2Name}, {m.Player2ID, m.Player1Name}} {
+		_, err := Queue(ctx, tx, p.id, KindResults, TemplateResult, map[string]interface{}{
+			"Opponent":   p.opponent,
+			"Tournament": tournament,
+			"Result":     result,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tournamentStatusChanged tells the players of an online tournament that it opened,
+// started or finished
+func tournamentStatusChanged(ctx context.Context, tx database.Querier, t webhooks.TournamentData) error {
+	tournament, err := tournamentName(ctx, tx, t.TournamentID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT player_id FROM online_tournament_players WHERE tournament_id = $1",
+		t.TournamentID,
+	)
+	if err != nil {
+		return err
+	}
+	var playerIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		playerIDs = append(playerIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range playerIDs {
+		_, err := Queue(ctx, tx, id, KindTournaments, TemplateTournament, map[string]interface{}{
+			"Tournament": tournament,
+			"Status":     t.Status,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func premierPlayerID(ctx context.Context, db database.Querier, name string) (int, error) {
+	var id int
+	err := db.QueryRowContext(ctx, "SELECT id FROM premier_players WHERE LOWER(name) = LOWER($1)", name).Scan(&id)
+	return id, err
+}
+
+func tournamentName(ctx context.Context, db database.Querier, tournamentID int) (string, error) {
+	var name string
+	err := db.QueryRowContext(ctx, "SELECT name FROM tournaments WHERE id = $1", tournamentID).Scan(&name)
+	return name, err
+}
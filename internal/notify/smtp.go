@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is an email ready to send
+type Message struct {
+	ID      int // notification ID, used in the Message-ID header
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPConfig is the mail server notifications are sent through
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string // "Premier Mitológico <torneos@example.com>"
+}
+
+// SMTPConfigFromEnv reads SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME,
+// SMTP_PASSWORD and SMTP_FROM
+func SMTPConfigFromEnv() SMTPConfig {
+	cfg := SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     587,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if value := os.Getenv("SMTP_PORT"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			slog.Warn("invalid SMTP_PORT, using default", "value", value, "default", cfg.Port)
+		} else {
+			cfg.Port = port
+		}
+	}
+	if cfg.From == "" {
+		cfg.From = "Premier Mitológico <no-reply@localhost>"
+	}
+	return cfg
+}
+
+// SMTPSender sends messages through an SMTP server, with STARTTLS when the server
+// offers it and authentication when a username is set. A local SMTP sink without
+// TLS or authentication works too.
+type SMTPSender struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+// NewSMTPSender checks the sender address of cfg
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+	return &SMTPSender{cfg: cfg, from: from}, nil
+}
+
+// Send delivers a message, giving up when ctx is done
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(s.compose(to, msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose writes a plain text UTF-8 email
+func (s *SMTPSender) compose(to *mail.Address, msg Message) []byte {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(s.from.Address, "@"); ok {
+		domain = d
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", s.from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <notification-%d.%d@%s>\r\n", msg.ID, time.Now().UnixNano(), domain)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+// LogSender writes messages to the log instead of sending them
+type LogSender struct{}
+
+// Send logs the message
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	slog.Info("notification", "notification_id", msg.ID, "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// NewSender returns an SMTPSender when SMTP_HOST is set, and a LogSender otherwise so
+// notifications can be followed in development without a mail server
+func NewSender(cfg SMTPConfig) (Sender, error) {
+	if cfg.Host == "" {
+		slog.Info("SMTP_HOST not set, notifications are written to the log")
+		return LogSender{}, nil
+	}
+	return NewSMTPSender(cfg)
+}
@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/outbox"
+)
+
+// sink is an SMTP server in the test, speaking just enough SMTP for net/smtp like
+// cmd/smtp-sink; with reject set, it refuses every message with a temporary error
+type sink struct {
+	listener net.Listener
+	reject   bool
+
+	mu       sync.Mutex
+	received []received
+}
+
+type received struct {
+	from, to string
+	data     string
+}
+
+func newSink(t *testing.T, reject bool) *sink {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &sink{listener: listener, reject: reject}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *sink) config() SMTPConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return SMTPConfig{Host: addr.IP.String(), Port: addr.Port, From: "Premier Mitológico <torneos@example.com>"}
+}
+
+func (s *sink) messages() []received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]received(nil), s.received...)
+}
+
+func (s *sink) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 sink ready")
+	var from, to string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250 sink")
+		case "MAIL":
+			from = strings.TrimPrefix(arg, "FROM:")
+			reply("250 OK")
+		case "RCPT":
+			to = strings.TrimPrefix(arg, "TO:")
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var b strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(line, "."))
+			}
+			if s.reject {
+				reply("451 Try again later")
+				continue
+			}
+			s.mu.Lock()
+			s.received = append(s.received, received{from: from, to: to, data: b.String()})
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// memoryStore is an outbox.Store of messages in memory
+type memoryStore struct {
+	items    []outbox.Item[Message]
+	outcomes []outbox.Outcome
+}
+
+func (s *memoryStore) Claim(_ context.Context, limit int, _ time.Duration) ([]outbox.Item[Message], error) {
+	batch := s.items
+	s.items = nil
+	return batch, nil
+}
+
+func (s *memoryStore) Record(_ context.Context, _ outbox.Item[Message], outcome outbox.Outcome) error {
+	s.outcomes = append(s.outcomes, outcome)
+	return nil
+}
+
+var testConfig = outbox.Config{Interval: time.Second, Timeout: 5 * time.Second, MaxAttempts: 3, BackoffBase: time.Minute, BackoffCap: time.Hour}
+
+func TestRenderedMessageReachesSMTPSink(t *testing.T) {
+	s := newSink(t, false)
+	sender, err := NewSMTPSender(s.config())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, body, err := Render("es", TemplateResult, map[string]interface{}{
+		"Player":     "Iñaki",
+		"Opponent":   "Ana",
+		"Tournament": "Liga Online Otoño",
+		"Result":     "Iñaki 2-1 Ana",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memoryStore{items: []outbox.Item[Message]{
+		{ID: 12, Data: Message{To: "Iñaki <inaki@example.com>", Subject: subject, Body: body}},
+	}}
+	if _, err := outbox.New[Message]("notification", store).RunOnce(context.Background(), testConfig, send(sender)); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.outcomes) != 1 || store.outcomes[0].Status != outbox.Sent {
+		t.Fatalf("outcomes = %+v, want one sent", store.outcomes)
+	}
+
+	messages := s.messages()
+	if len(messages) != 1 {
+		t.Fatalf("sink received %d messages, want 1", len(messages))
+	}
+	got := messages[0]
+	if got.from != "<torneos@example.com>" || got.to != "<inaki@example.com>" {
+		t.Errorf("envelope from %s to %s", got.from, got.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotSubject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Resultado registrado: Iñaki 2-1 Ana"; gotSubject != want {
+		t.Errorf("subject %q, want %q", gotSubject, want)
+	}
+	if !strings.HasPrefix(msg.Header.Get("Message-ID"), "<notification-12.") {
+		t.Errorf("Message-ID %q does not name the notification", msg.Header.Get("Message-ID"))
+	}
+	if ct := msg.Header.Get("Content-Type"); ct != "text/plain; charset=UTF-8" {
+		t.Errorf("Content-Type %q", ct)
+	}
+
+	gotBody, _ := io.ReadAll(msg.Body)
+	want := "Hola Iñaki:\n\nSe registró el resultado de tu partida contra Ana en Liga Online Otoño:\nIñaki 2-1 Ana\n\nSi no es correcto, avisa a un organizador.\n\nPremier Mitológico\n"
+	if body := strings.ReplaceAll(string(gotBody), "\r\n", "\n"); body != want {
+		t.Errorf("body %q, want %q", body, want)
+	}
+}
+
+func TestRejectedMessageIsRetried(t *testing.T) {
+	s := newSink(t, true)
+	sender, err := NewSMTPSender(s.config())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &memoryStore{items: []outbox.Item[Message]{
+		{ID: 1, Data: Message{To: "ana@example.com", Subject: "Notificación de prueba", Body: "Hola"}},
+	}}
+	if _, err := outbox.New[Message]("notification", store).RunOnce(context.Background(), testConfig, send(sender)); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.outcomes) != 1 {
+		t.Fatalf("recorded %d outcomes, want 1", len(store.outcomes))
+	}
+	got := store.outcomes[0]
+	if got.Status != outbox.Pending || got.Retry != testConfig.BackoffBase || got.Err == nil || !strings.Contains(got.Err.Error(), "451") {
+		t.Errorf("outcome = %+v, want pending after a 451, retried in %v", got, testConfig.BackoffBase)
+	}
+	if n := len(s.messages()); n != 0 {
+		t.Errorf("sink kept %d rejected messages", n)
+	}
+}
@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// Templates of the notifications
+const (
+	TemplatePairings   = "pairings"   // a round was paired: opponent and table
+	TemplateDeadline   = "deadline"   // an online match deadline is approaching
+	TemplateResult     = "result"     // the result of an online match was entered
+	TemplateTournament = "tournament" // an online tournament changed status
+	TemplateTest       = "test"       // sent on request, to check a contact
+)
+
+// DefaultLocale is the language of players without a preference, and the fallback of
+// templates missing in a locale
+const DefaultLocale = "es"
+
+const signature = "Premier Mitológico"
+
+type messageTemplate struct {
+	subject string
+	body    string
+}
+
+// partials are shared by the subject and body templates of a locale
+var partials = map[string]string{
+	"es": `{{define "status"}}{{if eq .Status "registration"}}abrió sus inscripciones{{else if eq .Status "in_progress"}}comenzó{{else if eq .Status "completed"}}terminó{{else if eq .Status "archived"}}fue archivado{{else}}volvió a borrador{{end}}{{end}}`,
+	"en": `{{define "status"}}{{if eq .Status "registration"}}is open for registration{{else if eq .Status "in_progress"}}has started{{else if eq .Status "completed"}}has finished{{else if eq .Status "archived"}}was archived{{else}}is back to draft{{end}}{{end}}`,
+}
+
+// sources holds the subject and body of every template per locale. Data fields are
+// documented where each notification is queued.
+var sources = map[string]map[string]messageTemplate{
+	"es": {
+		TemplatePairings: {
+			subject: "Ronda {{.Round}} publicada",
+			body: `Hola {{.Player}}:
+
+Se publicaron los emparejamientos de la ronda {{.Round}} ({{.Format}}).
+{{if .Bye}}En esta ronda tienes BYE.{{else}}Juegas contra {{.Opponent}}{{if .Table}} en la mesa {{.Table}}{{end}}.{{end}}
+
+¡Suerte!
+` + signature,
+		},
+		TemplateDeadline: {
+			subject: "Tu partida contra {{.Opponent}} vence pronto",
+			body: `Hola {{.Player}}:
+
+El plazo para jugar tu partida contra {{.Opponent}} en {{.Tournament}}{{if .Matchday}} (fecha {{.Matchday}}){{end}} vence el {{.Deadline}}.
+Si no se juega a tiempo, se resolverá según las reglas del torneo.
+
+` + signature,
+		},
+		TemplateResult: {
+			subject: "Resultado registrado: {{.Result}}",
+			body: `Hola {{.Player}}:
+
+Se registró el resultado de tu partida contra {{.Opponent}} en {{.Tournament}}:
+{{.Result}}
+
+Si no es correcto, avisa a un organizador.
+
+` + signature,
+		},
+		TemplateTournament: {
+			subject: `{{.Tournament}}: {{template "status" .}}`,
+			body: `Hola {{.Player}}:
+
+El torneo {{.Tournament}} {{template "status" .}}.
+{{if eq .Status "in_progress"}}Revisa tus partidas pendientes y sus plazos.{{else if eq .Status "completed"}}Gracias por jugar. La tabla final ya está disponible.{{end}}
+
+` + signature,
+		},
+		TemplateTest: {
+			subject: "Notificación de prueba",
+			body: `Hola {{.Player}}:
+
+Este es un mensaje de prueba. Si lo recibes, tus notificaciones están bien configuradas.
+
+` + signature,
+		},
+	},
+	"en": {
+		TemplatePairings: {
+			subject: "Round {{.Round}} is out",
+			body: `Hi {{.Player}},
+
+The pairings of round {{.Round}} ({{.Format}}) are out.
+{{if .Bye}}You have a BYE this round.{{else}}You play {{.Opponent}}{{if .Table}} at table {{.Table}}{{end}}.{{end}}
+
+Good luck!
+` + signature,
+		},
+		TemplateDeadline: {
+			subject: "Your match against {{.Opponent}} is due soon",
+			body: `Hi {{.Player}},
+
+Your match against {{.Opponent}} in {{.Tournament}}{{if .Matchday}} (matchday {{.Matchday}}){{end}} is due {{.Deadline}}.
+If it is not played in time, it will be resolved according to the tournament rules.
+
+` + signature,
+		},
+		TemplateResult: {
+			subject: "Result reported: {{.Result}}",
+			body: `Hi {{.Player}},
+
+The result of your match against {{.Opponent}} in {{.Tournament}} was reported:
+{{.Result}}
+
+If it is wrong, please tell an organizer.
+
+` + signature,
+		},
+		TemplateTournament: {
+			subject: `{{.Tournament}}: {{template "status" .}}`,
+			body: `Hi {{.Player}},
+
+{{.Tournament}} {{template "status" .}}.
+{{if eq .Status "in_progress"}}Check your pending matches and their deadlines.{{else if eq .Status "completed"}}Thanks for playing. The final standings are available.{{end}}
+
+` + signature,
+		},
+		TemplateTest: {
+			subject: "Test notification",
+			body: `Hi {{.Player}},
+
+This is a test message. If you got it, your notifications are set up correctly.
+
+` + signature,
+		},
+	},
+}
+
+// parsed holds the compiled templates, keyed by locale and template name
+var parsed = func() map[string]map[string][2]*template.Template {
+	all := make(map[string]map[string][2]*template.Template)
+	for locale, templates := range sources {
+		all[locale] = make(map[string][2]*template.Template)
+		for name, src := range templates {
+			key := locale + "/" + name
+			all[locale][name] = [2]*template.Template{
+				template.Must(template.New(key + "/subject").Parse(partials[locale] + src.subject)),
+				template.Must(template.New(key + "/body").Parse(partials[locale] + src.body)),
+			}
+		}
+	}
+	return all
+}()
+
+// Render returns the subject and body of a template in a locale, or in DefaultLocale
+// when the locale has no such template
+func Render(locale, name string, data map[string]interface{}) (string, string, error) {
+	templates, ok := parsed[locale][name]
+	if !ok {
+		if templates, ok = parsed[DefaultLocale][name]; !ok {
+			return "", "", fmt.Errorf("unknown notification template %q", name)
+		}
+	}
+
+	var subject, body bytes.Buffer
+	if err := templates[0].Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := templates[1].Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
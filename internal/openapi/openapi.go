@@ -0,0 +1,382 @@
+// Package openapi builds an OpenAPI 3 document of the API from the gin route table
+// and the models DTOs, serves it with a Swagger UI, and validates requests against it.
+//
+// Routes say the method, path and path parameters of an operation. What a handler
+// reads and answers (query parameters, body, response) is described by an Endpoint
+// keyed by the handler function, so the document follows the routes in main.go and
+// the types of the handlers.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version of the OpenAPI specification the document follows
+const Version = "3.0.3"
+
+// Endpoint describes what a handler reads and answers beyond its route
+type Endpoint struct {
+	Handler     gin.HandlerFunc
+	Summary     string // defaults to the handler name in words
+	Description string
+	Tags        []string // default to the first path segment
+
+	Path    []Param // path parameters that are not integers
+	Query   []Param
+	Headers []Param
+
+	Body         interface{} // zero value of the JSON request body
+	BodyOptional bool        // the body may be left out
+	Files        []string    // multipart file fields
+	Consumes     []string    // other raw request content types, e.g. application/zip
+
+	Status   int         // success status, 200 by default
+	Response interface{} // zero value of the JSON success response
+	Produces []string    // non-JSON response content types
+	Error    interface{} // zero value of the JSON error response, the Error schema by default
+}
+
+// Param is a path, query or header parameter
+type Param struct {
+	Name        string
+	Type        string // integer, number, string or boolean
+	Enum        []string
+	Required    bool
+	Description string
+}
+
+// IntQuery is an optional integer query parameter
+func IntQuery(name, description string) Param {
+	return Param{Name: name, Type: "integer", Description: description}
+}
+
+// BoolQuery is an optional boolean query parameter
+func BoolQuery(name, description string) Param {
+	return Param{Name: name, Type: "boolean", Description: description}
+}
+
+// StringQuery is an optional string query parameter, restricted to enum when given
+func StringQuery(name, description string, enum ...string) Param {
+	return Param{Name: name, Type: "string", Enum: enum, Description: description}
+}
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// apiKeyScheme is the security scheme of the protected routes
+const apiKeyScheme = "ApiKey"
+
+// Spec collects routes into a Document. It is filled while the router is set up
+// and only read afterwards.
+type Spec struct {
+	mu        sync.RWMutex
+	doc       Document
+	schemas   *schemaBuilder
+	endpoints map[string]Endpoint // by handler name
+	routes    map[string]*Operation
+	rejects   []reject
+}
+
+// reject answers the invalid requests under a path prefix
+type reject struct {
+	prefix  string
+	respond func(c *gin.Context, problems []string)
+}
+
+// New starts a document described by the given endpoints
+func New(info Info, endpoints []Endpoint) *Spec {
+	s := &Spec{
+		doc: Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]map[string]*Operation{},
+			Components: Components{
+				Schemas: map[string]*Schema{
+					"Error": {
+						Type:       "object",
+						Properties: map[string]*Schema{"error": {Type: "string"}},
+						Required:   []string{"error"},
+					},
+				},
+				SecuritySchemes: map[string]*SecurityScheme{
+					apiKeyScheme: {Type: "apiKey", In: "header", Name: "X-API-Key"},
+				},
+			},
+		},
+		endpoints: make(map[string]Endpoint, len(endpoints)),
+		routes:    map[string]*Operation{},
+	}
+	s.schemas = &schemaBuilder{components: s.doc.Components.Schemas}
+	for _, e := range endpoints {
+		s.endpoints[handlerName(e.Handler)] = e
+	}
+	return s
+}
+
+// AddRoutes documents the routes not added yet. Call it after each route group,
+// with secured set for the groups behind the API key.
+func (s *Spec) AddRoutes(routes gin.RoutesInfo, secured bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range routes {
+		key := r.Method + " " + r.Path
+		if _, ok := s.routes[key]; ok {
+			continue
+		}
+		op := s.operation(r, secured)
+		s.routes[key] = op
+
+		path := openAPIPath(r.Path)
+		if s.doc.Paths[path] == nil {
+			s.doc.Paths[path] = map[string]*Operation{}
+		}
+		s.doc.Paths[path][strings.ToLower(r.Method)] = op
+	}
+}
+
+// operation builds the operation of a route from its endpoint, if described
+func (s *Spec) operation(r gin.RouteInfo, secured bool) *Operation {
+	name := r.Handler[strings.LastIndex(r.Handler, ".")+1:]
+	e := s.endpoints[r.Handler]
+
+	op := &Operation{
+		OperationID: strings.ToLower(name[:1]) + name[1:],
+		Summary:     e.Summary,
+		Description: e.Description,
+		Tags:        e.Tags,
+		Responses:   map[string]*Response{},
+	}
+	if op.Summary == "" {
+		op.Summary = words(name)
+	}
+	if len(op.Tags) == 0 {
+		op.Tags = []string{tag(r.Path)}
+	}
+
+	for _, segment := range strings.Split(r.Path, "/") {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+		p := Param{Name: segment[1:], Type: "integer", Required: true}
+		for _, override := range e.Path {
+			if override.Name == p.Name {
+				p = override
+				p.Required = true
+			}
+		}
+		op.Parameters = append(op.Parameters, parameter("path", p))
+	}
+	for _, p := range e.Query {
+		op.Parameters = append(op.Parameters, parameter("query", p))
+	}
+	for _, p := range e.Headers {
+		op.Parameters = append(op.Parameters, parameter("header", p))
+	}
+
+	if e.Body != nil || len(e.Files) > 0 || len(e.Consumes) > 0 {
+		body := &RequestBody{Required: !e.BodyOptional, Content: map[string]*MediaType{}}
+		if e.Body != nil {
+			body.Content["application/json"] = &MediaType{Schema: s.schemas.schema(reflect.TypeOf(e.Body))}
+		}
+		if len(e.Files) > 0 {
+			form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			for _, f := range e.Files {
+				form.Properties[f] = &Schema{Type: "string", Format: "binary"}
+			}
+			body.Content["multipart/form-data"] = &MediaType{Schema: form}
+		}
+		for _, ct := range e.Consumes {
+			body.Content[ct] = &MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+		op.RequestBody = body
+	}
+
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status), Content: map[string]*MediaType{}}
+	if e.Response != nil {
+		success.Content["application/json"] = &MediaType{Schema: s.schemas.schema(reflect.TypeOf(e.Response))}
+	} else if len(e.Produces) == 0 {
+		success.Content["application/json"] = &MediaType{Schema: &Schema{Type: "object"}}
+	}
+	for _, ct := range e.Produces {
+		success.Content[ct] = &MediaType{Schema: &Schema{Type: "string"}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errorSchema := &Schema{Ref: "#/components/schemas/Error"}
+	if e.Error != nil {
+		errorSchema = s.schemas.schema(reflect.TypeOf(e.Error))
+	}
+	errorContent := map[string]*MediaType{"application/json": {Schema: errorSchema}}
+	if len(op.Parameters) > 0 || op.RequestBody != nil {
+		op.Responses["400"] = &Response{Description: "Invalid request", Content: errorContent}
+	}
+	if secured {
+		op.Security = []map[string][]string{{apiKeyScheme: {}}}
+		op.Responses["401"] = &Response{Description: "Invalid or missing API key", Content: errorContent}
+	}
+	op.Responses["429"] = &Response{Description: "Too many requests; retry after the seconds in Retry-After", Content: errorContent}
+	op.Responses["default"] = &Response{Description: "Error", Content: errorContent}
+	return op
+}
+
+func parameter(in string, p Param) *Parameter {
+	schema := &Schema{Type: p.Type}
+	if schema.Type == "" {
+		schema.Type = "string"
+	}
+	for _, v := range p.Enum {
+		schema.Enum = append(schema.Enum, v)
+	}
+	return &Parameter{Name: p.Name, In: in, Description: p.Description, Required: p.Required, Schema: schema}
+}
+
+// RejectWith makes Validate answer the invalid requests under prefix with respond
+// instead of the default 400, for route groups with their own error format
+func (s *Spec) RejectWith(prefix string, respond func(c *gin.Context, problems []string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejects = append(s.rejects, reject{prefix: prefix, respond: respond})
+}
+
+// Document returns the document built so far
+func (s *Spec) Document() *Document {
+	return &s.doc
+}
+
+// ServeJSON answers with the document
+func (s *Spec) ServeJSON(c *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c.JSON(http.StatusOK, s.doc)
+}
+
+// Operations lists the documented "METHOD /path" routes, sorted
+func (s *Spec) Operations() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]string, 0, len(s.routes))
+	for key := range s.routes {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// lookup returns the operation of a gin route
+func (s *Spec) lookup(method, fullPath string) *Operation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.routes[method+" "+fullPath]
+}
+
+// handlerName is the name gin reports for a handler in its route table
+func handlerName(h gin.HandlerFunc) string {
+	return runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+}
+
+// openAPIPath turns /matches/:id into /matches/{id}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// tag groups operations by the first path segment after /api, online tournaments
+// apart and the versions after the first one by themselves
+func tag(path string) string {
+	segments := strings.Split(strings.TrimPrefix(strings.TrimPrefix(path, "/"), "api/"), "/")
+	if len(segments) > 1 && segments[0] == "tournaments" && segments[1] == "online" {
+		return "online tournaments"
+	}
+	if len(segments) > 1 && segments[0] == "v2" {
+		return "v2 " + segments[1]
+	}
+	return segments[0]
+}
+
+// words turns GetOnlineMatch into "Get online match"
+func words(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteRune(' ')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
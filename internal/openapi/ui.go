@@ -0,0 +1,49 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIVersion is the swagger-ui-dist release loaded from the CDN
+const swaggerUIVersion = "5.17.14"
+
+var swaggerUIPage = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+<script>
+window.onload = function () {
+	window.ui = SwaggerUIBundle({
+		url: {{.SpecURL}},
+		dom_id: "#swagger-ui",
+		persistAuthorization: true,
+	});
+};
+</script>
+</body>
+</html>
+`))
+
+// SwaggerUI serves a Swagger UI page for the document at specURL. The UI itself is
+// loaded from a CDN, so the server has no assets to ship.
+func SwaggerUI(title, specURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		swaggerUIPage.Execute(c.Writer, map[string]string{
+			"Title":   title,
+			"SpecURL": specURL,
+			"Version": swaggerUIVersion,
+		})
+	}
+}
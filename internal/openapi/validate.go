@@ -0,0 +1,317 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxValidatedBody is the largest JSON body read for validation; bigger bodies are
+// left to the handler
+const maxValidatedBody = 10 << 20
+
+// Validate is a middleware rejecting requests that do not match the document: path
+// and query parameters of the wrong type or outside their enum, and JSON bodies that
+// are missing, malformed or break their schema. Routes without an operation pass.
+// It answers 400 with the first problem in "error" and all of them in "details",
+// unless RejectWith set another answer for the path.
+func (s *Spec) Validate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := s.lookup(c.Request.Method, c.FullPath())
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		var problems []string
+		for _, p := range op.Parameters {
+			var value string
+			var present bool
+			switch p.In {
+			case "path":
+				value = c.Param(p.Name)
+				present = true
+			case "query":
+				value, present = c.GetQuery(p.Name)
+			default:
+				continue
+			}
+			if !present {
+				if p.Required {
+					problems = append(problems, fmt.Sprintf("%s parameter %s is required", p.In, p.Name))
+				}
+				continue
+			}
+			if problem := checkParam(p, value); problem != "" {
+				problems = append(problems, problem)
+			}
+		}
+
+		if op.RequestBody != nil {
+			problems = append(problems, s.checkBody(c, op.RequestBody)...)
+		}
+
+		if len(problems) > 0 {
+			s.rejectRequest(c, problems)
+			return
+		}
+		c.Next()
+	}
+}
+
+// rejectRequest answers an invalid request, in the format of its route group if one
+// was set with RejectWith
+func (s *Spec) rejectRequest(c *gin.Context, problems []string) {
+	s.mu.RLock()
+	rejects := s.rejects
+	s.mu.RUnlock()
+	for _, r := range rejects {
+		if strings.HasPrefix(c.Request.URL.Path, r.prefix) {
+			r.respond(c, problems)
+			c.Abort()
+			return
+		}
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": problems[0], "details": problems})
+}
+
+// checkParam checks a path or query parameter against its schema
+func checkParam(p *Parameter, value string) string {
+	switch p.Schema.Type {
+	case "integer":
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Sprintf("%s parameter %s must be an integer", p.In, p.Name)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Sprintf("%s parameter %s must be a number", p.In, p.Name)
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Sprintf("%s parameter %s must be true or false", p.In, p.Name)
+		}
+	}
+	if len(p.Schema.Enum) > 0 && !inEnum(p.Schema.Enum, value) {
+		return fmt.Sprintf("%s parameter %s must be one of %s", p.In, p.Name, enumList(p.Schema.Enum))
+	}
+	return ""
+}
+
+// checkBody validates a JSON body and puts it back for the handler. Bodies of the
+// other declared content types (uploads, zip files) are left to the handler.
+func (s *Spec) checkBody(c *gin.Context, body *RequestBody) []string {
+	media, ok := body.Content["application/json"]
+	if !ok {
+		return nil
+	}
+	if contentType := c.ContentType(); contentType != "application/json" {
+		if _, other := body.Content[contentType]; other {
+			return nil
+		}
+	}
+
+	original := c.Request.Body
+	raw, err := io.ReadAll(io.LimitReader(original, maxValidatedBody+1))
+	if err != nil {
+		return []string{"failed to read request body"}
+	}
+	if len(raw) > maxValidatedBody {
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(raw), original))
+		return nil
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if body.Required {
+			return []string{"request body is required"}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []string{"request body is not valid JSON: " + err.Error()}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v := validator{components: s.doc.Components.Schemas}
+	v.check("body", media.Schema, value)
+	return v.problems
+}
+
+// validator collects the problems of a JSON value against a schema
+type validator struct {
+	components map[string]*Schema
+	problems   []string
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.problems = append(v.problems, path+" "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) resolve(s *Schema) *Schema {
+	for s.Ref != "" {
+		s = v.components[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+func (v *validator) check(path string, s *Schema, value interface{}) {
+	s = v.resolve(s)
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			v.fail(path, "must not be null")
+		}
+		return
+	}
+	for _, sub := range s.AllOf {
+		v.check(path, sub, value)
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			v.fail(path, "must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				v.fail(path+"."+name, "is required")
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := s.Properties[name]; ok {
+				v.check(path+"."+name, prop, obj[name])
+			} else if s.AdditionalProperties != nil {
+				v.check(path+"."+name, s.AdditionalProperties, obj[name])
+			}
+		}
+
+	case "array":
+		list, ok := value.([]interface{})
+		if !ok {
+			v.fail(path, "must be an array")
+			return
+		}
+		if s.MinItems != nil && len(list) < *s.MinItems {
+			v.fail(path, "must have at least %d item(s)", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(list) > *s.MaxItems {
+			v.fail(path, "must have at most %d item(s)", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range list {
+				v.check(fmt.Sprintf("%s[%d]", path, i), s.Items, item)
+			}
+		}
+
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			v.fail(path, "must be a number")
+			return
+		}
+		f, err := n.Float64()
+		if err != nil {
+			v.fail(path, "must be a number")
+			return
+		}
+		if s.Type == "integer" {
+			if _, err := n.Int64(); err != nil {
+				v.fail(path, "must be an integer")
+				return
+			}
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			v.fail(path, "must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			v.fail(path, "must be at most %v", *s.Maximum)
+		}
+		if len(s.Enum) > 0 && !inEnum(s.Enum, n.String()) {
+			v.fail(path, "must be one of %s", enumList(s.Enum))
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			v.fail(path, "must be a string")
+			return
+		}
+		length := utf8.RuneCountInString(str)
+		if s.MinLength != nil && length < *s.MinLength {
+			v.fail(path, "must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			v.fail(path, "must be at most %d characters", *s.MaxLength)
+		}
+		if len(s.Enum) > 0 && !inEnum(s.Enum, str) {
+			v.fail(path, "must be one of %s", enumList(s.Enum))
+		}
+		if problem := checkFormat(s.Format, str); problem != "" {
+			v.fail(path, "%s", problem)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(path, "must be true or false")
+		}
+	}
+}
+
+// checkFormat checks the string formats the models use
+func checkFormat(format, value string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC 3339 date-time, e.g. 2026-10-19T18:00:00Z"
+		}
+	case "email":
+		if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+			return "must be an email address"
+		}
+	case "uri":
+		u, err := url.ParseRequestURI(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be an absolute URL"
+		}
+	}
+	return ""
+}
+
+func inEnum(enum []interface{}, value string) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func enumList(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		values[i] = fmt.Sprint(e)
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,166 @@
+// Package outbox sends queued items in the background: webhook deliveries and email
+// notifications. Items are written to a queue table in the transaction of the change
+// that caused them, so none is lost; a Dispatcher then claims the due ones, sends
+// them and records the outcome, retrying failures with backoff until the attempts
+// run out.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/env"
+)
+
+// Statuses of a queued item
+const (
+	Pending = "pending"
+	Sent    = "sent"
+	Failed  = "failed" // gave up after the maximum number of attempts
+)
+
+// batchSize is the number of items claimed per round
+const batchSize = 20
+
+// Config controls the sending of queued items
+type Config struct {
+	Interval    time.Duration // how often the queue is checked for due items
+	Timeout     time.Duration // how long sending a single item may take
+	MaxAttempts int           // attempts before an item is marked failed
+	BackoffBase time.Duration // wait after the first failed attempt, doubled after every next one
+	BackoffCap  time.Duration // longest wait between attempts
+}
+
+// ConfigFromEnv reads <prefix>_DISPATCH_INTERVAL, <prefix>_TIMEOUT and
+// <prefix>_MAX_ATTEMPTS, keeping the values of defaults for unset ones
+func ConfigFromEnv(prefix string, defaults Config) Config {
+	cfg := defaults
+	cfg.Interval = env.Duration(prefix+"_DISPATCH_INTERVAL", defaults.Interval)
+	cfg.Timeout = env.Duration(prefix+"_TIMEOUT", defaults.Timeout)
+	cfg.MaxAttempts = env.Int(prefix+"_MAX_ATTEMPTS", defaults.MaxAttempts, 1)
+	return cfg
+}
+
+// Backoff is the wait before the next attempt after the given number of failed ones
+func (cfg Config) Backoff(attempts int) time.Duration {
+	d := cfg.BackoffBase
+	for i := 1; i < attempts && d < cfg.BackoffCap; i++ {
+		d *= 2
+	}
+	if d > cfg.BackoffCap {
+		d = cfg.BackoffCap
+	}
+	return d
+}
+
+// Item is a queued item claimed for sending
+type Item[T any] struct {
+	ID       int
+	Attempts int // attempts made before this one
+	Data     T
+}
+
+// Outcome is the result of an attempt to send an item
+type Outcome struct {
+	Status   string        // Sent, Pending to retry, or Failed
+	Attempts int           // attempts made, this one included
+	Retry    time.Duration // wait before the next attempt, unless Sent
+	Code     int           // answer of the receiver, e.g. an HTTP status; 0 if none
+	Err      error         // why the attempt failed, unless Sent
+}
+
+// Store holds the queue
+type Store[T any] interface {
+	// Claim takes up to limit due items, so no other dispatcher picks them up for
+	// lease
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Item[T], error)
+	// Record saves the outcome of an attempt
+	Record(ctx context.Context, item Item[T], outcome Outcome) error
+}
+
+// SendFunc sends an item, returning the answer code of the receiver, if any
+type SendFunc[T any] func(ctx context.Context, item Item[T]) (int, error)
+
+// Dispatcher sends the items of a store
+type Dispatcher[T any] struct {
+	name   string
+	store  Store[T]
+	wakeup chan struct{}
+}
+
+// New returns a dispatcher of the items in store, named in its logs
+func New[T any](name string, store Store[T]) *Dispatcher[T] {
+	return &Dispatcher[T]{name: name, store: store, wakeup: make(chan struct{}, 1)}
+}
+
+// Wake tells the dispatcher that items are due, so it sends them without waiting for
+// its next tick
+func (d *Dispatcher[T]) Wake() {
+	select {
+	case d.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the dispatcher in a background goroutine until ctx is cancelled
+func (d *Dispatcher[T]) Start(ctx context.Context, cfg Config, send SendFunc[T]) {
+	slog.Info(d.name+" dispatcher running", "interval", cfg.Interval, "max_attempts", cfg.MaxAttempts)
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := d.RunOnce(ctx, cfg, send); err != nil {
+				slog.Warn(d.name+" dispatcher failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				slog.Info(d.name + " dispatcher stopped")
+				return
+			case <-ticker.C:
+			case <-d.wakeup:
+			}
+		}
+	}()
+}
+
+// RunOnce sends every due item a single time and returns how many were sent
+func (d *Dispatcher[T]) RunOnce(ctx context.Context, cfg Config, send SendFunc[T]) (int, error) {
+	sent := 0
+	for {
+		// The claim outlasts the send timeout, so an item being sent is not claimed again
+		batch, err := d.store.Claim(ctx, batchSize, 2*cfg.Timeout)
+		if err != nil {
+			return sent, fmt.Errorf("failed to claim items: %w", err)
+		}
+		for _, item := range batch {
+			sendCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+			code, err := send(sendCtx, item)
+			cancel()
+
+			outcome := Outcome{Status: Sent, Attempts: item.Attempts + 1, Code: code, Err: err}
+			if err != nil {
+				outcome.Status = Pending
+				outcome.Retry = cfg.Backoff(outcome.Attempts)
+				if outcome.Attempts >= cfg.MaxAttempts {
+					outcome.Status = Failed
+					slog.Warn(d.name+" failed for good", "id", item.ID, "attempts", outcome.Attempts, "error", err)
+				}
+			}
+			// Recorded even when ctx is cancelled, so a sent item is not sent again
+			if err := d.store.Record(context.WithoutCancel(ctx), item, outcome); err != nil {
+				return sent, fmt.Errorf("failed to record item %d: %w", item.ID, err)
+			}
+			if outcome.Status == Sent {
+				sent++
+			}
+		}
+		if len(batch) < batchSize || ctx.Err() != nil {
+			return sent, nil
+		}
+	}
+}
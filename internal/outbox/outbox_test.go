@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryStore is a Store in memory; items are due again right after a failure, so
+// retries can be run without waiting
+type memoryStore struct {
+	items    map[int]*memoryItem
+	recorded []Outcome
+}
+
+type memoryItem struct {
+	Item[string]
+	status string
+}
+
+func newMemoryStore(data ...string) *memoryStore {
+	s := &memoryStore{items: map[int]*memoryItem{}}
+	for i, d := range data {
+		s.items[i+1] = &memoryItem{Item: Item[string]{ID: i + 1, Data: d}, status: Pending}
+	}
+	return s
+}
+
+func (s *memoryStore) Claim(_ context.Context, limit int, _ time.Duration) ([]Item[string], error) {
+	var batch []Item[string]
+	for id := 1; id <= len(s.items) && len(batch) < limit; id++ {
+		if item := s.items[id]; item.status == Pending {
+			batch = append(batch, item.Item)
+		}
+	}
+	return batch, nil
+}
+
+func (s *memoryStore) Record(_ context.Context, item Item[string], outcome Outcome) error {
+	s.recorded = append(s.recorded, outcome)
+	stored := s.items[item.ID]
+	stored.status = outcome.Status
+	stored.Attempts = outcome.Attempts
+	return nil
+}
+
+var testConfig = Config{
+	Interval:    time.Second,
+	Timeout:     time.Second,
+	MaxAttempts: 3,
+	BackoffBase: 30 * time.Second,
+	BackoffCap:  time.Hour,
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{50, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := testConfig.Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestRunOnceRetriesUntilSent(t *testing.T) {
+	store := newMemoryStore("a")
+	failures := 2
+	send := func(ctx context.Context, item Item[string]) (int, error) {
+		if failures > 0 {
+			failures--
+			return 503, errors.New("unavailable")
+		}
+		return 200, nil
+	}
+
+	d := New[string]("test", store)
+	for round := 1; round <= 3; round++ {
+		sent, err := d.RunOnce(context.Background(), testConfig, send)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSent := 0
+		if round == 3 {
+			wantSent = 1
+		}
+		if sent != wantSent {
+			t.Errorf("round %d: sent %d, want %d", round, sent, wantSent)
+		}
+	}
+
+	want := []Outcome{
+		{Status: Pending, Attempts: 1, Retry: 30 * time.Second, Code: 503},
+		{Status: Pending, Attempts: 2, Retry: time.Minute, Code: 503},
+		{Status: Sent, Attempts: 3, Code: 200},
+	}
+	if len(store.recorded) != len(want) {
+		t.Fatalf("recorded %d outcomes, want %d", len(store.recorded), len(want))
+	}
+	for i, got := range store.recorded {
+		if got.Status != want[i].Status || got.Attempts != want[i].Attempts || got.Retry != want[i].Retry || got.Code != want[i].Code {
+			t.Errorf("outcome %d = %+v, want %+v", i, got, want[i])
+		}
+		if (got.Err == nil) != (want[i].Status == Sent) {
+			t.Errorf("outcome %d: error %v with status %s", i, got.Err, got.Status)
+		}
+	}
+}
+
+func TestRunOnceGivesUp(t *testing.T) {
+	store := newMemoryStore("a", "b")
+	send := func(ctx context.Context, item Item[string]) (int, error) {
+		if item.Data == "a" {
+			return 0, errors.New("connection refused")
+		}
+		return 0, nil
+	}
+
+	d := New[string]("test", store)
+	for round := 0; round < 5; round++ {
+		if _, err := d.RunOnce(context.Background(), testConfig, send); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if a := store.items[1]; a.status != Failed || a.Attempts != testConfig.MaxAttempts {
+		t.Errorf("failing item: status %s after %d attempts, want %s after %d", a.status, a.Attempts, Failed, testConfig.MaxAttempts)
+	}
+	if b := store.items[2]; b.status != Sent || b.Attempts != 1 {
+		t.Errorf("other item: status %s after %d attempts, want %s after 1", b.status, b.Attempts, Sent)
+	}
+	if n := len(store.recorded); n != testConfig.MaxAttempts+1 {
+		t.Errorf("recorded %d outcomes, want %d", n, testConfig.MaxAttempts+1)
+	}
+}
+
+func TestRunOnceTimesOutSend(t *testing.T) {
+	store := newMemoryStore("slow")
+	cfg := testConfig
+	cfg.Timeout = 10 * time.Millisecond
+	send := func(ctx context.Context, item Item[string]) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+
+	if _, err := New[string]("test", store).RunOnce(context.Background(), cfg, send); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.recorded[0]; got.Status != Pending || !errors.Is(got.Err, context.DeadlineExceeded) {
+		t.Errorf("outcome = %+v, want pending after the timeout", got)
+	}
+}
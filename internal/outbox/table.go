@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+)
+
+// Table is a Store in a queue table with the columns id, status, attempts,
+// next_attempt_at, last_attempt_at and last_error. The table is aliased q in Join,
+// Where and Columns.
+type Table[T any] struct {
+	Name       string                      // the queue table
+	Join       string                      // tables joined for Where and Columns, e.g. "JOIN webhooks w ON w.id = q.webhook_id"
+	Where      string                      // what else makes a row due, if anything
+	Columns    string                      // columns of a claimed row, read into the fields of T
+	Fields     func(data *T) []interface{} // pointers to the fields of T, in the order of Columns
+	SentStatus string                      // status of sent rows, if not Sent
+	SentColumn string                      // time a row was sent
+	CodeColumn string                      // answer code of the receiver, if the table keeps it
+}
+
+func (t Table[T]) Claim(ctx context.Context, limit int, lease time.Duration) ([]Item[T], error) {
+	where := ""
+	if t.Where != "" {
+		where = "AND " + t.Where
+	}
+	rows, err := database.DB.QueryContext(ctx, fmt.Sprintf(`
+		WITH due AS (
+			SELECT q.id
+			FROM %[1]s q %[2]s
+			WHERE q.status = '%[3]s' AND q.next_attempt_at <= CURRENT_TIMESTAMP %[4]s
+			ORDER BY q.next_attempt_at, q.id
+			LIMIT $2
+			FOR UPDATE OF q SKIP LOCKED
+		), claimed AS (
+			UPDATE %[1]s q
+			SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $1)
+			FROM due
+			WHERE q.id = due.id
+			RETURNING q.*
+		)
+		SELECT q.id, q.attempts, %[5]s
+		FROM claimed q %[2]s
+		ORDER BY q.next_attempt_at, q.id
+	`, t.Name, t.Join, Pending, where, t.Columns), lease.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []Item[T]
+	for rows.Next() {
+		var item Item[T]
+		dest := append([]interface{}{&item.ID, &item.Attempts}, t.Fields(&item.Data)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		batch = append(batch, item)
+	}
+	return batch, rows.Err()
+}
+
+func (t Table[T]) Record(ctx context.Context, item Item[T], outcome Outcome) error {
+	status := outcome.Status
+	if status == Sent && t.SentStatus != "" {
+		status = t.SentStatus
+	}
+
+	set := "status = $1, attempts = $2, last_attempt_at = CURRENT_TIMESTAMP"
+	args := []interface{}{status, outcome.Attempts}
+	if outcome.Status == Sent {
+		set += ", " + t.SentColumn + " = CURRENT_TIMESTAMP, last_error = NULL"
+	} else {
+		args = append(args, outcome.Retry.Seconds(), outcome.Err.Error())
+		set += ", next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $3), last_error = $4"
+	}
+	if t.CodeColumn != "" {
+		var code *int
+		if outcome.Code != 0 {
+			code = &outcome.Code
+		}
+		args = append(args, code)
+		set += fmt.Sprintf(", %s = $%d", t.CodeColumn, len(args))
+	}
+	args = append(args, item.ID)
+
+	_, err := database.DB.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", t.Name, set, len(args)), args...)
+	return err
+}
@@ -0,0 +1,70 @@
+// Package playerstats maintains player_format_stats: the matches, wins, ties and
+// losses of every player in every tournament, by format. It is the summary the
+// statistics endpoints read, so it is updated in the same transaction as the matches
+// it counts, and can be rebuilt from scratch with cmd/rebuild-stats.
+package playerstats
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// resultsQuery inserts the summary of the tournaments matching the %s condition on
+// t. In-person tournaments count their archived matches by round format, online ones
+// their matches in the tournament format. Void matches don't count and a double loss
+// is a loss for both players.
+const resultsQuery = `
+	INSERT INTO player_format_stats (tournament_id, player_id, player_name, format, matches, wins, ties, losses)
+	SELECT
+		r.tournament_id, r.player_id, r.player_name, r.format,
+		COUNT(*),
+		SUM(CASE WHEN r.result_type <> 'double_loss' AND r.scored > r.conceded THEN 1 ELSE 0 END),
+		SUM(CASE WHEN r.result_type <> 'double_loss' AND r.scored = r.conceded THEN 1 ELSE 0 END),
+		SUM(CASE WHEN r.result_type = 'double_loss' OR r.scored < r.conceded THEN 1 ELSE 0 END)
+	FROM (
+		SELECT
+			ts.tournament_id, ts.player_id, ts.player_name, tr.format, tm.result_type,
+			CASE WHEN tm.player1_id = ts.player_id THEN tm.score1 ELSE tm.score2 END AS scored,
+			CASE WHEN tm.player1_id = ts.player_id THEN tm.score2 ELSE tm.score1 END AS conceded
+		FROM tournaments t
+		JOIN tournament_standings ts ON ts.tournament_id = t.id
+		JOIN tournament_rounds tr ON tr.tournament_id = t.id AND tr.format IN ('PB', 'BF')
+		JOIN tournament_matches tm ON tm.tournament_round_id = tr.id
+			AND (tm.player1_id = ts.player_id OR tm.player2_id = ts.player_id)
+		WHERE t.type <> 'ONLINE' AND tm.completed AND tm.result_type <> 'void' AND %[1]s
+		UNION ALL
+		SELECT
+			otp.tournament_id, otp.player_id, otp.player_name, t.format, otm.result_type,
+			CASE WHEN otm.player1_id = otp.player_id THEN otm.score1 ELSE otm.score2 END AS scored,
+			CASE WHEN otm.player1_id = otp.player_id THEN otm.score2 ELSE otm.score1 END AS conceded
+		FROM tournaments t
+		JOIN online_tournament_players otp ON otp.tournament_id = t.id
+		JOIN online_tournament_matches otm ON otm.tournament_id = t.id
+			AND (otm.player1_id = otp.player_id OR otm.player2_id = otp.player_id)
+		WHERE t.type = 'ONLINE' AND t.format IS NOT NULL AND otm.completed AND otm.result_type <> 'void' AND %[1]s
+	) r
+	GROUP BY r.tournament_id, r.player_id, r.player_name, r.format
+`
+
+// RefreshTournament recomputes the summary of one tournament. Call it inside the
+// transaction that changes its matches, standings or players.
+func RefreshTournament(ctx context.Context, tx *sql.Tx, tournamentID int) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM player_format_stats WHERE tournament_id = $1", tournamentID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(resultsQuery, "t.id = $1"), tournamentID)
+	return err
+}
+
+// Rebuild recomputes the summary of every tournament and returns the rows written
+func Rebuild(ctx context.Context, tx *sql.Tx) (int64, error) {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM player_format_stats"); err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(resultsQuery, "true"))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
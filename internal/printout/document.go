@@ -0,0 +1,183 @@
+// Package printout renders printable sheets for the venue (pairings, standings and
+// match result slips) as HTML or PDF.
+package printout
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+)
+
+// ByeName is the placeholder opponent of a player without a match in a round
+const ByeName = "BYE"
+
+// Document is a printable sheet made of pages, each with a table or with result slips
+type Document struct {
+	Title       string
+	GeneratedAt time.Time
+	Pages       []Page
+}
+
+type Page struct {
+	Heading string
+	Table   *Table
+	Slips   []Slip
+}
+
+// Table is a grid of text cells. Widths are relative column widths.
+type Table struct {
+	Columns []string
+	Widths  []float64
+	Rows    [][]string
+}
+
+// Slip is a match result slip the players fill in and sign
+type Slip struct {
+	Round   int
+	Format  string
+	Table   string
+	Player1 string
+	Player2 string
+}
+
+type pairing struct {
+	table   string
+	player1 string
+	player2 string
+	result  string
+}
+
+// roundPairings uses the table numbers assigned to the round. Rounds without them
+// (seated before tables were tracked) are numbered in match order. Matches against
+// BYE get no table.
+func roundPairings(round models.FixtureRound) []pairing {
+	seated := false
+	for _, m := range round.Matches {
+		if m.TableNumber != nil {
+			seated = true
+			break
+		}
+	}
+
+	pairings := make([]pairing, 0, len(round.Matches))
+	table := 0
+	for _, m := range round.Matches {
+		p := pairing{table: "-", player1: m.Player1Name, player2: m.Player2Name}
+		switch {
+		case seated && m.TableNumber != nil:
+			p.table = strconv.Itoa(*m.TableNumber)
+		case !seated && !isBye(m.Player1Name) && !isBye(m.Player2Name):
+			table++
+			p.table = strconv.Itoa(table)
+		}
+		if m.Completed && m.Score1 != nil && m.Score2 != nil {
+			p.result = fmt.Sprintf("%d - %d", *m.Score1, *m.Score2)
+		}
+		pairings = append(pairings, p)
+	}
+	return pairings
+}
+
+func isBye(name string) bool {
+	return strings.EqualFold(strings.TrimSpace(name), ByeName)
+}
+
+func roundHeading(round models.FixtureRound) string {
+	return fmt.Sprintf("Ronda %d (%s)", round.Number, round.Format)
+}
+
+// PairingsByTable lists the matches of every round by table, one page per round
+func PairingsByTable(title string, rounds []models.FixtureRound) Document {
+	doc := Document{Title: title, GeneratedAt: time.Now()}
+	for _, round := range rounds {
+		t := &Table{Columns: []string{"Mesa", "Jugador 1", "Jugador 2", "Resultado"}, Widths: []float64{1, 4, 4, 2}}
+		for _, p := range roundPairings(round) {
+			t.Rows = append(t.Rows, []string{p.table, p.player1, p.player2, p.result})
+		}
+		doc.Pages = append(doc.Pages, Page{Heading: "Emparejamientos - " + roundHeading(round), Table: t})
+	}
+	return doc
+}
+
+// PairingsByName lists every player in alphabetical order with their table and opponent,
+// one page per round
+func PairingsByName(title string, rounds []models.FixtureRound) Document {
+	doc := Document{Title: title, GeneratedAt: time.Now()}
+	for _, round := range rounds {
+		var rows [][]string
+		for _, p := range roundPairings(round) {
+			if !isBye(p.player1) {
+				rows = append(rows, []string{p.player1, p.table, p.player2})
+			}
+			if !isBye(p.player2) {
+				rows = append(rows, []string{p.player2, p.table, p.player1})
+			}
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			return strings.ToLower(rows[i][0]) < strings.ToLower(rows[j][0])
+		})
+		t := &Table{Columns: []string{"Jugador", "Mesa", "Rival"}, Widths: []float64{4, 1, 4}, Rows: rows}
+		doc.Pages = append(doc.Pages, Page{Heading: "Emparejamientos por nombre - " + roundHeading(round), Table: t})
+	}
+	return doc
+}
+
+// Standings lists the current standings. Dropped and disqualified players are marked.
+func Standings(title string, standings []models.Standing) Document {
+	t := &Table{
+		Columns: []string{"Pos", "Jugador", "PJ", "G", "E", "P", "Pts", "Puntaje"},
+		Widths:  []float64{1, 5, 1, 1, 1, 1, 1.2, 1.6},
+	}
+	position := 0
+	for _, s := range standings {
+		if isBye(s.Name) {
+			continue
+		}
+		position++
+		name := s.Name
+		switch s.Status {
+		case models.PlayerStatusDropped:
+			name += " (retirado)"
+		case models.PlayerStatusDisqualified:
+			name += " (descalificado)"
+		}
+		t.Rows = append(t.Rows, []string{
+			strconv.Itoa(position), name, strconv.Itoa(s.MatchesPlayed), strconv.Itoa(s.Wins),
+			strconv.Itoa(s.Ties), strconv.Itoa(s.Losses), strconv.Itoa(s.Points), strconv.Itoa(s.TotalPointsScored),
+		})
+	}
+	return Document{
+		Title:       title,
+		GeneratedAt: time.Now(),
+		Pages:       []Page{{Heading: "Clasificación", Table: t}},
+	}
+}
+
+// ResultSlips makes one slip per match to be played, one page per round.
+// Matches against BYE and matches that already have a result are skipped.
+func ResultSlips(title string, rounds []models.FixtureRound) Document {
+	doc := Document{Title: title, GeneratedAt: time.Now()}
+	for _, round := range rounds {
+		page := Page{Heading: "Hojas de resultado - " + roundHeading(round)}
+		for i, p := range roundPairings(round) {
+			if p.table == "-" || round.Matches[i].Completed {
+				continue
+			}
+			page.Slips = append(page.Slips, Slip{
+				Round:   round.Number,
+				Format:  round.Format,
+				Table:   p.table,
+				Player1: p.player1,
+				Player2: p.player2,
+			})
+		}
+		if len(page.Slips) > 0 {
+			doc.Pages = append(doc.Pages, page)
+		}
+	}
+	return doc
+}
@@ -0,0 +1,82 @@
+package printout
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("printout").Funcs(template.FuncMap{
+	"percent": func(widths []float64, i int) float64 {
+		total := 0.0
+		for _, w := range widths {
+			total += w
+		}
+		return widths[i] * 100 / total
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+  @page { size: A4; margin: 15mm; }
+  body { font-family: Helvetica, Arial, sans-serif; color: #000; margin: 0; }
+  .page { page-break-after: always; padding: 8mm 0; }
+  .page:last-child { page-break-after: auto; }
+  h1 { font-size: 20pt; margin: 0 0 2mm; }
+  h2 { font-size: 15pt; margin: 0 0 5mm; font-weight: normal; }
+  table { width: 100%; border-collapse: collapse; font-size: 12pt; }
+  th { text-align: left; border-bottom: 2px solid #000; padding: 2mm; }
+  td { border-bottom: 1px solid #999; padding: 2mm; }
+  .slips { display: grid; grid-template-columns: 1fr 1fr; gap: 6mm; }
+  .slip { border: 1px dashed #000; padding: 4mm; page-break-inside: avoid; }
+  .slip-header { display: flex; justify-content: space-between; font-weight: bold; margin-bottom: 4mm; }
+  .slip-player { display: flex; align-items: center; margin-bottom: 3mm; }
+  .slip-player .name { flex: 1; }
+  .slip-player .score { width: 14mm; height: 9mm; border: 1px solid #000; }
+  .signature { border-top: 1px solid #000; margin-top: 10mm; padding-top: 1mm; font-size: 9pt; }
+  .generated { font-size: 8pt; color: #555; margin-top: 4mm; }
+</style>
+</head>
+<body>
+{{- $doc := .}}
+{{- range .Pages}}
+<section class="page">
+  <h1>{{$doc.Title}}</h1>
+  <h2>{{.Heading}}</h2>
+  {{- with .Table}}
+  <table>
+    <thead><tr>{{$t := .}}{{range $i, $col := .Columns}}<th style="width: {{printf "%.1f" (percent $t.Widths $i)}}%">{{$col}}</th>{{end}}</tr></thead>
+    <tbody>
+    {{- range .Rows}}
+      <tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+    {{- end}}
+    </tbody>
+  </table>
+  {{- end}}
+  {{- if .Slips}}
+  <div class="slips">
+    {{- range .Slips}}
+    <div class="slip">
+      <div class="slip-header"><span>Ronda {{.Round}} ({{.Format}})</span><span>Mesa {{.Table}}</span></div>
+      <div class="slip-player"><span class="name">{{.Player1}}</span><span class="score"></span></div>
+      <div class="slip-player"><span class="name">{{.Player2}}</span><span class="score"></span></div>
+      <div class="signature">Firma {{.Player1}}</div>
+      <div class="signature">Firma {{.Player2}}</div>
+    </div>
+    {{- end}}
+  </div>
+  {{- end}}
+  <div class="generated">Generado el {{$doc.GeneratedAt.Format "02/01/2006 15:04"}}</div>
+</section>
+{{- else}}
+<section class="page"><h1>{{.Title}}</h1><p>No hay datos para imprimir.</p></section>
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderHTML writes the document as a standalone, print-ready HTML page
+func RenderHTML(w io.Writer, doc Document) error {
+	return htmlTemplate.Execute(w, doc)
+}
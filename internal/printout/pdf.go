@@ -0,0 +1,264 @@
+package printout
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// A4 page in points, with the margins used for every page
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	pageMargin   = 40.0
+	rowHeight    = 18.0
+	fontSize     = 11.0
+	titleSize    = 18.0
+	headingSize  = 14.0
+	footerSize   = 7.0
+	slipHeight   = 160.0
+	slipsPerPage = 8
+)
+
+// helveticaWidths are the widths of the printable ASCII characters of Helvetica,
+// in thousandths of the font size (from the standard AFM metrics)
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// textWidth approximates the width of s in points. Non-ASCII letters use the
+// width of an average lowercase letter; bold text is about 5% wider.
+func textWidth(s string, size float64, bold bool) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r < 127 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	w := float64(total) * size / 1000
+	if bold {
+		w *= 1.05
+	}
+	return w
+}
+
+// fitText shortens s with an ellipsis so it fits in width
+func fitText(s string, width, size float64, bold bool) string {
+	if textWidth(s, size, bold) <= width {
+		return s
+	}
+	for utf8.RuneCountInString(s) > 0 {
+		_, n := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-n]
+		if textWidth(s+"...", size, bold) <= width {
+			return s + "..."
+		}
+	}
+	return ""
+}
+
+// pdfString encodes s as a PDF literal string in WinAnsiEncoding. Latin-1 characters
+// (accents, ñ) map directly; anything else becomes '?'.
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// pdfPage collects the content stream of one page
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+func (p *pdfPage) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td %s Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+func (p *pdfPage) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+func (p *pdfPage) rect(x, y, w, h float64, dashed bool) {
+	if dashed {
+		p.content.WriteString("[4 3] 0 d\n")
+	}
+	fmt.Fprintf(&p.content, "0.8 w %.2f %.2f %.2f %.2f re S\n", x, y, w, h)
+	if dashed {
+		p.content.WriteString("[] 0 d\n")
+	}
+}
+
+type pdfWriter struct {
+	doc   Document
+	pages []*pdfPage
+}
+
+// newPage starts a page with the document title and heading and returns the y
+// position where the content starts
+func (w *pdfWriter) newPage(heading string) (*pdfPage, float64) {
+	p := &pdfPage{}
+	w.pages = append(w.pages, p)
+
+	y := pageHeight - pageMargin - titleSize
+	p.text(pageMargin, y, titleSize, true, w.doc.Title)
+	y -= headingSize + 8
+	p.text(pageMargin, y, headingSize, false, heading)
+	p.text(pageMargin, pageMargin/2, footerSize, false, "Generado el "+w.doc.GeneratedAt.Format("02/01/2006 15:04"))
+	return p, y - 16
+}
+
+func (w *pdfWriter) writeTable(heading string, t *Table) {
+	usable := pageWidth - 2*pageMargin
+	total := 0.0
+	for _, width := range t.Widths {
+		total += width
+	}
+	xs := make([]float64, len(t.Columns))
+	widths := make([]float64, len(t.Columns))
+	x := pageMargin
+	for i := range t.Columns {
+		xs[i] = x
+		widths[i] = usable * t.Widths[i] / total
+		x += widths[i]
+	}
+
+	header := func(p *pdfPage, y float64) float64 {
+		for i, col := range t.Columns {
+			p.text(xs[i]+3, y, fontSize, true, fitText(col, widths[i]-6, fontSize, true))
+		}
+		p.line(pageMargin, y-5, pageWidth-pageMargin, y-5, 1.2)
+		return y - rowHeight
+	}
+
+	p, y := w.newPage(heading)
+	y = header(p, y)
+	for _, row := range t.Rows {
+		if y < pageMargin+rowHeight {
+			p, y = w.newPage(heading + " (cont.)")
+			y = header(p, y)
+		}
+		for i, cell := range row {
+			if i < len(xs) {
+				p.text(xs[i]+3, y, fontSize, false, fitText(cell, widths[i]-6, fontSize, false))
+			}
+		}
+		p.line(pageMargin, y-5, pageWidth-pageMargin, y-5, 0.3)
+		y -= rowHeight
+	}
+}
+
+// writeSlips lays out the slips in two columns
+func (w *pdfWriter) writeSlips(heading string, slips []Slip) {
+	slipWidth := (pageWidth-2*pageMargin)/2 - 8
+	var p *pdfPage
+	var top float64
+
+	for i, s := range slips {
+		if i%slipsPerPage == 0 {
+			p, top = w.newPage(heading)
+		}
+		col := float64(i % 2)
+		row := float64((i % slipsPerPage) / 2)
+		x := pageMargin + col*(slipWidth+16)
+		y := top - row*(slipHeight+10) - slipHeight
+
+		p.rect(x, y, slipWidth, slipHeight, true)
+		inner := slipWidth - 20
+		p.text(x+10, y+slipHeight-20, fontSize, true, fmt.Sprintf("Ronda %d (%s)", s.Round, s.Format))
+		mesa := "Mesa " + s.Table
+		p.text(x+slipWidth-10-textWidth(mesa, fontSize, true), y+slipHeight-20, fontSize, true, mesa)
+
+		for j, name := range []string{s.Player1, s.Player2} {
+			ly := y + slipHeight - 50 - float64(j)*28
+			p.text(x+10, ly, fontSize, false, fitText(name, inner-50, fontSize, false))
+			p.rect(x+slipWidth-50, ly-6, 40, 20, false)
+		}
+
+		for j, name := range []string{s.Player1, s.Player2} {
+			ly := y + 40 - float64(j)*26
+			p.line(x+10, ly, x+10+inner, ly, 0.6)
+			p.text(x+10, ly-9, footerSize, false, fitText("Firma "+name, inner, footerSize, false))
+		}
+	}
+}
+
+// RenderPDF writes the document as an A4 PDF using the standard Helvetica fonts
+func RenderPDF(out io.Writer, doc Document) error {
+	w := &pdfWriter{doc: doc}
+	for _, page := range doc.Pages {
+		if page.Table != nil {
+			w.writeTable(page.Heading, page.Table)
+		}
+		if len(page.Slips) > 0 {
+			w.writeSlips(page.Heading, page.Slips)
+		}
+	}
+	if len(w.pages) == 0 {
+		p, y := w.newPage("")
+		p.text(pageMargin, y, fontSize, false, "No hay datos para imprimir.")
+	}
+
+	// Objects: 1 catalog, 2 page tree, 3-4 fonts, then a page and its content stream per page
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(w.pages))
+	for i := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, p := range w.pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := out.Write(buf.Bytes())
+	return err
+}
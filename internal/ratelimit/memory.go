@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full buckets are dropped from memory
+const sweepInterval = time.Minute
+
+// Memory is a Store in the memory of the process
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // when the bucket is full again, and can be forgotten
+}
+
+// NewMemory returns an in-process store
+func NewMemory() *Memory {
+	return &Memory{buckets: map[string]*bucket{}, lastSweep: time.Now()}
+}
+
+func (m *Memory) Take(_ context.Context, key string, limit Limit, cost int) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) > sweepInterval {
+		m.sweep(now)
+	}
+
+	burst := float64(limit.Requests)
+	rate := limit.rate()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, updated: now}
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	need := math.Max(float64(cost), 1)
+	if b.tokens < need {
+		m.buckets[key] = b
+		return time.Duration((need - b.tokens) / rate * float64(time.Second)), nil
+	}
+	if cost == 0 {
+		return 0, nil
+	}
+	b.tokens -= float64(cost)
+	b.full = now.Add(time.Duration((burst - b.tokens) / rate * float64(time.Second)))
+	m.buckets[key] = b
+	return 0, nil
+}
+
+// sweep forgets the buckets that have refilled, as a new bucket starts full anyway
+func (m *Memory) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if now.After(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,158 @@
+// Package ratelimit throttles clients with token buckets: a bucket holds up to
+// Limit.Requests tokens and refills at Limit.Requests per Limit.Per, and every
+// request takes a token. Buckets live in a Store: in process by default, or in Redis
+// when REDIS_URL is set so every instance counts the same requests.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/cache"
+	"github.com/andreuvv/premier_mitologico/backend/internal/logging"
+)
+
+// Limit is a number of requests per period. The zero Limit is no limit.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Enabled reports whether the limit throttles anything
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// rate is the tokens added per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return strconv.Itoa(l.Requests) + "/" + l.Per.String()
+}
+
+// ParseLimit reads "<requests>/<period>", e.g. "120/1m", or "off"
+func ParseLimit(value string) (Limit, error) {
+	if value == "off" {
+		return Limit{}, nil
+	}
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q, want <requests>/<period> or off", value)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("invalid number of requests in %q", value)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid period in %q", value)
+	}
+	return Limit{Requests: n, Per: d}, nil
+}
+
+// Store holds the buckets. Stores are shared by goroutines.
+type Store interface {
+	// Take takes cost tokens from the bucket under key, created full. It returns 0 if
+	// they were taken, else how long until they will be there and nothing is taken.
+	// A cost of 0 only checks that the bucket is not empty.
+	Take(ctx context.Context, key string, limit Limit, cost int) (time.Duration, error)
+}
+
+// Config sets the limits and the store
+type Config struct {
+	PerIP        Limit  // requests of a client IP, on every route
+	PerKey       Limit  // requests with an API key, on the protected routes
+	AuthFailures Limit  // wrong API keys of a client IP before it is locked out
+	RedisURL     string // redis://[:password@]host:port[/db]; empty keeps buckets in process
+}
+
+// ConfigFromEnv reads RATE_LIMIT (default 300/1m), RATE_LIMIT_API_KEY (default
+// 600/1m), AUTH_FAILURE_LIMIT (default 5/15m) and REDIS_URL
+func ConfigFromEnv() Config {
+	return Config{
+		PerIP:        limitFromEnv("RATE_LIMIT", Limit{Requests: 300, Per: time.Minute}),
+		PerKey:       limitFromEnv("RATE_LIMIT_API_KEY", Limit{Requests: 600, Per: time.Minute}),
+		AuthFailures: limitFromEnv("AUTH_FAILURE_LIMIT", Limit{Requests: 5, Per: 15 * time.Minute}),
+		RedisURL:     os.Getenv("REDIS_URL"),
+	}
+}
+
+func limitFromEnv(key string, fallback Limit) Limit {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	limit, err := ParseLimit(value)
+	if err != nil {
+		slog.Warn("invalid rate limit, using default", "variable", key, "value", value, "default", fallback.String())
+		return fallback
+	}
+	return limit
+}
+
+// NewStore returns the Redis store if the config has a URL, else an in-process one
+func NewStore(cfg Config) (Store, error) {
+	if cfg.RedisURL == "" {
+		return NewMemory(), nil
+	}
+	client, err := cache.NewRedis(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewRedis(client), nil
+}
+
+// Limiter applies the limits of a config to the buckets of a store
+type Limiter struct {
+	Config
+	store Store
+}
+
+// New returns a limiter of cfg keeping its buckets in store
+func New(store Store, cfg Config) *Limiter {
+	return &Limiter{Config: cfg, store: store}
+}
+
+// Take takes a token from the bucket of key in scope, returning 0 if the request may
+// go on, else how long the client has to wait. Store failures are logged and let the
+// request through.
+func (l *Limiter) Take(ctx context.Context, scope, key string, limit Limit) time.Duration {
+	return l.take(ctx, scope, key, limit, 1)
+}
+
+// Blocked returns how long the bucket of key in scope stays empty, without taking a
+// token
+func (l *Limiter) Blocked(ctx context.Context, scope, key string, limit Limit) time.Duration {
+	return l.take(ctx, scope, key, limit, 0)
+}
+
+func (l *Limiter) take(ctx context.Context, scope, key string, limit Limit, cost int) time.Duration {
+	if !limit.Enabled() {
+		return 0
+	}
+	wait, err := l.store.Take(ctx, keyPrefix+scope+":"+key, limit, cost)
+	if err != nil {
+		logging.FromContext(ctx).Warn("rate limit store unavailable", "error", err)
+		return 0
+	}
+	return wait
+}
+
+// keyPrefix keeps the buckets of this API apart from other keys in a shared Redis
+const keyPrefix = "pm:ratelimit:"
+
+// RetryAfter is the value of a Retry-After header for a wait: whole seconds, at least 1
+func RetryAfter(wait time.Duration) string {
+	return strconv.Itoa(int(math.Max(1, math.Ceil(wait.Seconds()))))
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/cache"
+)
+
+// takeScript refills and takes from a bucket atomically, on the clock of Redis so
+// instances with skewed clocks agree. It returns the milliseconds to wait, 0 if the
+// tokens were taken.
+const takeScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(bucket[1]) or burst
+local updated = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate)
+local need = math.max(cost, 1)
+if tokens < need then
+	return math.ceil((need - tokens) / rate)
+end
+if cost == 0 then
+	return 0
+end
+tokens = tokens - cost
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate) + 1000)
+return 0
+`
+
+// Redis is a Store in Redis, shared by every instance
+type Redis struct {
+	client *cache.Redis
+}
+
+// NewRedis returns a store using client
+func NewRedis(client *cache.Redis) *Redis {
+	return &Redis{client: client}
+}
+
+func (r *Redis) Take(ctx context.Context, key string, limit Limit, cost int) (time.Duration, error) {
+	perMillisecond := limit.rate() / 1000
+	value, err := r.client.Do(ctx, "EVAL", takeScript, "1", key,
+		strconv.FormatFloat(perMillisecond, 'g', -1, 64),
+		strconv.Itoa(limit.Requests),
+		strconv.Itoa(cost),
+	)
+	if err != nil {
+		return 0, err
+	}
+	wait, ok := value.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected rate limit answer %v", value)
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
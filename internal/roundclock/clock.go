@@ -0,0 +1,226 @@
+// Package roundclock serves the timers of in-person rounds. The clock state lives in
+// the round_clocks table and every elapsed time is measured by the database, so all
+// clients and server instances agree on the remaining time.
+package roundclock
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/env"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+)
+
+// Defaults for a new round clock, overridable with ROUND_DURATION (a Go duration)
+// and ROUND_EXTRA_TURNS
+const (
+	defaultDuration   = 40 * time.Minute
+	defaultExtraTurns = 3
+)
+
+// DefaultDuration is the length of a round when the clock is started without one
+func DefaultDuration() time.Duration {
+	return env.Duration("ROUND_DURATION", defaultDuration)
+}
+
+// DefaultExtraTurns is the number of extra turns played after time is called
+func DefaultExtraTurns() int {
+	return env.Int("ROUND_EXTRA_TURNS", defaultExtraTurns, 0)
+}
+
+// Load returns the clock of a round. A round whose clock was never started is
+// not_started. It returns sql.ErrNoRows if the round does not exist.
+func Load(ctx context.Context, roundNumber int) (*models.RoundClock, error) {
+	var clock models.RoundClock
+	var roundID int
+	var duration, extension, extraTurns sql.NullInt64
+	var elapsed sql.NullFloat64
+	var running bool
+	var startedAt sql.NullTime
+
+	err := database.DB.QueryRowContext(ctx, `
+		SELECT
+			r.id,
+			r.round_number,
+			r.format,
+			rc.duration_seconds,
+			rc.extension_seconds,
+			rc.extra_turns,
+			rc.elapsed_seconds + COALESCE(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - rc.running_since)), 0),
+			rc.running_since IS NOT NULL,
+			rc.started_at,
+			CURRENT_TIMESTAMP
+		FROM rounds r
+		LEFT JOIN round_clocks rc ON rc.round_id = r.id
+		WHERE r.round_number = $1
+	`, roundNumber).Scan(
+		&roundID,
+		&clock.RoundNumber,
+		&clock.Format,
+		&duration,
+		&extension,
+		&extraTurns,
+		&elapsed,
+		&running,
+		&startedAt,
+		&clock.ServerTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remaining time in seconds, before rounding; kept exact for the match clocks
+	remaining := 0.0
+	if duration.Valid {
+		remaining = float64(duration.Int64+extension.Int64) - elapsed.Float64
+		clock.Status = clockStatus(remaining, running)
+		clock.DurationSeconds = int(duration.Int64)
+		clock.ExtensionSeconds = int(extension.Int64)
+		clock.RemainingSeconds = remainingSeconds(remaining)
+		clock.ExtraTurns = int(extraTurns.Int64)
+		clock.StartedAt = &startedAt.Time
+	} else {
+		clock.Status = models.ClockStatusNotStarted
+		clock.DurationSeconds = int(DefaultDuration().Seconds())
+		clock.RemainingSeconds = clock.DurationSeconds
+		clock.ExtraTurns = DefaultExtraTurns()
+	}
+
+	clock.Matches, err = loadMatchClocks(ctx, roundID, duration.Valid, remaining, running)
+	if err != nil {
+		return nil, err
+	}
+	return &clock, nil
+}
+
+// Current returns the clock of the most recently started round. It returns
+// sql.ErrNoRows if no clock was started.
+func Current(ctx context.Context) (*models.RoundClock, error) {
+	var roundNumber int
+	err := database.DB.QueryRowContext(ctx, `
+		SELECT r.round_number
+		FROM round_clocks rc
+		JOIN rounds r ON r.id = rc.round_id
+		ORDER BY rc.started_at DESC, r.round_number DESC
+		LIMIT 1
+	`).Scan(&roundNumber)
+	if err != nil {
+		return nil, err
+	}
+	return Load(ctx, roundNumber)
+}
+
+func loadMatchClocks(ctx context.Context, roundID int, started bool, roundRemaining float64, running bool) ([]models.MatchClock, error) {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT m.id, m.table_number, p1.name, p2.name, m.completed, COALESCE(SUM(e.seconds), 0)
+		FROM matches m
+		JOIN players p1 ON m.player1_id = p1.id
+		JOIN players p2 ON m.player2_id = p2.id
+		LEFT JOIN match_time_extensions e ON e.match_id = m.id
+		WHERE m.round_id = $1 AND p1.name <> 'BYE' AND p2.name <> 'BYE'
+		GROUP BY m.id, m.table_number, p1.name, p2.name, m.completed
+		ORDER BY m.table_number NULLS LAST, m.id
+	`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []models.MatchClock{}
+	for rows.Next() {
+		var m models.MatchClock
+		var completed bool
+		if err := rows.Scan(&m.MatchID, &m.TableNumber, &m.Player1Name, &m.Player2Name, &completed, &m.ExtensionSeconds); err != nil {
+			return nil, err
+		}
+
+		remaining := roundRemaining + float64(m.ExtensionSeconds)
+		switch {
+		case completed:
+			m.Status = models.ClockStatusCompleted
+		case !started:
+			m.Status = models.ClockStatusNotStarted
+		default:
+			m.Status = clockStatus(remaining, running)
+		}
+		if started && !completed {
+			m.RemainingSeconds = remainingSeconds(remaining)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// clockStatus is extra_turns once time is up, whether or not the clock runs
+func clockStatus(remaining float64, running bool) string {
+	switch {
+	case remaining <= 0:
+		return models.ClockStatusExtraTurns
+	case running:
+		return models.ClockStatusRunning
+	default:
+		return models.ClockStatusPaused
+	}
+}
+
+// remainingSeconds rounds up, so a clock shows 0 only when time is really up
+func remainingSeconds(remaining float64) int {
+	if remaining <= 0 {
+		return 0
+	}
+	return int(math.Ceil(remaining))
+}
+
+// NextChange returns how long until the round or one of its matches runs out of
+// time, or 0 if nothing will change by itself (no clock is running down)
+func NextChange(clock *models.RoundClock) time.Duration {
+	next := 0
+	if clock.Status == models.ClockStatusRunning {
+		next = clock.RemainingSeconds
+	}
+	for _, m := range clock.Matches {
+		if m.Status == models.ClockStatusRunning && (next == 0 || m.RemainingSeconds < next) {
+			next = m.RemainingSeconds
+		}
+	}
+	return time.Duration(next) * time.Second
+}
+
+// Clients following the clocks are notified of every change through a channel;
+// they reload the clock themselves, so a notification carries no data
+var (
+	subscribersMu sync.Mutex
+	subscribers   = make(map[chan struct{}]struct{})
+)
+
+// Subscribe returns a channel that receives a signal whenever a clock changes, and
+// a function to stop receiving them
+func Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	subscribersMu.Lock()
+	subscribers[ch] = struct{}{}
+	subscribersMu.Unlock()
+
+	return ch, func() {
+		subscribersMu.Lock()
+		delete(subscribers, ch)
+		subscribersMu.Unlock()
+	}
+}
+
+// Notify tells every subscriber that a clock changed. It never blocks: a subscriber
+// that has not caught up with the previous signal simply gets one signal for both.
+func Notify() {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	for ch := range subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
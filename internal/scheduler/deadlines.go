@@ -0,0 +1,298 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/env"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/playerstats"
+)
+
+// Config controls how often deadlines are checked and how early reminders are sent
+type Config struct {
+	Interval       time.Duration
+	ReminderWindow time.Duration
+}
+
+// ConfigFromEnv reads DEADLINE_CHECK_INTERVAL (default 5m) and
+// DEADLINE_REMINDER_WINDOW (default 24h) as Go durations
+func ConfigFromEnv() Config {
+	return Config{
+		Interval:       env.Duration("DEADLINE_CHECK_INTERVAL", 5*time.Minute),
+		ReminderWindow: env.Duration("DEADLINE_REMINDER_WINDOW", 24*time.Hour),
+	}
+}
+
+// ReminderHook is called once for every pending match whose deadline is about to pass
+type ReminderHook func(match models.OnlineTournamentMatch)
+
+// ExpiryHook is called for every match resolved automatically after its deadline
+type ExpiryHook func(match models.OnlineTournamentMatch)
+
+// ExpiryQueue is called in the transaction that resolves an overdue match, to queue
+// what the resolution causes with it; an error rolls the resolution back
+type ExpiryQueue func(ctx context.Context, tx database.Querier, match models.OnlineTournamentMatch) error
+
+var (
+	hooksMu       sync.RWMutex
+	reminderHooks []ReminderHook
+	expiryHooks   []ExpiryHook
+	expiryQueues  []ExpiryQueue
+)
+
+// RegisterReminderHook adds a hook called when a match deadline is approaching
+func RegisterReminderHook(hook ReminderHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	reminderHooks = append(reminderHooks, hook)
+}
+
+// RegisterExpiryHook adds a hook called when an overdue match is resolved
+func RegisterExpiryHook(hook ExpiryHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	expiryHooks = append(expiryHooks, hook)
+}
+
+// RegisterExpiryQueue adds a queue called when an overdue match is resolved, before
+// the resolution is committed
+func RegisterExpiryQueue(queue ExpiryQueue) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	expiryQueues = append(expiryQueues, queue)
+}
+
+// Start runs the deadline scheduler in a background goroutine until ctx is cancelled
+func Start(ctx context.Context, cfg Config) {
+	slog.Info("deadline scheduler running", "interval", cfg.Interval, "reminder_window", cfg.ReminderWindow)
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := RunOnce(ctx, cfg); err != nil {
+				slog.Warn("deadline scheduler failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				slog.Info("deadline scheduler stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce resolves overdue matches and sends pending reminders a single time
+func RunOnce(ctx context.Context, cfg Config) error {
+	expired, err := ExpireOverdueMatches(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to expire overdue matches: %w", err)
+	}
+	if len(expired) > 0 {
+		slog.Info("resolved overdue online matches", "count", len(expired))
+	}
+
+	hooksMu.RLock()
+	hooks := append([]ExpiryHook(nil), expiryHooks...)
+	hooksMu.RUnlock()
+	for _, m := range expired {
+		for _, hook := range hooks {
+			hook(m)
+		}
+	}
+
+	if err := SendReminders(ctx, time.Now(), cfg.ReminderWindow); err != nil {
+		return fmt.Errorf("failed to send reminders: %w", err)
+	}
+	return nil
+}
+
+// ExpireOverdueMatches resolves every pending match of an in-progress online tournament
+// whose deadline has passed, according to the tournament's deadline policy
+func ExpireOverdueMatches(ctx context.Context, now time.Time) ([]models.OnlineTournamentMatch, error) {
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT otm.id, otm.tournament_id, otm.player1_id, otm.player2_id, otm.player1_name, otm.player2_name,
+			otm.deadline, otm.forfeit_claimed_by, t.deadline_policy
+		FROM online_tournament_matches otm
+		JOIN tournaments t ON t.id = otm.tournament_id
+		WHERE otm.completed = false
+			AND otm.deadline IS NOT NULL
+			AND otm.deadline < $1
+			AND t.status = 'in_progress'
+			AND t.deadline_policy != 'none'
+		ORDER BY otm.deadline
+		FOR UPDATE OF otm SKIP LOCKED
+	`, now)
+	if err != nil {
+		return nil, err
+	}
+
+	type overdueMatch struct {
+		match  models.OnlineTournamentMatch
+		policy string
+	}
+	var overdue []overdueMatch
+	for rows.Next() {
+		var o overdueMatch
+		var claimedBy sql.NullInt64
+		err := rows.Scan(
+			&o.match.ID,
+			&o.match.TournamentID,
+			&o.match.Player1ID,
+			&o.match.Player2ID,
+			&o.match.Player1Name,
+			&o.match.Player2Name,
+			&o.match.Deadline,
+			&claimedBy,
+			&o.policy,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if claimedBy.Valid {
+			id := int(claimedBy.Int64)
+			o.match.ClaimedBy = &id
+		}
+		overdue = append(overdue, o)
+	}
+	rows.Close()
+
+	expired := make([]models.OnlineTournamentMatch, 0, len(overdue))
+	for _, o := range overdue {
+		m := o.match
+		score1, score2 := 0, 0
+		resultType := models.MatchResultDoubleLoss
+
+		// A forfeit claim only decides the match under the forfeit policy
+		if o.policy == models.DeadlinePolicyForfeit && m.ClaimedBy != nil {
+			resultType = models.MatchResultForfeit
+			if *m.ClaimedBy == m.Player1ID {
+				score1 = models.ForfeitWinScore
+			} else {
+				score2 = models.ForfeitWinScore
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE online_tournament_matches
+			SET score1 = $1, score2 = $2, completed = true, result_type = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4
+		`, score1, score2, resultType, m.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		m.Score1, m.Score2 = &score1, &score2
+		m.Completed = true
+		m.ResultType = resultType
+		expired = append(expired, m)
+	}
+
+	hooksMu.RLock()
+	queues := append([]ExpiryQueue(nil), expiryQueues...)
+	hooksMu.RUnlock()
+	for _, m := range expired {
+		for _, queue := range queues {
+			if err := queue(ctx, tx, m); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	refreshed := map[int]bool{}
+	for _, m := range expired {
+		if refreshed[m.TournamentID] {
+			continue
+		}
+		if err := playerstats.RefreshTournament(ctx, tx, m.TournamentID); err != nil {
+			return nil, err
+		}
+		refreshed[m.TournamentID] = true
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// SendReminders calls the reminder hooks for pending matches whose deadline falls
+// within the window and that haven't been reminded yet. The matches are claimed
+// first, by setting reminder_sent_at, so that concurrent runs (or a failure after
+// the hooks) never remind a match twice.
+func SendReminders(ctx context.Context, now time.Time, window time.Duration) error {
+	rows, err := database.DB.QueryContext(ctx, `
+		UPDATE online_tournament_matches otm
+		SET reminder_sent_at = CURRENT_TIMESTAMP
+		FROM tournaments t
+		WHERE t.id = otm.tournament_id
+			AND otm.completed = false
+			AND otm.reminder_sent_at IS NULL
+			AND otm.deadline BETWEEN $1 AND $2
+			AND t.status = 'in_progress'
+		RETURNING otm.id, otm.tournament_id, otm.player1_id, otm.player2_id, otm.player1_name, otm.player2_name,
+			otm.matchday, otm.deadline
+	`, now, now.Add(window))
+	if err != nil {
+		return err
+	}
+
+	var due []models.OnlineTournamentMatch
+	for rows.Next() {
+		var m models.OnlineTournamentMatch
+		err := rows.Scan(
+			&m.ID,
+			&m.TournamentID,
+			&m.Player1ID,
+			&m.Player2ID,
+			&m.Player1Name,
+			&m.Player2Name,
+			&m.Matchday,
+			&m.Deadline,
+		)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		due = append(due, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Deadline.Before(*due[j].Deadline) })
+
+	hooksMu.RLock()
+	hooks := append([]ReminderHook(nil), reminderHooks...)
+	hooksMu.RUnlock()
+
+	for _, m := range due {
+		for _, hook := range hooks {
+			hook(m)
+		}
+	}
+	return nil
+}
+
+// LogReminder is the default reminder hook: it only writes the reminder to the log
+func LogReminder(m models.OnlineTournamentMatch) {
+	slog.Info("match reminder", "match_id", m.ID, "tournament_id", m.TournamentID,
+		"player1", m.Player1Name, "player2", m.Player2Name, "deadline", m.Deadline.Format(time.RFC3339))
+}
@@ -0,0 +1,390 @@
+package tournamentio
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+)
+
+// BulkFiles are the spreadsheet exports of a bulk import. Every row carries the
+// tournament, month and year it belongs to, so one set of files can hold many
+// tournaments. Any file may be nil, but there must be standings or pairings.
+type BulkFiles struct {
+	Standings io.Reader
+	Pairings  io.Reader
+	Races     io.Reader
+}
+
+// BulkOptions controls a bulk import
+type BulkOptions struct {
+	CreateMissingPlayers bool
+	DryRun               bool
+}
+
+// Column names of the bulk CSV files, in English or as usually written in the spreadsheets
+var bulkColumnAliases = map[string]string{
+	"torneo":         "tournament",
+	"nombre":         "tournament",
+	"mes":            "month",
+	"año":            "year",
+	"anio":           "year",
+	"ano":            "year",
+	"fecha_inicio":   "start_date",
+	"fecha_fin":      "end_date",
+	"jugador":        "player",
+	"player_name":    "player",
+	"posicion":       "position",
+	"posición":       "position",
+	"puesto":         "position",
+	"final_position": "position",
+	"partidas":       "matches_played",
+	"victorias":      "wins",
+	"empates":        "ties",
+	"derrotas":       "losses",
+	"puntos":         "points",
+	"ronda":          "round",
+	"round_number":   "round",
+	"formato":        "format",
+	"jugador1":       "player1",
+	"jugador 1":      "player1",
+	"player1_name":   "player1",
+	"jugador2":       "player2",
+	"jugador 2":      "player2",
+	"player2_name":   "player2",
+	"puntaje1":       "score1",
+	"resultado1":     "score1",
+	"puntaje2":       "score2",
+	"resultado2":     "score2",
+	"raza_pb":        "race_pb",
+	"raza_bf":        "race_bf",
+	"notas":          "notes",
+}
+
+type bulkTournament struct {
+	label  string
+	bundle *models.TournamentBundle
+	// where every player appears, to report unknown players row by row
+	playerLines map[string][]string
+	roundLines  map[int]string
+}
+
+func (t *bulkTournament) notePlayer(r *Record, name string) {
+	key := normalizeName(name)
+	t.playerLines[key] = append(t.playerLines[key], fmt.Sprintf("%s line %d", r.File, r.Line))
+}
+
+// BulkImport parses the bulk CSV files and imports every tournament in them as an
+// archived in-person tournament inside tx. Nothing is imported if any row has a
+// problem: the report lists every problem with its file and line, and a
+// *ValidationError is returned. Tournaments that already exist are skipped.
+// With DryRun the caller is expected to roll tx back.
+func BulkImport(ctx context.Context, tx *sql.Tx, files BulkFiles, opts BulkOptions) (*models.BulkImportReport, error) {
+	report := &models.BulkImportReport{
+		DryRun:      opts.DryRun,
+		Tournaments: []models.BulkImportTournament{},
+		Errors:      []string{},
+	}
+
+	tournaments, problems := parseBulk(files)
+
+	for _, t := range tournaments {
+		Normalize(t.bundle)
+		for _, p := range Validate(t.bundle) {
+			problems = append(problems, t.label+": "+p)
+		}
+		if opts.CreateMissingPlayers {
+			continue
+		}
+		for _, bp := range t.bundle.Players {
+			if normalizeName(bp.Name) == normalizeName(ByeName) {
+				continue
+			}
+			if _, err := findPlayer(ctx, tx, bp.Name); err == sql.ErrNoRows {
+				lines := t.playerLines[normalizeName(bp.Name)]
+				problems = append(problems, fmt.Sprintf("%s: unknown player '%s'", strings.Join(lines, ", "), bp.Name))
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		report.Errors = problems
+		return report, &ValidationError{Problems: problems}
+	}
+
+	for _, t := range tournaments {
+		b := t.bundle
+		entry := models.BulkImportTournament{
+			Name:           b.Tournament.Name,
+			Month:          b.Tournament.Month,
+			Year:           b.Tournament.Year,
+			Players:        len(b.Players),
+			Standings:      len(b.Standings),
+			Races:          len(b.Races),
+			PlayersCreated: []string{},
+		}
+
+		result, err := Import(ctx, tx, b, Options{CreateMissingPlayers: opts.CreateMissingPlayers})
+		if errors.Is(err, ErrDuplicateTournament) {
+			entry.Status = models.BulkImportDuplicate
+			report.Tournaments = append(report.Tournaments, entry)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.label, err)
+		}
+
+		entry.Status = models.BulkImportImported
+		entry.Rounds = result.Rounds
+		entry.Matches = result.Matches
+		entry.PlayersCreated = result.PlayersCreated
+		if !opts.DryRun {
+			id := result.TournamentID
+			entry.TournamentID = &id
+		}
+		report.Tournaments = append(report.Tournaments, entry)
+	}
+
+	return report, nil
+}
+
+// parseBulk groups the rows of the bulk files by tournament, in order of appearance
+func parseBulk(files BulkFiles) ([]*bulkTournament, []string) {
+	if files.Standings == nil && files.Pairings == nil {
+		return nil, []string{"standings or pairings file is required"}
+	}
+
+	var tournaments []*bulkTournament
+	byKey := make(map[string]*bulkTournament)
+	var problems []string
+
+	tournamentFor := func(r *Record) *bulkTournament {
+		name, month, year := r.String("tournament"), r.String("month"), r.Int("year")
+		if name == "" || month == "" || year <= 0 {
+			r.Errorf("tournament, month and year are required")
+			return nil
+		}
+		key := fmt.Sprintf("%s|%s|%d", normalizeName(name), strings.ToLower(month), year)
+		t, ok := byKey[key]
+		if !ok {
+			t = &bulkTournament{
+				label: fmt.Sprintf("%s (%s %d)", name, month, year),
+				bundle: &models.TournamentBundle{
+					Version:    models.TournamentBundleVersion,
+					Tournament: models.BundleTournament{Name: name, Month: month, Year: year, Type: "IN_PERSON"},
+				},
+				playerLines: make(map[string][]string),
+				roundLines:  make(map[int]string),
+			}
+			byKey[key] = t
+			tournaments = append(tournaments, t)
+		}
+		if bt := &t.bundle.Tournament; bt.StartDate == nil {
+			bt.StartDate = r.Date("start_date")
+		}
+		if bt := &t.bundle.Tournament; bt.EndDate == nil {
+			bt.EndDate = r.Date("end_date")
+		}
+		return t
+	}
+
+	read := func(reader io.Reader, file string, row func(t *bulkTournament, r *Record)) {
+		if reader == nil {
+			return
+		}
+		records, err := ReadCSV(reader, file)
+		if err != nil {
+			problems = append(problems, err.Error())
+			return
+		}
+		for _, r := range records {
+			aliasColumns(r)
+			if t := tournamentFor(r); t != nil {
+				row(t, r)
+			}
+			problems = append(problems, r.Problems...)
+		}
+	}
+
+	read(files.Standings, "standings.csv", func(t *bulkTournament, r *Record) {
+		player := r.String("player")
+		if player == "" {
+			r.Errorf("player is required")
+			return
+		}
+		t.bundle.Standings = append(t.bundle.Standings, models.BundleStanding{
+			PlayerName:        player,
+			FinalPosition:     r.IntPtr("position"),
+			MatchesPlayed:     r.Int("matches_played"),
+			Wins:              r.Int("wins"),
+			Ties:              r.Int("ties"),
+			Losses:            r.Int("losses"),
+			Points:            r.Int("points"),
+			TotalPointsScored: r.Int("total_points_scored"),
+			TotalMatches:      r.Int("total_matches"),
+		})
+		t.notePlayer(r, player)
+	})
+
+	read(files.Pairings, "pairings.csv", func(t *bulkTournament, r *Record) {
+		round := r.Int("round")
+		format := strings.ToUpper(r.String("format"))
+		player1, player2 := r.String("player1"), r.String("player2")
+		switch {
+		case round <= 0:
+			r.Errorf("round is required")
+			return
+		case format != "PB" && format != "BF":
+			r.Errorf("format must be PB or BF")
+			return
+		case player1 == "" || player2 == "":
+			r.Errorf("player1 and player2 are required")
+			return
+		}
+
+		if previous, ok := t.roundLines[round]; ok {
+			for _, rd := range t.bundle.Rounds {
+				if rd.RoundNumber == round && rd.Format != format {
+					r.Errorf("round %d is %s in %s", round, rd.Format, previous)
+					return
+				}
+			}
+		} else {
+			t.roundLines[round] = fmt.Sprintf("%s line %d", r.File, r.Line)
+			t.bundle.Rounds = append(t.bundle.Rounds, models.BundleRound{RoundNumber: round, Format: format})
+		}
+
+		m := models.BundleMatch{
+			RoundNumber: &round,
+			Player1Name: player1,
+			Player2Name: player2,
+			Score1:      r.IntPtr("score1"),
+			Score2:      r.IntPtr("score2"),
+			ResultType:  models.MatchResultPlayed,
+		}
+		m.Completed = m.Score1 != nil && m.Score2 != nil
+		t.bundle.Matches = append(t.bundle.Matches, m)
+		t.notePlayer(r, player1)
+		t.notePlayer(r, player2)
+	})
+
+	read(files.Races, "races.csv", func(t *bulkTournament, r *Record) {
+		player := r.String("player")
+		if player == "" {
+			r.Errorf("player is required")
+			return
+		}
+		t.bundle.Races = append(t.bundle.Races, models.BundlePlayerRace{
+			PlayerName: player,
+			RacePB:     r.StringPtr("race_pb"),
+			RaceBF:     r.StringPtr("race_bf"),
+			Notes:      r.StringPtr("notes"),
+		})
+		t.notePlayer(r, player)
+	})
+
+	for _, t := range tournaments {
+		sort.Slice(t.bundle.Rounds, func(i, j int) bool {
+			return t.bundle.Rounds[i].RoundNumber < t.bundle.Rounds[j].RoundNumber
+		})
+		if len(t.bundle.Matches) == 0 {
+			continue
+		}
+		computed := standingsFromMatches(t.bundle.Matches)
+		if len(t.bundle.Standings) == 0 {
+			t.bundle.Standings = computed
+			continue
+		}
+		// Standings with only positions take their statistics from the pairings
+		for i, s := range t.bundle.Standings {
+			if s.MatchesPlayed != 0 || s.Wins != 0 || s.Ties != 0 || s.Losses != 0 || s.Points != 0 {
+				continue
+			}
+			for _, c := range computed {
+				if normalizeName(c.PlayerName) == normalizeName(s.PlayerName) {
+					c.PlayerName, c.FinalPosition = s.PlayerName, s.FinalPosition
+					t.bundle.Standings[i] = c
+				}
+			}
+		}
+	}
+	return tournaments, problems
+}
+
+// aliasColumns renames the spreadsheet columns of a record to the names used by the importer
+func aliasColumns(r *Record) {
+	for col, i := range r.header {
+		if canonical, ok := bulkColumnAliases[col]; ok {
+			if _, exists := r.header[canonical]; !exists {
+				r.header[canonical] = i
+			}
+		}
+	}
+}
+
+// standingsFromMatches computes final standings the same way the standings view
+// and ArchiveTournament do: 3 points per win, 1 per tie, ordered by points and
+// then by game points scored
+func standingsFromMatches(matches []models.BundleMatch) []models.BundleStanding {
+	byPlayer := make(map[string]*models.BundleStanding)
+	var order []string
+	get := func(name string) *models.BundleStanding {
+		key := normalizeName(name)
+		s, ok := byPlayer[key]
+		if !ok {
+			s = &models.BundleStanding{PlayerName: name}
+			byPlayer[key] = s
+			order = append(order, key)
+		}
+		return s
+	}
+
+	for _, m := range matches {
+		p1, p2 := get(m.Player1Name), get(m.Player2Name)
+		if !m.Completed || m.Score1 == nil || m.Score2 == nil {
+			continue
+		}
+		s1, s2 := *m.Score1, *m.Score2
+		for _, side := range []struct {
+			s          *models.BundleStanding
+			own, other int
+		}{{p1, s1, s2}, {p2, s2, s1}} {
+			side.s.MatchesPlayed++
+			side.s.TotalPointsScored += side.own
+			side.s.TotalMatches += s1 + s2
+			switch {
+			case side.own > side.other:
+				side.s.Wins++
+				side.s.Points += 3
+			case side.own == side.other:
+				side.s.Ties++
+				side.s.Points++
+			default:
+				side.s.Losses++
+			}
+		}
+	}
+
+	standings := make([]models.BundleStanding, 0, len(order))
+	for _, key := range order {
+		standings = append(standings, *byPlayer[key])
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		return standings[i].TotalPointsScored > standings[j].TotalPointsScored
+	})
+	for i := range standings {
+		position := i + 1
+		standings[i].FinalPosition = &position
+	}
+	return standings
+}
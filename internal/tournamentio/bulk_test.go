@@ -0,0 +1,211 @@
+package tournamentio
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	_ "github.com/lib/pq"
+)
+
+const pairingsHeader = "torneo,mes,año,ronda,formato,jugador1,jugador2,puntaje1,puntaje2\n"
+
+func TestParseBulkProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		pairings string
+		problems []string
+	}{
+		{
+			name:     "valid",
+			pairings: "Premier,Marzo,2024,1,PB,Ana,Bruno,2,1\n",
+		},
+		{
+			name:     "missing tournament",
+			pairings: ",Marzo,2024,1,PB,Ana,Bruno,2,1\n",
+			problems: []string{"pairings.csv line 2: tournament, month and year are required"},
+		},
+		{
+			name:     "invalid year",
+			pairings: "Premier,Marzo,dos mil,1,PB,Ana,Bruno,2,1\n",
+			problems: []string{"pairings.csv line 2: invalid year 'dos mil'", "pairings.csv line 2: tournament, month and year are required"},
+		},
+		{
+			name:     "missing round",
+			pairings: "Premier,Marzo,2024,,PB,Ana,Bruno,2,1\n",
+			problems: []string{"pairings.csv line 2: round is required"},
+		},
+		{
+			name:     "unknown format",
+			pairings: "Premier,Marzo,2024,1,XX,Ana,Bruno,2,1\n",
+			problems: []string{"pairings.csv line 2: format must be PB or BF"},
+		},
+		{
+			name:     "missing player",
+			pairings: "Premier,Marzo,2024,1,PB,Ana,,2,1\n",
+			problems: []string{"pairings.csv line 2: player1 and player2 are required"},
+		},
+		{
+			name:     "invalid score",
+			pairings: "Premier,Marzo,2024,1,PB,Ana,Bruno,dos,1\n",
+			problems: []string{"pairings.csv line 2: invalid score1 'dos'"},
+		},
+		{
+			name:     "round with two formats",
+			pairings: "Premier,Marzo,2024,1,PB,Ana,Bruno,2,1\nPremier,Marzo,2024,1,BF,Carla,Diego,2,0\n",
+			problems: []string{"pairings.csv line 3: round 1 is PB in pairings.csv line 2"},
+		},
+		{
+			name:     "malformed csv",
+			pairings: "Premier,Marzo,2024,1,PB,\"Ana,Bruno,2,1\n",
+			problems: []string{"pairings.csv: parse error on line 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := parseBulk(BulkFiles{Pairings: strings.NewReader(pairingsHeader + tt.pairings)})
+			if len(problems) != len(tt.problems) {
+				t.Fatalf("parseBulk() problems = %q, want %q", problems, tt.problems)
+			}
+			// The wording of CSV syntax errors comes from encoding/csv, so only the start is checked
+			for i, p := range problems {
+				if !strings.HasPrefix(p, tt.problems[i]) {
+					t.Errorf("problem %d = %q, want %q", i, p, tt.problems[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseBulkGroupsTournaments(t *testing.T) {
+	pairings := pairingsHeader +
+		"Premier,Marzo,2024,1,PB,Ana,Bruno,2,1\n" +
+		"Premier Abril,Abril,2024,1,BF,Ana,Carla,0,2\n" +
+		"premier,marzo,2024,2,BF,Ana,Carla,1,1\n"
+	standings := "torneo,mes,año,jugador,posicion\nPremier,Marzo,2024,Carla,1\n"
+
+	tournaments, problems := parseBulk(BulkFiles{Standings: strings.NewReader(standings), Pairings: strings.NewReader(pairings)})
+	if len(problems) > 0 {
+		t.Fatalf("parseBulk() problems = %q", problems)
+	}
+	if len(tournaments) != 2 {
+		t.Fatalf("parseBulk() returned %d tournaments, want 2", len(tournaments))
+	}
+
+	march := tournaments[0].bundle
+	if len(march.Rounds) != 2 || len(march.Matches) != 2 {
+		t.Errorf("Premier Marzo has %d rounds and %d matches, want 2 and 2", len(march.Rounds), len(march.Matches))
+	}
+	if got := tournaments[0].playerLines["ana"]; !reflect.DeepEqual(got, []string{"pairings.csv line 2", "pairings.csv line 4"}) {
+		t.Errorf("Ana appears on %q", got)
+	}
+	// Carla has only a position, so her statistics come from the pairings
+	for _, s := range march.Standings {
+		if s.PlayerName == "Carla" && (s.MatchesPlayed != 1 || s.Ties != 1 || s.Points != 1) {
+			t.Errorf("Carla's standing = %+v, want 1 match, 1 tie and 1 point", s)
+		}
+	}
+}
+
+func TestParseBulkNeedsStandingsOrPairings(t *testing.T) {
+	_, problems := parseBulk(BulkFiles{Races: strings.NewReader("torneo,mes,año,jugador\n")})
+	if want := []string{"standings or pairings file is required"}; !reflect.DeepEqual(problems, want) {
+		t.Errorf("parseBulk() problems = %q, want %q", problems, want)
+	}
+}
+
+// TestBulkImport runs bulk imports against TEST_DATABASE_URL, a migrated database.
+// Every import is rolled back.
+func TestBulkImport(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	pairings := func() io.Reader {
+		return strings.NewReader(pairingsHeader +
+			"Premier de prueba,Marzo,1999,1,PB,Prueba Ana,Prueba Bruno,2,1\n" +
+			"Premier de prueba,Marzo,1999,2,BF,Prueba Bruno,Prueba Ana,0,2\n")
+	}
+	count := func(query string) int {
+		var n int
+		if err := db.QueryRowContext(ctx, query).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	const (
+		countTournaments = "SELECT COUNT(*) FROM tournaments WHERE name = 'Premier de prueba' AND year = 1999"
+		countPlayers     = "SELECT COUNT(*) FROM premier_players WHERE name LIKE 'Prueba %'"
+	)
+
+	tests := []struct {
+		name    string
+		opts    BulkOptions
+		problem string
+		status  string
+	}{
+		{"unknown players", BulkOptions{}, "pairings.csv line 2, pairings.csv line 3: unknown player 'Prueba Ana'", ""},
+		{"dry run", BulkOptions{CreateMissingPlayers: true, DryRun: true}, "", models.BulkImportImported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tournamentsBefore, playersBefore := count(countTournaments), count(countPlayers)
+
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := BulkImport(ctx, tx, BulkFiles{Pairings: pairings()}, tt.opts)
+			// Both callers roll a dry run back
+			tx.Rollback()
+
+			var validationErr *ValidationError
+			switch {
+			case tt.problem != "":
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("BulkImport() error = %v, want a *ValidationError", err)
+				}
+				found := false
+				for _, p := range report.Errors {
+					found = found || p == tt.problem
+				}
+				if !found {
+					t.Errorf("report errors = %q, want %q", report.Errors, tt.problem)
+				}
+			case err != nil:
+				t.Fatalf("BulkImport() error = %v", err)
+			default:
+				if len(report.Tournaments) != 1 {
+					t.Fatalf("report has %d tournaments, want 1", len(report.Tournaments))
+				}
+				entry := report.Tournaments[0]
+				if entry.Status != tt.status || entry.TournamentID != nil || entry.Matches != 2 || len(entry.PlayersCreated) != 2 {
+					t.Errorf("report entry = %+v", entry)
+				}
+				if !report.DryRun {
+					t.Error("report is not marked as a dry run")
+				}
+			}
+
+			if n := count(countTournaments); n != tournamentsBefore {
+				t.Errorf("%d tournaments after the import, want %d", n, tournamentsBefore)
+			}
+			if n := count(countPlayers); n != playersBefore {
+				t.Errorf("%d players after the import, want %d", n, playersBefore)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+package tournamentio
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+)
+
+// CSV files of a bundle archive, one per table
+const (
+	tournamentFile = "tournament.csv"
+	playersFile    = "players.csv"
+	standingsFile  = "standings.csv"
+	roundsFile     = "rounds.csv"
+	matchdaysFile  = "matchdays.csv"
+	matchesFile    = "matches.csv"
+	racesFile      = "races.csv"
+)
+
+var csvHeaders = map[string][]string{
+	tournamentFile: {"name", "month", "year", "type", "format", "status", "deadline_policy", "start_date", "end_date", "completed_at", "archived_at"},
+	playersFile:    {"name", "status", "status_reason", "late_entry"},
+	standingsFile:  {"player_name", "final_position", "matches_played", "wins", "ties", "losses", "points", "total_points_scored", "total_matches"},
+	roundsFile:     {"round_number", "format"},
+	matchdaysFile:  {"matchday", "deadline"},
+	matchesFile:    {"round_number", "matchday", "player1_name", "player2_name", "score1", "score2", "completed", "result_type", "match_date", "deadline"},
+	racesFile:      {"player_name", "race_pb", "race_bf", "notes"},
+}
+
+// WriteZip writes a bundle as a zip archive with one CSV file per table
+func WriteZip(w io.Writer, b *models.TournamentBundle) error {
+	zw := zip.NewWriter(w)
+
+	t := b.Tournament
+	files := []struct {
+		name string
+		rows [][]string
+	}{
+		{tournamentFile, [][]string{{
+			t.Name, t.Month, strconv.Itoa(t.Year), t.Type, fmtString(t.Format), t.Status, t.DeadlinePolicy,
+			fmtString(t.StartDate), fmtString(t.EndDate), fmtTime(t.CompletedAt), fmtTime(t.ArchivedAt),
+		}}},
+		{playersFile, nil},
+		{standingsFile, nil},
+		{roundsFile, nil},
+		{matchdaysFile, nil},
+		{matchesFile, nil},
+		{racesFile, nil},
+	}
+	for _, p := range b.Players {
+		files[1].rows = append(files[1].rows, []string{p.Name, p.Status, fmtString(p.StatusReason), strconv.FormatBool(p.LateEntry)})
+	}
+	for _, s := range b.Standings {
+		files[2].rows = append(files[2].rows, []string{
+			s.PlayerName, fmtInt(s.FinalPosition), strconv.Itoa(s.MatchesPlayed), strconv.Itoa(s.Wins), strconv.Itoa(s.Ties),
+			strconv.Itoa(s.Losses), strconv.Itoa(s.Points), strconv.Itoa(s.TotalPointsScored), strconv.Itoa(s.TotalMatches),
+		})
+	}
+	for _, r := range b.Rounds {
+		files[3].rows = append(files[3].rows, []string{strconv.Itoa(r.RoundNumber), r.Format})
+	}
+	for _, md := range b.Matchdays {
+		files[4].rows = append(files[4].rows, []string{strconv.Itoa(md.Matchday), fmtTime(&md.Deadline)})
+	}
+	for _, m := range b.Matches {
+		files[5].rows = append(files[5].rows, []string{
+			fmtInt(m.RoundNumber), fmtInt(m.Matchday), m.Player1Name, m.Player2Name, fmtInt(m.Score1), fmtInt(m.Score2),
+			strconv.FormatBool(m.Completed), m.ResultType, fmtTime(m.MatchDate), fmtTime(m.Deadline),
+		})
+	}
+	for _, r := range b.Races {
+		files[6].rows = append(files[6].rows, []string{r.PlayerName, fmtString(r.RacePB), fmtString(r.RaceBF), fmtString(r.Notes)})
+	}
+
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		cw := csv.NewWriter(fw)
+		if err := cw.Write(csvHeaders[f.name]); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(f.rows); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ReadZip reads a bundle from a zip archive written by WriteZip. Only tournament.csv
+// is required; unknown columns are ignored. Every malformed value is reported with
+// its file and line in a *ValidationError.
+func ReadZip(data []byte) (*models.TournamentBundle, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	b := &models.TournamentBundle{Version: models.TournamentBundleVersion}
+	var problems []string
+	found := make(map[string]bool)
+
+	for _, f := range zr.File {
+		name := f.Name[strings.LastIndex(f.Name, "/")+1:]
+		if _, ok := csvHeaders[name]; !ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		records, err := ReadCSV(rc, name)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		found[name] = true
+
+		for _, r := range records {
+			switch name {
+			case tournamentFile:
+				if r != records[0] {
+					r.Errorf("only one tournament per archive")
+					break
+				}
+				b.Tournament = r.Tournament()
+			case playersFile:
+				b.Players = append(b.Players, models.BundlePlayer{
+					Name:         r.String("name"),
+					Status:       r.String("status"),
+					StatusReason: r.StringPtr("status_reason"),
+					LateEntry:    r.Bool("late_entry"),
+				})
+			case standingsFile:
+				b.Standings = append(b.Standings, r.Standing())
+			case roundsFile:
+				b.Rounds = append(b.Rounds, models.BundleRound{
+					RoundNumber: r.Int("round_number"),
+					Format:      strings.ToUpper(r.String("format")),
+				})
+			case matchdaysFile:
+				md := models.BundleMatchday{Matchday: r.Int("matchday")}
+				if d := r.Time("deadline"); d != nil {
+					md.Deadline = *d
+				} else {
+					r.Errorf("deadline is required")
+				}
+				b.Matchdays = append(b.Matchdays, md)
+			case matchesFile:
+				b.Matches = append(b.Matches, r.Match())
+			case racesFile:
+				b.Races = append(b.Races, r.Race())
+			}
+			problems = append(problems, r.Problems...)
+		}
+	}
+
+	if !found[tournamentFile] {
+		problems = append(problems, tournamentFile+" is missing")
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return b, nil
+}
+
+// Record is one data row of a CSV file, addressed by column name.
+// Parsing problems are collected in Problems instead of failing on the first one.
+type Record struct {
+	File     string
+	Line     int
+	header   map[string]int
+	row      []string
+	Problems []string
+}
+
+// ReadCSV reads every data row of a CSV file with a header line
+func ReadCSV(r io.Reader, file string) ([]*Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	first, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	header := make(map[string]int)
+	for i, col := range first {
+		header[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff")))] = i
+	}
+
+	var records []*Record
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if isBlankRow(row) {
+			continue
+		}
+		// The reader skips empty lines, so the line is taken from the reader
+		line, _ := cr.FieldPos(0)
+		records = append(records, &Record{File: file, Line: line, header: header, row: row})
+	}
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Has reports whether the CSV file has the column
+func (r *Record) Has(col string) bool {
+	_, ok := r.header[col]
+	return ok
+}
+
+// Errorf records a problem on this row
+func (r *Record) Errorf(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf("%s line %d: %s", r.File, r.Line, fmt.Sprintf(format, args...)))
+}
+
+// String returns the trimmed value of a column, empty if the column is missing
+func (r *Record) String(col string) string {
+	i, ok := r.header[col]
+	if !ok || i >= len(r.row) {
+		return ""
+	}
+	return strings.TrimSpace(r.row[i])
+}
+
+func (r *Record) StringPtr(col string) *string {
+	v := r.String(col)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func (r *Record) Int(col string) int {
+	v := r.IntPtr(col)
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func (r *Record) IntPtr(col string) *int {
+	v := r.String(col)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		r.Errorf("invalid %s '%s'", col, v)
+		return nil
+	}
+	return &n
+}
+
+// Bool accepts true/false, 1/0 and si/no
+func (r *Record) Bool(col string) bool {
+	switch v := strings.ToLower(r.String(col)); v {
+	case "", "false", "0", "no", "n":
+		return false
+	case "true", "1", "si", "sí", "yes", "y":
+		return true
+	default:
+		r.Errorf("invalid %s '%s'", col, v)
+		return false
+	}
+}
+
+// Time accepts RFC 3339 timestamps and plain YYYY-MM-DD dates
+func (r *Record) Time(col string) *time.Time {
+	v := r.String(col)
+	if v == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return &t
+		}
+	}
+	r.Errorf("invalid %s '%s'", col, v)
+	return nil
+}
+
+// Date returns a YYYY-MM-DD date column
+func (r *Record) Date(col string) *string {
+	v := r.String(col)
+	if v == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", v); err != nil {
+		r.Errorf("invalid %s '%s', expected YYYY-MM-DD", col, v)
+		return nil
+	}
+	return &v
+}
+
+func (r *Record) Tournament() models.BundleTournament {
+	return models.BundleTournament{
+		Name:           r.String("name"),
+		Month:          r.String("month"),
+		Year:           r.Int("year"),
+		Type:           r.String("type"),
+		Format:         r.StringPtr("format"),
+		Status:         strings.ToLower(r.String("status")),
+		DeadlinePolicy: strings.ToLower(r.String("deadline_policy")),
+		StartDate:      r.Date("start_date"),
+		EndDate:        r.Date("end_date"),
+		CompletedAt:    r.Time("completed_at"),
+		ArchivedAt:     r.Time("archived_at"),
+	}
+}
+
+func (r *Record) Standing() models.BundleStanding {
+	return models.BundleStanding{
+		PlayerName:        r.String("player_name"),
+		FinalPosition:     r.IntPtr("final_position"),
+		MatchesPlayed:     r.Int("matches_played"),
+		Wins:              r.Int("wins"),
+		Ties:              r.Int("ties"),
+		Losses:            r.Int("losses"),
+		Points:            r.Int("points"),
+		TotalPointsScored: r.Int("total_points_scored"),
+		TotalMatches:      r.Int("total_matches"),
+	}
+}
+
+// Match reads a match row. Without a completed column a match counts as completed
+// when both scores are present.
+func (r *Record) Match() models.BundleMatch {
+	m := models.BundleMatch{
+		RoundNumber: r.IntPtr("round_number"),
+		Matchday:    r.IntPtr("matchday"),
+		Player1Name: r.String("player1_name"),
+		Player2Name: r.String("player2_name"),
+		Score1:      r.IntPtr("score1"),
+		Score2:      r.IntPtr("score2"),
+		ResultType:  strings.ToLower(r.String("result_type")),
+		MatchDate:   r.Time("match_date"),
+		Deadline:    r.Time("deadline"),
+	}
+	if r.Has("completed") && r.String("completed") != "" {
+		m.Completed = r.Bool("completed")
+	} else {
+		m.Completed = m.Score1 != nil && m.Score2 != nil
+	}
+	return m
+}
+
+func (r *Record) Race() models.BundlePlayerRace {
+	return models.BundlePlayerRace{
+		PlayerName: r.String("player_name"),
+		RacePB:     r.StringPtr("race_pb"),
+		RaceBF:     r.StringPtr("race_bf"),
+		Notes:      r.StringPtr("notes"),
+	}
+}
+
+func fmtString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func fmtInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func fmtTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
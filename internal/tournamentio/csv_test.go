@@ -0,0 +1,110 @@
+package tournamentio
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []map[string]string // per record: column -> value
+		lines   []int
+		wantErr bool
+	}{
+		{
+			name:  "header only",
+			input: "player,points\n",
+		},
+		{
+			name:  "empty file",
+			input: "",
+		},
+		{
+			name:  "byte order mark and header case",
+			input: "\ufeffPlayer, Points\nAna,9\n",
+			want:  []map[string]string{{"player": "Ana", "points": "9"}},
+			lines: []int{2},
+		},
+		{
+			name:  "blank rows keep line numbers",
+			input: "player,points\nAna,9\n,\n\nBruno, 6 \n",
+			want:  []map[string]string{{"player": "Ana", "points": "9"}, {"player": "Bruno", "points": "6"}},
+			lines: []int{2, 5},
+		},
+		{
+			name:  "short row",
+			input: "player,points\nAna\n",
+			want:  []map[string]string{{"player": "Ana", "points": ""}},
+			lines: []int{2},
+		},
+		{
+			name:    "unterminated quote",
+			input:   "player,points\n\"Ana,9\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ReadCSV(strings.NewReader(tt.input), "standings.csv")
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "standings.csv") {
+					t.Fatalf("ReadCSV() error = %v, want an error naming the file", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadCSV() error = %v", err)
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("ReadCSV() returned %d records, want %d", len(records), len(tt.want))
+			}
+			for i, r := range records {
+				if r.Line != tt.lines[i] {
+					t.Errorf("record %d is on line %d, want %d", i, r.Line, tt.lines[i])
+				}
+				got := make(map[string]string)
+				for col := range tt.want[i] {
+					got[col] = r.String(col)
+				}
+				if !reflect.DeepEqual(got, tt.want[i]) {
+					t.Errorf("record %d = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRecordProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		read    func(r *Record)
+		problem string
+	}{
+		{"valid int", func(r *Record) { r.Int("year") }, ""},
+		{"invalid int", func(r *Record) { r.Int("points") }, "standings.csv line 2: invalid points 'nueve'"},
+		{"invalid date", func(r *Record) { r.Date("start_date") }, "standings.csv line 2: invalid start_date '03/11/2026', expected YYYY-MM-DD"},
+		{"invalid bool", func(r *Record) { r.Bool("completed") }, "standings.csv line 2: invalid completed 'quizas'"},
+		{"missing column", func(r *Record) { r.IntPtr("wins") }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ReadCSV(strings.NewReader("year,points,start_date,completed\n2026,nueve,03/11/2026,quizas\n"), "standings.csv")
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := records[0]
+			tt.read(r)
+
+			var want []string
+			if tt.problem != "" {
+				want = []string{tt.problem}
+			}
+			if !reflect.DeepEqual(r.Problems, want) {
+				t.Errorf("Problems = %q, want %q", r.Problems, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Package tournamentio exports tournaments into self-contained bundles and imports
+// them back, resolving players by name against premier_players.
+package tournamentio
+
+import (
+	"context"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+)
+
+// ByeName is the placeholder player of in-person rounds with an odd number of players
+const ByeName = "BYE"
+
+// Export builds the bundle of a tournament. It returns sql.ErrNoRows if the tournament doesn't exist.
+func Export(ctx context.Context, tournamentID int) (*models.TournamentBundle, error) {
+	b := &models.TournamentBundle{
+		Version:    models.TournamentBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Players:    []models.BundlePlayer{},
+		Standings:  []models.BundleStanding{},
+		Rounds:     []models.BundleRound{},
+		Matchdays:  []models.BundleMatchday{},
+		Matches:    []models.BundleMatch{},
+		Races:      []models.BundlePlayerRace{},
+	}
+
+	t := &b.Tournament
+	err := database.DB.QueryRowContext(ctx, `
+		SELECT name, month, year, type, format, status, deadline_policy,
+			TO_CHAR(start_date, 'YYYY-MM-DD'), TO_CHAR(end_date, 'YYYY-MM-DD'), completed_at, archived_at
+		FROM tournaments
+		WHERE id = $1
+	`, tournamentID).Scan(
+		&t.Name, &t.Month, &t.Year, &t.Type, &t.Format, &t.Status, &t.DeadlinePolicy,
+		&t.StartDate, &t.EndDate, &t.CompletedAt, &t.ArchivedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := exportStandings(ctx, b, tournamentID); err != nil {
+		return nil, err
+	}
+	if err := exportPlayers(ctx, b, tournamentID); err != nil {
+		return nil, err
+	}
+	if err := exportRounds(ctx, b, tournamentID); err != nil {
+		return nil, err
+	}
+	if err := exportMatches(ctx, b, tournamentID); err != nil {
+		return nil, err
+	}
+	if err := exportRaces(ctx, b, tournamentID); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func exportStandings(ctx context.Context, b *models.TournamentBundle, tournamentID int) error {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT player_name, matches_played, wins, ties, losses, points,
+			total_points_scored, total_matches, final_position
+		FROM tournament_standings
+		WHERE tournament_id = $1
+		ORDER BY final_position, player_name
+	`, tournamentID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.BundleStanding
+		err := rows.Scan(&s.PlayerName, &s.MatchesPlayed, &s.Wins, &s.Ties, &s.Losses, &s.Points,
+			&s.TotalPointsScored, &s.TotalMatches, &s.FinalPosition)
+		if err != nil {
+			return err
+		}
+		b.Standings = append(b.Standings, s)
+	}
+	return rows.Err()
+}
+
+// exportPlayers lists the online league players, or the players of the archived
+// standings, with their status, for in-person tournaments
+func exportPlayers(ctx context.Context, b *models.TournamentBundle, tournamentID int) error {
+	query := `
+		SELECT player_name, status, status_reason, late_entry
+		FROM online_tournament_players
+		WHERE tournament_id = $1
+		ORDER BY player_name
+	`
+	if b.Tournament.Type != "ONLINE" {
+		query = `
+			SELECT player_name, status, NULL, false
+			FROM tournament_standings
+			WHERE tournament_id = $1
+			ORDER BY final_position, player_name
+		`
+	}
+
+	rows, err := database.DB.QueryContext(ctx, query, tournamentID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.BundlePlayer
+		if err := rows.Scan(&p.Name, &p.Status, &p.StatusReason, &p.LateEntry); err != nil {
+			return err
+		}
+		b.Players = append(b.Players, p)
+	}
+	return rows.Err()
+}
+
+func exportRounds(ctx context.Context, b *models.TournamentBundle, tournamentID int) error {
+	rows, err := database.DB.QueryContext(ctx,
+		"SELECT round_number, format FROM tournament_rounds WHERE tournament_id = $1 ORDER BY round_number",
+		tournamentID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.BundleRound
+		if err := rows.Scan(&r.RoundNumber, &r.Format); err != nil {
+			return err
+		}
+		b.Rounds = append(b.Rounds, r)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	mdRows, err := database.DB.QueryContext(ctx,
+		"SELECT matchday, deadline FROM online_tournament_matchdays WHERE tournament_id = $1 ORDER BY matchday",
+		tournamentID,
+	)
+	if err != nil {
+		return err
+	}
+	defer mdRows.Close()
+
+	for mdRows.Next() {
+		var md models.BundleMatchday
+		if err := mdRows.Scan(&md.Matchday, &md.Deadline); err != nil {
+			return err
+		}
+		b.Matchdays = append(b.Matchdays, md)
+	}
+	return mdRows.Err()
+}
+
+// exportMatches exports the archived round matches and the online league matches
+func exportMatches(ctx context.Context, b *models.TournamentBundle, tournamentID int) error {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT tr.round_number, tm.player1_name, tm.player2_name, tm.score1, tm.score2, tm.completed, tm.result_type
+		FROM tournament_matches tm
+		JOIN tournament_rounds tr ON tr.id = tm.tournament_round_id
+		WHERE tr.tournament_id = $1
+		ORDER BY tr.round_number, tm.id
+	`, tournamentID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.BundleMatch
+		if err := rows.Scan(&m.RoundNumber, &m.Player1Name, &m.Player2Name, &m.Score1, &m.Score2, &m.Completed, &m.ResultType); err != nil {
+			return err
+		}
+		b.Matches = append(b.Matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	onlineRows, err := database.DB.QueryContext(ctx, `
+		SELECT player1_name, player2_name, score1, score2, completed, result_type, match_date, matchday, deadline
+		FROM online_tournament_matches
+		WHERE tournament_id = $1
+		ORDER BY matchday NULLS LAST, id
+	`, tournamentID)
+	if err != nil {
+		return err
+	}
+	defer onlineRows.Close()
+
+	for onlineRows.Next() {
+		var m models.BundleMatch
+		err := onlineRows.Scan(&m.Player1Name, &m.Player2Name, &m.Score1, &m.Score2, &m.Completed,
+			&m.ResultType, &m.MatchDate, &m.Matchday, &m.Deadline)
+		if err != nil {
+			return err
+		}
+		b.Matches = append(b.Matches, m)
+	}
+	return onlineRows.Err()
+}
+
+func exportRaces(ctx context.Context, b *models.TournamentBundle, tournamentID int) error {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT COALESCE(tpr.player_name, ts.player_name, ''), tpr.race_pb, tpr.race_bf, tpr.notes
+		FROM tournament_player_races tpr
+		LEFT JOIN tournament_standings ts ON ts.tournament_id = tpr.tournament_id AND ts.player_id = tpr.player_id
+		WHERE tpr.tournament_id = $1
+		ORDER BY 1
+	`, tournamentID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.BundlePlayerRace
+		if err := rows.Scan(&r.PlayerName, &r.RacePB, &r.RaceBF, &r.Notes); err != nil {
+			return err
+		}
+		if r.PlayerName == "" {
+			continue // race of a player we can't identify
+		}
+		b.Races = append(b.Races, r)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,269 @@
+package tournamentio
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/playerstats"
+)
+
+// Options controls how a bundle is imported
+type Options struct {
+	// CreateMissingPlayers adds players that aren't in premier_players instead of failing
+	CreateMissingPlayers bool
+	// AllowDuplicate imports the bundle even if a tournament with the same name, month, year and type exists
+	AllowDuplicate bool
+}
+
+// Result summarizes an import
+type Result struct {
+	TournamentID   int
+	PlayersMatched int
+	PlayersCreated []string
+	Rounds         int
+	Matches        int
+}
+
+// ErrDuplicateTournament is returned when the tournament already exists and Options.AllowDuplicate is false
+var ErrDuplicateTournament = errors.New("tournament already exists")
+
+// ValidationError lists every problem found in a bundle
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid tournament bundle: %s", strings.Join(e.Problems, "; "))
+}
+
+// UnresolvedPlayersError lists the players that don't exist in premier_players
+type UnresolvedPlayersError struct {
+	Names []string
+}
+
+func (e *UnresolvedPlayersError) Error() string {
+	return fmt.Sprintf("unknown players: %s", strings.Join(e.Names, ", "))
+}
+
+type playerRef struct {
+	id   int
+	name string
+}
+
+// Import recreates the tournament of a bundle inside tx. The bundle is normalized
+// (defaults filled in) and validated first; players are resolved by name against
+// premier_players, case-insensitively.
+func Import(ctx context.Context, tx *sql.Tx, b *models.TournamentBundle, opts Options) (*Result, error) {
+	Normalize(b)
+	if problems := Validate(b); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	t := b.Tournament
+	isOnline := t.Type == "ONLINE"
+
+	if !opts.AllowDuplicate {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS(SELECT 1 FROM tournaments WHERE LOWER(name) = LOWER($1) AND month = $2 AND year = $3 AND type = $4)
+		`, t.Name, t.Month, t.Year, t.Type).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrDuplicateTournament
+		}
+	}
+
+	result := &Result{PlayersCreated: []string{}}
+	players, err := resolvePlayers(ctx, tx, b.Players, !isOnline, opts.CreateMissingPlayers, result)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tournaments (
+			name, month, year, type, format, status, deadline_policy,
+			start_date, end_date, completed_at, archived_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, t.Name, t.Month, t.Year, t.Type, t.Format, t.Status, t.DeadlinePolicy,
+		t.StartDate, t.EndDate, t.CompletedAt, t.ArchivedAt,
+	).Scan(&result.TournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tournament: %w", err)
+	}
+	tournamentID := result.TournamentID
+
+	// Standings keep the status of their player, so a recompute ranks them the same
+	statuses := make(map[string]string, len(b.Players))
+	for _, bp := range b.Players {
+		statuses[normalizeName(bp.Name)] = bp.Status
+	}
+	for _, s := range b.Standings {
+		p := players[normalizeName(s.PlayerName)]
+		status := statuses[normalizeName(s.PlayerName)]
+		if status == "" {
+			status = models.PlayerStatusActive
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO tournament_standings (
+				tournament_id, player_id, player_name, matches_played, wins, ties, losses,
+				points, total_points_scored, total_matches, final_position, status
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		`, tournamentID, p.id, p.name, s.MatchesPlayed, s.Wins, s.Ties, s.Losses,
+			s.Points, s.TotalPointsScored, s.TotalMatches, s.FinalPosition, status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import standing of %s: %w", p.name, err)
+		}
+	}
+
+	roundIDs := make(map[int]int)
+	for _, r := range b.Rounds {
+		var roundID int
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO tournament_rounds (tournament_id, round_number, format)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, tournamentID, r.RoundNumber, r.Format).Scan(&roundID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import round %d: %w", r.RoundNumber, err)
+		}
+		roundIDs[r.RoundNumber] = roundID
+		result.Rounds++
+	}
+
+	if isOnline {
+		for _, bp := range b.Players {
+			p := players[normalizeName(bp.Name)]
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO online_tournament_players (tournament_id, player_id, player_name, status, status_reason, late_entry)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, tournamentID, p.id, p.name, bp.Status, bp.StatusReason, bp.LateEntry)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import player %s: %w", p.name, err)
+			}
+		}
+
+		matchdayDeadlines := make(map[int]bool)
+		for _, md := range b.Matchdays {
+			_, err := tx.ExecContext(ctx,
+				"INSERT INTO online_tournament_matchdays (tournament_id, matchday, deadline) VALUES ($1, $2, $3)",
+				tournamentID, md.Matchday, md.Deadline,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import matchday %d: %w", md.Matchday, err)
+			}
+			matchdayDeadlines[md.Matchday] = true
+		}
+
+		for _, m := range b.Matches {
+			p1 := players[normalizeName(m.Player1Name)]
+			p2 := players[normalizeName(m.Player2Name)]
+			overridden := m.Deadline != nil && (m.Matchday == nil || !matchdayDeadlines[*m.Matchday])
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO online_tournament_matches (
+					tournament_id, player1_id, player2_id, player1_name, player2_name,
+					score1, score2, completed, result_type, match_date, matchday, deadline, deadline_overridden
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			`, tournamentID, p1.id, p2.id, p1.name, p2.name,
+				m.Score1, m.Score2, m.Completed, m.ResultType, m.MatchDate, m.Matchday, m.Deadline, overridden)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import match %s vs %s: %w", p1.name, p2.name, err)
+			}
+			result.Matches++
+		}
+	} else {
+		for _, m := range b.Matches {
+			p1 := players[normalizeName(m.Player1Name)]
+			p2 := players[normalizeName(m.Player2Name)]
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO tournament_matches (
+					tournament_round_id, player1_id, player2_id, player1_name, player2_name,
+					score1, score2, completed, result_type
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			`, roundIDs[*m.RoundNumber], p1.id, p2.id, p1.name, p2.name, m.Score1, m.Score2, m.Completed, m.ResultType)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import match %s vs %s: %w", p1.name, p2.name, err)
+			}
+			result.Matches++
+		}
+	}
+
+	for _, r := range b.Races {
+		p := players[normalizeName(r.PlayerName)]
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO tournament_player_races (tournament_id, player_id, player_name, race_pb, race_bf, notes)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, tournamentID, p.id, p.name, r.RacePB, r.RaceBF, r.Notes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import races of %s: %w", p.name, err)
+		}
+	}
+
+	if err := playerstats.RefreshTournament(ctx, tx, tournamentID); err != nil {
+		return nil, fmt.Errorf("failed to update player statistics: %w", err)
+	}
+
+	return result, nil
+}
+
+// resolvePlayers maps every bundle player to a premier player. The BYE placeholder of
+// in-person tournaments gets player id 0 since archive tables don't reference players.
+func resolvePlayers(ctx context.Context, tx *sql.Tx, bundlePlayers []models.BundlePlayer, allowBye, create bool, result *Result) (map[string]playerRef, error) {
+	players := make(map[string]playerRef)
+	var missing []string
+
+	for _, bp := range bundlePlayers {
+		key := normalizeName(bp.Name)
+		if allowBye && key == normalizeName(ByeName) {
+			players[key] = playerRef{id: 0, name: ByeName}
+			continue
+		}
+
+		p, err := findPlayer(ctx, tx, bp.Name)
+		if err == sql.ErrNoRows {
+			if !create {
+				missing = append(missing, strings.TrimSpace(bp.Name))
+				continue
+			}
+			p.name = strings.TrimSpace(bp.Name)
+			if err := tx.QueryRowContext(ctx, "INSERT INTO premier_players (name) VALUES ($1) RETURNING id", p.name).Scan(&p.id); err != nil {
+				return nil, fmt.Errorf("failed to create player %s: %w", p.name, err)
+			}
+			result.PlayersCreated = append(result.PlayersCreated, p.name)
+		} else if err != nil {
+			return nil, err
+		} else {
+			result.PlayersMatched++
+		}
+		players[key] = p
+	}
+
+	if len(missing) > 0 {
+		return nil, &UnresolvedPlayersError{Names: missing}
+	}
+	return players, nil
+}
+
+// findPlayer looks up a premier player by name, case-insensitively
+func findPlayer(ctx context.Context, tx *sql.Tx, name string) (playerRef, error) {
+	var p playerRef
+	err := tx.QueryRowContext(ctx,
+		"SELECT id, name FROM premier_players WHERE LOWER(TRIM(name)) = $1 ORDER BY id LIMIT 1",
+		normalizeName(name),
+	).Scan(&p.id, &p.name)
+	return p, err
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
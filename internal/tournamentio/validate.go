@@ -0,0 +1,312 @@
+package tournamentio
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+)
+
+var (
+	validStatuses = map[string]bool{
+		models.TournamentStatusDraft:        true,
+		models.TournamentStatusRegistration: true,
+		models.TournamentStatusInProgress:   true,
+		models.TournamentStatusCompleted:    true,
+		models.TournamentStatusArchived:     true,
+	}
+	validPlayerStatuses = map[string]bool{
+		models.PlayerStatusActive:       true,
+		models.PlayerStatusDropped:      true,
+		models.PlayerStatusDisqualified: true,
+	}
+	validResultTypes = map[string]bool{
+		models.MatchResultPlayed:     true,
+		models.MatchResultForfeit:    true,
+		models.MatchResultDoubleLoss: true,
+		models.MatchResultVoid:       true,
+	}
+	validDeadlinePolicies = map[string]bool{
+		models.DeadlinePolicyDoubleLoss: true,
+		models.DeadlinePolicyForfeit:    true,
+		models.DeadlinePolicyNone:       true,
+	}
+)
+
+// months maps the month names tournaments are written with, in Spanish and English,
+// to their number, like tournament_month_start in the database
+var months = map[string]int{
+	"enero": 1, "january": 1,
+	"febrero": 2, "february": 2,
+	"marzo": 3, "march": 3,
+	"abril": 4, "april": 4,
+	"mayo": 5, "may": 5,
+	"junio": 6, "june": 6,
+	"julio": 7, "july": 7,
+	"agosto": 8, "august": 8,
+	"septiembre": 9, "setiembre": 9, "september": 9,
+	"octubre": 10, "october": 10,
+	"noviembre": 11, "november": 11,
+	"diciembre": 12, "december": 12,
+}
+
+// monthNumber matches the month numbers tournament_month_start accepts
+var monthNumber = regexp.MustCompile(`^(0?[1-9]|1[0-2])$`)
+
+// ValidMonth reports whether month is a month name in Spanish or English, in any
+// case, or a month number from 1 to 12
+func ValidMonth(month string) bool {
+	month = strings.ToLower(strings.TrimSpace(month))
+	if _, ok := months[month]; ok {
+		return true
+	}
+	return monthNumber.MatchString(month)
+}
+
+// Normalize fills in the defaults of a bundle written by hand (for example from a
+// spreadsheet): type IN_PERSON, archived status, active players, played results.
+// If the player list is empty it is derived from the standings, matches and races.
+func Normalize(b *models.TournamentBundle) {
+	if b.Version == 0 {
+		b.Version = models.TournamentBundleVersion
+	}
+
+	t := &b.Tournament
+	t.Name = strings.TrimSpace(t.Name)
+	t.Type = strings.ToUpper(strings.TrimSpace(t.Type))
+	if t.Type == "" {
+		t.Type = "IN_PERSON"
+	}
+	if t.Format != nil {
+		format := strings.ToUpper(strings.TrimSpace(*t.Format))
+		t.Format = &format
+		if format == "" {
+			t.Format = nil
+		}
+	}
+	if t.Status == "" {
+		t.Status = defaultStatus(b)
+	}
+	if t.DeadlinePolicy == "" {
+		t.DeadlinePolicy = models.DeadlinePolicyDoubleLoss
+	}
+
+	now := time.Now().UTC()
+	if (t.Status == models.TournamentStatusCompleted || t.Status == models.TournamentStatusArchived) && t.CompletedAt == nil {
+		t.CompletedAt = &now
+	}
+	if t.Status == models.TournamentStatusArchived && t.ArchivedAt == nil {
+		t.ArchivedAt = &now
+	}
+
+	if len(b.Players) == 0 {
+		seen := make(map[string]bool)
+		add := func(name string) {
+			key := normalizeName(name)
+			if key == "" || seen[key] {
+				return
+			}
+			seen[key] = true
+			b.Players = append(b.Players, models.BundlePlayer{Name: strings.TrimSpace(name)})
+		}
+		for _, s := range b.Standings {
+			add(s.PlayerName)
+		}
+		for _, m := range b.Matches {
+			add(m.Player1Name)
+			add(m.Player2Name)
+		}
+		for _, r := range b.Races {
+			add(r.PlayerName)
+		}
+	}
+
+	for i := range b.Players {
+		if b.Players[i].Status == "" {
+			b.Players[i].Status = models.PlayerStatusActive
+		}
+	}
+	for i := range b.Matches {
+		if b.Matches[i].ResultType == "" {
+			b.Matches[i].ResultType = models.MatchResultPlayed
+		}
+	}
+}
+
+// defaultStatus is archived for in-person tournaments; online tournaments are
+// completed once every match has a result
+func defaultStatus(b *models.TournamentBundle) string {
+	if b.Tournament.Type != "ONLINE" {
+		return models.TournamentStatusArchived
+	}
+	for _, m := range b.Matches {
+		if !m.Completed {
+			return models.TournamentStatusInProgress
+		}
+	}
+	return models.TournamentStatusCompleted
+}
+
+// Validate returns every problem found in a normalized bundle
+func Validate(b *models.TournamentBundle) []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if b.Version != models.TournamentBundleVersion {
+		add("unsupported bundle version %d", b.Version)
+	}
+
+	t := b.Tournament
+	isOnline := t.Type == "ONLINE"
+	if t.Name == "" {
+		add("tournament: name is required")
+	}
+	if t.Month == "" {
+		add("tournament: month is required")
+	} else if !ValidMonth(t.Month) {
+		add("tournament: unknown month '%s'", t.Month)
+	}
+	if t.Year <= 0 {
+		add("tournament: year is required")
+	} else if t.Year > 9999 {
+		add("tournament: invalid year %d", t.Year)
+	}
+	if t.Type != "IN_PERSON" && !isOnline {
+		add("tournament: invalid type '%s'", t.Type)
+	}
+	if t.Format != nil && *t.Format != "PB" && *t.Format != "BF" {
+		add("tournament: invalid format '%s'", *t.Format)
+	}
+	if isOnline && t.Format == nil {
+		add("tournament: format is required for online tournaments")
+	}
+	if !validStatuses[t.Status] {
+		add("tournament: invalid status '%s'", t.Status)
+	}
+	if !validDeadlinePolicies[t.DeadlinePolicy] {
+		add("tournament: invalid deadline_policy '%s'", t.DeadlinePolicy)
+	}
+
+	known := make(map[string]bool)
+	for i, p := range b.Players {
+		key := normalizeName(p.Name)
+		switch {
+		case key == "":
+			add("players[%d]: name is required", i)
+		case known[key]:
+			add("players[%d]: duplicate player '%s'", i, p.Name)
+		case isOnline && key == normalizeName(ByeName):
+			add("players[%d]: online tournaments can't have a BYE", i)
+		}
+		if !validPlayerStatuses[p.Status] {
+			add("players[%d]: invalid status '%s'", i, p.Status)
+		}
+		known[key] = true
+	}
+	checkPlayer := func(where, name string) {
+		if !known[normalizeName(name)] {
+			add("%s: player '%s' is not in the player list", where, name)
+		}
+	}
+
+	positions := make(map[int]bool)
+	for i, s := range b.Standings {
+		where := fmt.Sprintf("standings[%d]", i)
+		checkPlayer(where, s.PlayerName)
+		if s.FinalPosition != nil {
+			if *s.FinalPosition <= 0 {
+				add("%s: final_position must be positive", where)
+			} else if positions[*s.FinalPosition] {
+				add("%s: duplicate final_position %d", where, *s.FinalPosition)
+			}
+			positions[*s.FinalPosition] = true
+		}
+	}
+
+	rounds := make(map[int]bool)
+	for i, r := range b.Rounds {
+		where := fmt.Sprintf("rounds[%d]", i)
+		if r.RoundNumber <= 0 {
+			add("%s: round_number must be positive", where)
+		} else if rounds[r.RoundNumber] {
+			add("%s: duplicate round %d", where, r.RoundNumber)
+		}
+		if r.Format != "PB" && r.Format != "BF" {
+			add("%s: invalid format '%s'", where, r.Format)
+		}
+		rounds[r.RoundNumber] = true
+	}
+
+	matchdays := make(map[int]bool)
+	for i, md := range b.Matchdays {
+		if md.Matchday <= 0 || matchdays[md.Matchday] {
+			add("matchdays[%d]: invalid or duplicate matchday %d", i, md.Matchday)
+		}
+		matchdays[md.Matchday] = true
+	}
+	if !isOnline && len(b.Matchdays) > 0 {
+		add("matchdays: only online tournaments have matchdays")
+	}
+
+	pairs := make(map[[2]string]bool)
+	for i, m := range b.Matches {
+		where := fmt.Sprintf("matches[%d]", i)
+		checkPlayer(where, m.Player1Name)
+		checkPlayer(where, m.Player2Name)
+		p1, p2 := normalizeName(m.Player1Name), normalizeName(m.Player2Name)
+		if p1 == p2 {
+			add("%s: a player can't play against themselves", where)
+		}
+		if isOnline {
+			if m.RoundNumber != nil {
+				add("%s: online matches don't have a round_number", where)
+			}
+			if p1 > p2 {
+				p1, p2 = p2, p1
+			}
+			if pairs[[2]string{p1, p2}] {
+				add("%s: duplicate match %s vs %s", where, m.Player1Name, m.Player2Name)
+			}
+			pairs[[2]string{p1, p2}] = true
+		} else {
+			if m.RoundNumber == nil || !rounds[*m.RoundNumber] {
+				add("%s: round_number must be one of the rounds", where)
+			}
+			if m.Matchday != nil || m.Deadline != nil {
+				add("%s: only online matches have a matchday or deadline", where)
+			}
+		}
+		if (m.Score1 != nil && *m.Score1 < 0) || (m.Score2 != nil && *m.Score2 < 0) {
+			add("%s: scores can't be negative", where)
+		}
+		if m.ResultType == models.MatchResultVoid {
+			if m.Score1 != nil || m.Score2 != nil {
+				add("%s: void matches have no scores", where)
+			}
+		} else if m.Completed && (m.Score1 == nil || m.Score2 == nil) {
+			add("%s: completed matches need both scores", where)
+		}
+		if !validResultTypes[m.ResultType] {
+			add("%s: invalid result_type '%s'", where, m.ResultType)
+		}
+		if m.Matchday != nil && !matchdays[*m.Matchday] && len(b.Matchdays) > 0 {
+			add("%s: matchday %d is not in the matchday list", where, *m.Matchday)
+		}
+	}
+
+	raced := make(map[string]bool)
+	for i, r := range b.Races {
+		where := fmt.Sprintf("races[%d]", i)
+		checkPlayer(where, r.PlayerName)
+		if raced[normalizeName(r.PlayerName)] {
+			add("%s: duplicate races for '%s'", where, r.PlayerName)
+		}
+		raced[normalizeName(r.PlayerName)] = true
+	}
+
+	return problems
+}
@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+	"github.com/andreuvv/premier_mitologico/backend/internal/outbox"
+)
+
+// ConfigFromEnv reads WEBHOOK_DISPATCH_INTERVAL (default 10s), WEBHOOK_TIMEOUT
+// (default 10s) and WEBHOOK_MAX_ATTEMPTS (default 8). Retries back off from 30s
+// up to 6h.
+func ConfigFromEnv() outbox.Config {
+	return outbox.ConfigFromEnv("WEBHOOK", outbox.Config{
+		Interval:    10 * time.Second,
+		Timeout:     10 * time.Second,
+		MaxAttempts: 8,
+		BackoffBase: 30 * time.Second,
+		BackoffCap:  6 * time.Hour,
+	})
+}
+
+// delivery is a queued event, with the webhook it goes to
+type delivery struct {
+	url     string
+	secret  string
+	event   string
+	payload []byte
+}
+
+// deliveries is the webhook_deliveries outbox; deliveries of disabled webhooks wait
+// until they are enabled again
+var deliveries = outbox.New[delivery]("webhook", outbox.Table[delivery]{
+	Name:    "webhook_deliveries",
+	Join:    "JOIN webhooks w ON w.id = q.webhook_id",
+	Where:   "w.active = true",
+	Columns: "w.url, w.secret, q.event, q.payload",
+	Fields: func(d *delivery) []interface{} {
+		return []interface{}{&d.url, &d.secret, &d.event, &d.payload}
+	},
+	SentStatus: models.WebhookDeliveryDelivered,
+	SentColumn: "delivered_at",
+	CodeColumn: "response_status",
+})
+
+// Wake tells the dispatcher that deliveries are due
+func Wake() {
+	deliveries.Wake()
+}
+
+// Start runs the dispatcher in a background goroutine until ctx is cancelled
+func Start(ctx context.Context, cfg outbox.Config) {
+	deliveries.Start(ctx, cfg, send(http.DefaultClient))
+}
+
+// send posts deliveries with client. Any 2xx answer is a success.
+func send(client *http.Client) outbox.SendFunc[delivery] {
+	return func(ctx context.Context, item outbox.Item[delivery]) (int, error) {
+		d := item.Data
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(d.payload))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "premier-mitologico-webhooks/1.0")
+		req.Header.Set(HeaderEvent, d.event)
+		req.Header.Set(HeaderDelivery, strconv.Itoa(item.ID))
+		req.Header.Set(HeaderSignature, Sign(d.secret, time.Now(), d.payload))
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status)
+		}
+		return resp.StatusCode, nil
+	}
+}
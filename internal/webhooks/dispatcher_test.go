@@ -0,0 +1,158 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/outbox"
+)
+
+// memoryStore is an outbox.Store of deliveries in memory; failed ones are due again
+// right away
+type memoryStore struct {
+	mu       sync.Mutex
+	items    []outbox.Item[delivery]
+	statuses []string
+	outcomes []outbox.Outcome
+}
+
+func (s *memoryStore) Claim(_ context.Context, limit int, _ time.Duration) ([]outbox.Item[delivery], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var batch []outbox.Item[delivery]
+	for i, item := range s.items {
+		if s.statuses[i] == outbox.Pending && len(batch) < limit {
+			batch = append(batch, item)
+		}
+	}
+	return batch, nil
+}
+
+func (s *memoryStore) Record(_ context.Context, item outbox.Item[delivery], outcome outbox.Outcome) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outcomes = append(s.outcomes, outcome)
+	for i := range s.items {
+		if s.items[i].ID == item.ID {
+			s.items[i].Attempts = outcome.Attempts
+			s.statuses[i] = outcome.Status
+		}
+	}
+	return nil
+}
+
+var testConfig = outbox.Config{
+	Interval:    time.Second,
+	Timeout:     time.Second,
+	MaxAttempts: 3,
+	BackoffBase: 30 * time.Second,
+	BackoffCap:  time.Hour,
+}
+
+func TestDispatcherRetriesUntilDelivered(t *testing.T) {
+	payload := []byte(`{"event":"match.completed"}`)
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		call := calls
+		mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify("secret", r.Header.Get(HeaderSignature), body, time.Minute); err != nil {
+			t.Errorf("call %d: %v", call, err)
+		}
+		if got := r.Header.Get(HeaderEvent); got != EventMatchCompleted {
+			t.Errorf("call %d: %s = %q, want %q", call, HeaderEvent, got, EventMatchCompleted)
+		}
+		if got := r.Header.Get(HeaderDelivery); got != "7" {
+			t.Errorf("call %d: %s = %q, want 7", call, HeaderDelivery, got)
+		}
+		if call < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{
+		items:    []outbox.Item[delivery]{{ID: 7, Data: delivery{url: server.URL, secret: "secret", event: EventMatchCompleted, payload: payload}}},
+		statuses: []string{outbox.Pending},
+	}
+	d := outbox.New[delivery]("webhook", store)
+	for round := 0; round < 5; round++ {
+		if _, err := d.RunOnce(context.Background(), testConfig, send(server.Client())); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 3 {
+		t.Errorf("receiver called %d times, want 3", calls)
+	}
+	wantCodes := []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusNoContent}
+	if len(store.outcomes) != len(wantCodes) {
+		t.Fatalf("recorded %d outcomes, want %d", len(store.outcomes), len(wantCodes))
+	}
+	for i, got := range store.outcomes {
+		if got.Code != wantCodes[i] {
+			t.Errorf("outcome %d: code %d, want %d", i, got.Code, wantCodes[i])
+		}
+	}
+	if store.statuses[0] != outbox.Sent {
+		t.Errorf("status %s, want %s", store.statuses[0], outbox.Sent)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{
+		items:    []outbox.Item[delivery]{{ID: 1, Data: delivery{url: server.URL, secret: "secret", event: EventPing, payload: []byte(`{}`)}}},
+		statuses: []string{outbox.Pending},
+	}
+	d := outbox.New[delivery]("webhook", store)
+	for round := 0; round < testConfig.MaxAttempts+2; round++ {
+		if _, err := d.RunOnce(context.Background(), testConfig, send(server.Client())); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := len(store.outcomes); n != testConfig.MaxAttempts {
+		t.Fatalf("recorded %d outcomes, want %d", n, testConfig.MaxAttempts)
+	}
+	last := store.outcomes[len(store.outcomes)-1]
+	if last.Status != outbox.Failed || last.Attempts != testConfig.MaxAttempts || last.Code != http.StatusInternalServerError {
+		t.Errorf("last outcome = %+v, want failed after %d attempts with 500", last, testConfig.MaxAttempts)
+	}
+	if last.Err == nil {
+		t.Error("last outcome has no error")
+	}
+}
+
+func TestDispatcherUnreachableReceiver(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	store := &memoryStore{
+		items:    []outbox.Item[delivery]{{ID: 1, Data: delivery{url: url, secret: "secret", event: EventPing, payload: []byte(`{}`)}}},
+		statuses: []string{outbox.Pending},
+	}
+	if _, err := outbox.New[delivery]("webhook", store).RunOnce(context.Background(), testConfig, send(http.DefaultClient)); err != nil {
+		t.Fatal(err)
+	}
+	got := store.outcomes[0]
+	if got.Status != outbox.Pending || got.Code != 0 || got.Err == nil || got.Retry != testConfig.BackoffBase {
+		t.Errorf("outcome = %+v, want pending without a code, retried after %v", got, testConfig.BackoffBase)
+	}
+}
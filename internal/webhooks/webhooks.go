@@ -0,0 +1,252 @@
+// Package webhooks tells registered URLs about tournament events. Events are queued in
+// the webhook_deliveries outbox and sent by a background dispatcher, signed with the
+// secret of each webhook, and retried with backoff until the receiver accepts them.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andreuvv/premier_mitologico/backend/internal/database"
+	"github.com/andreuvv/premier_mitologico/backend/internal/models"
+)
+
+// Tournament events a webhook can subscribe to
+const (
+	EventMatchCompleted            = "match.completed"           // a result was entered, in person or online
+	EventMatchCorrected            = "match.corrected"           // a result was corrected, reopened or voided
+	EventRoundCreated              = "round.created"             // a round was paired
+	EventRoundLocked               = "round.locked"              // a round was closed by the organizer
+	EventTournamentStatusChanged   = "tournament.status_changed" // any status transition
+	EventTournamentArchived        = "tournament.archived"
+	EventOnlineTournamentCompleted = "online_tournament.completed"
+
+	// EventPing is only sent by the test endpoint
+	EventPing = "ping"
+)
+
+// Events lists the events a webhook can subscribe to
+var Events = []string{
+	EventMatchCompleted,
+	EventMatchCorrected,
+	EventRoundCreated,
+	EventRoundLocked,
+	EventTournamentStatusChanged,
+	EventTournamentArchived,
+	EventOnlineTournamentCompleted,
+}
+
+// IsEvent reports whether a webhook can subscribe to event
+func IsEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Headers of every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Payload is the JSON body of every delivery
+type Payload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Queue is called by Enqueue for every event, in the same transaction, to queue
+// more than webhook deliveries, e.g. emails
+type Queue func(ctx context.Context, tx database.Querier, event string, data interface{}) error
+
+var (
+	queuesMu sync.RWMutex
+	queues   []Queue
+)
+
+// RegisterQueue adds a queue called for every enqueued event
+func RegisterQueue(queue Queue) {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+	queues = append(queues, queue)
+}
+
+// Enqueue queues event for every active webhook subscribed to it and in the
+// registered queues. Call it in the transaction of the change that caused the event,
+// so the event is queued only if the change commits, and call Emit once it has.
+func Enqueue(ctx context.Context, tx database.Querier, event string, data interface{}) error {
+	body, err := json.Marshal(Payload{Event: event, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT id, $1, $2
+		FROM webhooks
+		WHERE active = true AND $1 = ANY(events)
+	`, event, string(body))
+	if err != nil {
+		return err
+	}
+
+	queuesMu.RLock()
+	current := append([]Queue(nil), queues...)
+	queuesMu.RUnlock()
+	for _, queue := range current {
+		if err := queue(ctx, tx, event, data); err != nil {
+			return fmt.Errorf("failed to queue %s: %w", event, err)
+		}
+	}
+	return nil
+}
+
+// EnqueueFor queues event for a single webhook, whatever it is subscribed to, and
+// returns the delivery ID. sql.ErrNoRows means the webhook does not exist.
+func EnqueueFor(ctx context.Context, db database.Querier, webhookID int, event string, data interface{}) (int, error) {
+	body, err := json.Marshal(Payload{Event: event, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return 0, err
+	}
+
+	var deliveryID int
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT id, $2, $3 FROM webhooks WHERE id = $1
+		RETURNING id
+	`, webhookID, event, string(body)).Scan(&deliveryID)
+	if err != nil {
+		return 0, err
+	}
+	Wake()
+	return deliveryID, nil
+}
+
+// Listener is called in process for every emitted event, whether or not a webhook is
+// subscribed to it, e.g. to post it to a chat
+type Listener func(event string, data interface{})
+
+var (
+	listenersMu sync.RWMutex
+	listeners   []Listener
+)
+
+// RegisterListener adds a listener called for every emitted event
+func RegisterListener(listener Listener) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	listeners = append(listeners, listener)
+}
+
+// Emit announces an event once the change that caused it is committed: it wakes the
+// dispatcher for the deliveries Enqueue queued and calls the registered listeners
+func Emit(event string, data interface{}) {
+	Wake()
+
+	listenersMu.RLock()
+	current := append([]Listener(nil), listeners...)
+	listenersMu.RUnlock()
+	for _, listener := range current {
+		listener(event, data)
+	}
+}
+
+// EnqueueOnlineMatchExpired is a scheduler expiry queue for matches resolved after
+// their deadline
+func EnqueueOnlineMatchExpired(ctx context.Context, tx database.Querier, match models.OnlineTournamentMatch) error {
+	return Enqueue(ctx, tx, EventMatchCompleted, MatchData{Source: models.MatchSourceOnline, Match: match})
+}
+
+// OnlineMatchExpired is a scheduler expiry hook announcing matches resolved after
+// their deadline, once they are committed
+func OnlineMatchExpired(match models.OnlineTournamentMatch) {
+	Emit(EventMatchCompleted, MatchData{Source: models.MatchSourceOnline, Match: match})
+}
+
+// MatchData is the data of match.completed and match.corrected events
+type MatchData struct {
+	Source     string      `json:"source"` // in_person, online or archived
+	Match      interface{} `json:"match,omitempty"`
+	Correction interface{} `json:"correction,omitempty"` // match.corrected only
+}
+
+// TournamentData is the data of tournament events
+type TournamentData struct {
+	TournamentID   int    `json:"tournament_id"`
+	Type           string `json:"type,omitempty"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status,omitempty"` // tournament.status_changed only
+}
+
+// NewSecret generates a random webhook secret
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the X-Webhook-Signature header of a body sent at timestamp:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" with the secret>"
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, signature(secret, t, body))
+}
+
+func signature(secret, t string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the X-Webhook-Signature header of a received body. Signatures older
+// than tolerance are rejected so a captured delivery cannot be replayed later;
+// a tolerance of 0 accepts any age.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+	if t == "" || v1 == "" {
+		return errors.New("malformed signature header")
+	}
+
+	if tolerance > 0 {
+		unix, err := strconv.ParseInt(t, 10, 64)
+		if err != nil {
+			return errors.New("malformed signature timestamp")
+		}
+		if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return errors.New("signature timestamp outside tolerance")
+		}
+	}
+
+	if !hmac.Equal([]byte(v1), []byte(signature(secret, t, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package webhooks
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"event":"ping"}`)
+	now := time.Now()
+	valid := Sign("secret", now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		header    string
+		body      []byte
+		tolerance time.Duration
+		wantErr   string
+	}{
+		{"valid", "secret", valid, body, 5 * time.Minute, ""},
+		{"spaces after commas", "secret", "t=" + strconv.FormatInt(now.Unix(), 10) + ", v1=" + signature("secret", strconv.FormatInt(now.Unix(), 10), body), body, 5 * time.Minute, ""},
+		{"wrong secret", "other", valid, body, 5 * time.Minute, "signature mismatch"},
+		{"changed body", "secret", valid, []byte(`{"event":"pong"}`), 5 * time.Minute, "signature mismatch"},
+		{"too old", "secret", Sign("secret", now.Add(-time.Hour), body), body, 5 * time.Minute, "signature timestamp outside tolerance"},
+		{"from the future", "secret", Sign("secret", now.Add(time.Hour), body), body, 5 * time.Minute, "signature timestamp outside tolerance"},
+		{"any age without tolerance", "secret", Sign("secret", now.Add(-24*time.Hour), body), body, 0, ""},
+		{"no timestamp", "secret", "v1=abc", body, 5 * time.Minute, "malformed signature header"},
+		{"no signature", "secret", "t=123", body, 5 * time.Minute, "malformed signature header"},
+		{"bad timestamp", "secret", "t=yesterday,v1=abc", body, 5 * time.Minute, "malformed signature timestamp"},
+		{"empty", "secret", "", body, 5 * time.Minute, "malformed signature header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, tt.tolerance)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Verify() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("Verify() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
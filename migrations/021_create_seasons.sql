@@ -0,0 +1,33 @@
+-- Migration: Create season tables for the yearly circuit
+-- Created: 2026-10-19
+-- Purpose: Group IN_PERSON and ONLINE tournaments into a season with circuit points
+
+CREATE TABLE IF NOT EXISTS seasons (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    year INTEGER NOT NULL,
+    -- Circuit points awarded by final position: points_table[1] is 1st place, [2] is 2nd, ...
+    points_table INTEGER[] NOT NULL DEFAULT '{10,8,6,5,4,3,2,1}',
+    -- Points every player gets for taking part in a tournament, on top of the position points
+    participation_points INTEGER NOT NULL DEFAULT 0 CHECK (participation_points >= 0),
+    -- Only the best N tournament results count towards the total (NULL = all results)
+    best_n_results INTEGER CHECK (best_n_results IS NULL OR best_n_results > 0),
+    -- Number of players from the top of the leaderboard that qualify for the finals
+    qualifier_slots INTEGER NOT NULL DEFAULT 8 CHECK (qualifier_slots >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tournaments that count towards a season
+CREATE TABLE IF NOT EXISTS season_tournaments (
+    season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    tournament_id INTEGER NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (season_id, tournament_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seasons_year ON seasons(year DESC);
+CREATE INDEX IF NOT EXISTS idx_season_tournaments_tournament ON season_tournaments(tournament_id);
+
+CREATE TRIGGER update_seasons_updated_at BEFORE UPDATE ON seasons
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
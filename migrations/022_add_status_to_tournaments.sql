@@ -0,0 +1,26 @@
+-- Migration: Add explicit status lifecycle to tournaments
+-- Created: 2026-10-19
+-- Purpose: Online tournaments were stored with archived_at set at creation, so
+-- "active" and "archived" could not be told apart. Tournaments now move through
+-- draft -> registration -> in_progress -> completed -> archived.
+
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'archived'
+  CHECK (status IN ('draft', 'registration', 'in_progress', 'completed', 'archived'));
+
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+
+-- archived_at is only set when a tournament is actually archived
+ALTER TABLE tournaments ALTER COLUMN archived_at DROP DEFAULT;
+
+-- Existing online tournaments without frozen standings are still being played
+UPDATE tournaments t
+SET status = 'in_progress', archived_at = NULL
+WHERE t.type = 'ONLINE'
+  AND NOT EXISTS (SELECT 1 FROM tournament_standings ts WHERE ts.tournament_id = t.id);
+
+-- Every other existing tournament was archived from the live tables
+UPDATE tournaments SET completed_at = archived_at WHERE status = 'archived' AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_status ON tournaments(status);
+
+COMMENT ON COLUMN tournaments.status IS 'Lifecycle: draft, registration, in_progress, completed, archived';
@@ -0,0 +1,126 @@
+-- Migration: Track drops, disqualifications, late entries and forfeited matches
+-- Created: 2026-10-19
+-- Purpose: Players can leave (drop), be disqualified or join late, both in the
+-- in-person tournament and in online leagues. Their pending matches are either
+-- forfeited to the opponent or removed.
+
+-- In-person players
+ALTER TABLE players ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active'
+  CHECK (status IN ('active', 'dropped', 'disqualified'));
+ALTER TABLE players ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE players ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP;
+ALTER TABLE players ADD COLUMN IF NOT EXISTS late_entry BOOLEAN NOT NULL DEFAULT false;
+
+-- Online tournament players
+ALTER TABLE online_tournament_players ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active'
+  CHECK (status IN ('active', 'dropped', 'disqualified'));
+ALTER TABLE online_tournament_players ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE online_tournament_players ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP;
+ALTER TABLE online_tournament_players ADD COLUMN IF NOT EXISTS late_entry BOOLEAN NOT NULL DEFAULT false;
+
+-- How a match result came to be: played normally or awarded by forfeit
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS result_type VARCHAR(20) NOT NULL DEFAULT 'played'
+  CHECK (result_type IN ('played', 'forfeit'));
+ALTER TABLE online_tournament_matches ADD COLUMN IF NOT EXISTS result_type VARCHAR(20) NOT NULL DEFAULT 'played'
+  CHECK (result_type IN ('played', 'forfeit'));
+
+-- Recreate in-person standings: forfeit wins score match points but not game points
+DROP VIEW IF EXISTS standings;
+
+CREATE VIEW standings AS
+SELECT 
+    p.id,
+    p.name,
+    p.status,
+    COUNT(CASE WHEN m.completed = true THEN 1 END) as matches_played,
+    SUM(CASE 
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 > m.score2) OR 
+            (m.player2_id = p.id AND m.score2 > m.score1)
+        ) THEN 1 ELSE 0 
+    END) as wins,
+    SUM(CASE 
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 = m.score2) OR 
+            (m.player2_id = p.id AND m.score2 = m.score1)
+        ) THEN 1 ELSE 0 
+    END) as ties,
+    SUM(CASE 
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 < m.score2) OR 
+            (m.player2_id = p.id AND m.score2 < m.score1)
+        ) THEN 1 ELSE 0 
+    END) as losses,
+    SUM(CASE 
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 > m.score2) OR 
+            (m.player2_id = p.id AND m.score2 > m.score1)
+        ) THEN 3
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 = m.score2) OR 
+            (m.player2_id = p.id AND m.score2 = m.score1)
+        ) THEN 1
+        ELSE 0 
+    END) as points,
+    COALESCE(SUM(CASE 
+        WHEN m.completed AND m.result_type = 'forfeit' THEN 0
+        WHEN m.completed AND m.player1_id = p.id THEN m.score1
+        WHEN m.completed AND m.player2_id = p.id THEN m.score2
+        ELSE 0
+    END), 0) as total_points_scored,
+    COALESCE((
+        SELECT SUM(pms.games_played)
+        FROM player_match_stats pms
+        JOIN matches m2 ON pms.match_id = m2.id
+        WHERE pms.player_id = p.id AND m2.completed = true
+    ), 0) as total_matches
+FROM players p
+LEFT JOIN matches m ON (m.player1_id = p.id OR m.player2_id = p.id)
+WHERE p.confirmed = true
+GROUP BY p.id, p.name, p.status
+ORDER BY points DESC, total_points_scored DESC;
+
+-- Recreate online standings with the player status
+DROP VIEW IF EXISTS online_tournament_standings;
+
+CREATE VIEW online_tournament_standings AS
+SELECT 
+    otp.tournament_id,
+    otp.player_id,
+    otp.player_name,
+    otp.status,
+    COUNT(CASE WHEN otm.completed THEN 1 END) as matches_played,
+    SUM(CASE 
+        WHEN otm.completed AND (
+            (otm.player1_id = otp.player_id AND otm.score1 > otm.score2) OR 
+            (otm.player2_id = otp.player_id AND otm.score2 > otm.score1)
+        ) THEN 1 ELSE 0 
+    END) as wins,
+    SUM(CASE 
+        WHEN otm.completed AND otm.score1 = otm.score2 THEN 1 ELSE 0 
+    END) as ties,
+    SUM(CASE 
+        WHEN otm.completed AND (
+            (otm.player1_id = otp.player_id AND otm.score1 < otm.score2) OR 
+            (otm.player2_id = otp.player_id AND otm.score2 < otm.score1)
+        ) THEN 1 ELSE 0 
+    END) as losses,
+    SUM(CASE 
+        WHEN otm.completed AND otm.player1_id = otp.player_id THEN 
+            CASE WHEN otm.score1 > otm.score2 THEN 3 
+                 WHEN otm.score1 = otm.score2 THEN 1 
+                 ELSE 0 END
+        WHEN otm.completed AND otm.player2_id = otp.player_id THEN 
+            CASE WHEN otm.score2 > otm.score1 THEN 3 
+                 WHEN otm.score2 = otm.score1 THEN 1 
+                 ELSE 0 END
+        ELSE 0
+    END) as points
+FROM online_tournament_players otp
+LEFT JOIN online_tournament_matches otm ON otm.tournament_id = otp.tournament_id 
+    AND (otm.player1_id = otp.player_id OR otm.player2_id = otp.player_id)
+GROUP BY otp.tournament_id, otp.player_id, otp.player_name, otp.status
+ORDER BY points DESC, wins DESC;
+
+CREATE INDEX IF NOT EXISTS idx_players_status ON players(status);
+CREATE INDEX IF NOT EXISTS idx_online_tournament_players_status ON online_tournament_players(status);
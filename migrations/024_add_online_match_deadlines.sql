@@ -0,0 +1,84 @@
+-- Migration: Deadlines and automatic expiry for online matches
+-- Created: 2026-10-19
+-- Purpose: Online matches get a deadline, either per match or per matchday.
+-- A background scheduler resolves overdue matches according to the tournament's
+-- deadline policy and sends reminders before a deadline passes.
+
+-- What happens when a match passes its deadline:
+--   double_loss: both players get a loss
+--   forfeit:     the player who claimed the forfeit wins, otherwise double loss
+--   none:        the match stays pending
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS deadline_policy VARCHAR(20) NOT NULL DEFAULT 'double_loss'
+  CHECK (deadline_policy IN ('double_loss', 'forfeit', 'none'));
+
+-- Deadline per matchday
+CREATE TABLE IF NOT EXISTS online_tournament_matchdays (
+    tournament_id INTEGER NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    matchday INTEGER NOT NULL CHECK (matchday > 0),
+    deadline TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tournament_id, matchday)
+);
+
+-- deadline holds the effective deadline of the match. It follows the matchday
+-- deadline unless it was set on the match itself (deadline_overridden).
+ALTER TABLE online_tournament_matches ADD COLUMN IF NOT EXISTS matchday INTEGER;
+ALTER TABLE online_tournament_matches ADD COLUMN IF NOT EXISTS deadline TIMESTAMP;
+ALTER TABLE online_tournament_matches ADD COLUMN IF NOT EXISTS deadline_overridden BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE online_tournament_matches ADD COLUMN IF NOT EXISTS reminder_sent_at TIMESTAMP;
+ALTER TABLE online_tournament_matches ADD COLUMN IF NOT EXISTS forfeit_claimed_by INTEGER REFERENCES premier_players(id) ON DELETE SET NULL;
+
+-- Double losses are a new kind of result
+ALTER TABLE online_tournament_matches DROP CONSTRAINT IF EXISTS online_tournament_matches_result_type_check;
+ALTER TABLE online_tournament_matches ADD CONSTRAINT online_tournament_matches_result_type_check
+  CHECK (result_type IN ('played', 'forfeit', 'double_loss'));
+
+-- Recreate online standings: a double loss (0-0) is a loss for both players, not a tie
+DROP VIEW IF EXISTS online_tournament_standings;
+
+CREATE VIEW online_tournament_standings AS
+SELECT 
+    otp.tournament_id,
+    otp.player_id,
+    otp.player_name,
+    otp.status,
+    COUNT(CASE WHEN otm.completed THEN 1 END) as matches_played,
+    SUM(CASE 
+        WHEN otm.completed AND otm.result_type != 'double_loss' AND (
+            (otm.player1_id = otp.player_id AND otm.score1 > otm.score2) OR 
+            (otm.player2_id = otp.player_id AND otm.score2 > otm.score1)
+        ) THEN 1 ELSE 0 
+    END) as wins,
+    SUM(CASE 
+        WHEN otm.completed AND otm.result_type != 'double_loss' AND otm.score1 = otm.score2 THEN 1 ELSE 0 
+    END) as ties,
+    SUM(CASE 
+        WHEN otm.completed AND otm.result_type = 'double_loss' THEN 1
+        WHEN otm.completed AND (
+            (otm.player1_id = otp.player_id AND otm.score1 < otm.score2) OR 
+            (otm.player2_id = otp.player_id AND otm.score2 < otm.score1)
+        ) THEN 1 ELSE 0 
+    END) as losses,
+    SUM(CASE 
+        WHEN otm.completed AND otm.result_type = 'double_loss' THEN 0
+        WHEN otm.completed AND otm.player1_id = otp.player_id THEN 
+            CASE WHEN otm.score1 > otm.score2 THEN 3 
+                 WHEN otm.score1 = otm.score2 THEN 1 
+                 ELSE 0 END
+        WHEN otm.completed AND otm.player2_id = otp.player_id THEN 
+            CASE WHEN otm.score2 > otm.score1 THEN 3 
+                 WHEN otm.score2 = otm.score1 THEN 1 
+                 ELSE 0 END
+        ELSE 0
+    END) as points
+FROM online_tournament_players otp
+LEFT JOIN online_tournament_matches otm ON otm.tournament_id = otp.tournament_id 
+    AND (otm.player1_id = otp.player_id OR otm.player2_id = otp.player_id)
+GROUP BY otp.tournament_id, otp.player_id, otp.player_name, otp.status
+ORDER BY points DESC, wins DESC;
+
+CREATE INDEX IF NOT EXISTS idx_online_tournament_matches_deadline ON online_tournament_matches(deadline) WHERE completed = false;
+
+CREATE TRIGGER update_online_tournament_matchdays_updated_at BEFORE UPDATE ON online_tournament_matchdays
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,34 @@
+-- Migration: Scheduling proposals for online matches
+-- Created: 2026-10-19
+-- Purpose: A player proposes one or more time slots for a match, the opponent
+-- accepts one of them and the agreed time is stored in online_tournament_matches.match_date
+
+CREATE TABLE IF NOT EXISTS online_match_proposals (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES online_tournament_matches(id) ON DELETE CASCADE,
+    proposed_by INTEGER NOT NULL REFERENCES premier_players(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+      CHECK (status IN ('pending', 'accepted', 'declined', 'withdrawn', 'superseded')),
+    message TEXT,
+    accepted_slot_id INTEGER,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS online_match_proposal_slots (
+    id SERIAL PRIMARY KEY,
+    proposal_id INTEGER NOT NULL REFERENCES online_match_proposals(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    UNIQUE(proposal_id, starts_at)
+);
+
+ALTER TABLE online_match_proposals ADD CONSTRAINT online_match_proposals_accepted_slot_fkey
+  FOREIGN KEY (accepted_slot_id) REFERENCES online_match_proposal_slots(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_online_match_proposals_match ON online_match_proposals(match_id);
+CREATE INDEX IF NOT EXISTS idx_online_match_proposal_slots_proposal ON online_match_proposal_slots(proposal_id);
+CREATE INDEX IF NOT EXISTS idx_online_tournament_matches_match_date ON online_tournament_matches(match_date) WHERE match_date IS NOT NULL;
+
+CREATE TRIGGER update_online_match_proposals_updated_at BEFORE UPDATE ON online_match_proposals
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,12 @@
+-- Migration: Table numbers for in-person matches and fixed seating
+-- Created: 2026-10-19
+-- Purpose: Every match of an in-person round is played at a numbered table.
+-- Top tables go to the players highest in the standings; players who need an
+-- accessibility accommodation can be given a fixed table for every round.
+
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS table_number INTEGER CHECK (table_number > 0);
+ALTER TABLE players ADD COLUMN IF NOT EXISTS fixed_table INTEGER CHECK (fixed_table > 0);
+
+-- A table hosts one match per round (matches against BYE have no table)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_matches_round_table
+  ON matches(round_id, table_number) WHERE table_number IS NOT NULL;
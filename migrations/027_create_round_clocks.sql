@@ -0,0 +1,33 @@
+-- Migration: Round clocks for in-person rounds
+-- Created: 2026-10-19
+-- Purpose: The round timer lives on the server so every client shows the same
+-- remaining time. A clock can be paused, resumed and extended; judges can give
+-- single matches extra time. When time runs out the round enters extra turns.
+
+-- Remaining time = duration_seconds + extension_seconds - elapsed, where elapsed is
+-- elapsed_seconds plus the time since running_since while the clock runs
+CREATE TABLE IF NOT EXISTS round_clocks (
+    round_id INTEGER PRIMARY KEY REFERENCES rounds(id) ON DELETE CASCADE,
+    duration_seconds INTEGER NOT NULL CHECK (duration_seconds > 0),
+    extension_seconds INTEGER NOT NULL DEFAULT 0,
+    extra_turns INTEGER NOT NULL DEFAULT 3 CHECK (extra_turns >= 0),
+    elapsed_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    running_since TIMESTAMP,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_round_clocks_updated_at BEFORE UPDATE ON round_clocks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Extra time given to a single match (e.g. after a judge call)
+CREATE TABLE IF NOT EXISTS match_time_extensions (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    seconds INTEGER NOT NULL CHECK (seconds > 0),
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_time_extensions_match ON match_time_extensions(match_id);
@@ -0,0 +1,30 @@
+-- Migration: Judge calls, infractions and penalties
+-- Created: 2026-10-19
+-- Purpose: Judges record every infraction with its penalty. Game and match losses
+-- change the match result; a disqualification also removes the player from the
+-- tournament. Infractions are kept by player name, like the rest of the history,
+-- so repeat offenders can be spotted across tournaments.
+
+-- tournament_id is NULL while the in-person tournament is running and is set when
+-- it is archived. match_id is cleared when the fixture is replaced, so the round
+-- and opponent are kept as well.
+CREATE TABLE IF NOT EXISTS infractions (
+    id SERIAL PRIMARY KEY,
+    tournament_id INTEGER REFERENCES tournaments(id) ON DELETE SET NULL,
+    match_id INTEGER REFERENCES matches(id) ON DELETE SET NULL,
+    round_number INTEGER,
+    player_name VARCHAR(255) NOT NULL,
+    opponent_name VARCHAR(255),
+    infraction_type VARCHAR(40) NOT NULL CHECK (infraction_type IN (
+        'tardiness', 'slow_play', 'game_rule_violation', 'procedural_error',
+        'deck_problem', 'marked_cards', 'unsporting_conduct', 'cheating', 'other'
+    )),
+    penalty VARCHAR(20) NOT NULL CHECK (penalty IN ('warning', 'game_loss', 'match_loss', 'disqualification')),
+    judge VARCHAR(255) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_infractions_player_name ON infractions(LOWER(TRIM(player_name)));
+CREATE INDEX IF NOT EXISTS idx_infractions_tournament ON infractions(tournament_id);
+CREATE INDEX IF NOT EXISTS idx_infractions_match ON infractions(match_id);
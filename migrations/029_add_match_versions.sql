@@ -0,0 +1,27 @@
+-- Migration: Versioned matches for optimistic concurrency
+-- Created: 2026-10-19
+-- Purpose: Two scorekeepers entering the same match must not silently overwrite
+-- each other. Every match carries a version, exposed as its ETag; a score update
+-- sent with an outdated If-Match is rejected with the current result.
+
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE online_tournament_matches ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- The version changes with the result or the players, whatever changes them (score
+-- entry, forfeits, expired deadlines, penalties), but not with table numbers or dates
+CREATE OR REPLACE FUNCTION bump_match_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (NEW.score1, NEW.score2, NEW.completed, NEW.result_type, NEW.player1_id, NEW.player2_id)
+        IS DISTINCT FROM (OLD.score1, OLD.score2, OLD.completed, OLD.result_type, OLD.player1_id, OLD.player2_id) THEN
+        NEW.version = OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER bump_matches_version BEFORE UPDATE ON matches
+    FOR EACH ROW EXECUTE FUNCTION bump_match_version();
+
+CREATE TRIGGER bump_online_tournament_matches_version BEFORE UPDATE ON online_tournament_matches
+    FOR EACH ROW EXECUTE FUNCTION bump_match_version();
@@ -0,0 +1,143 @@
+-- Migration: Void matches and corrections of archived results
+-- Created: 2026-10-19
+-- Purpose: A match can be voided (not played, no result for anyone) or reopened,
+-- and results can be corrected after their round, or the whole tournament, is over.
+-- Archived matches keep how their result came to be, so the standings of an archived
+-- tournament can be recomputed from its matches after a correction.
+
+-- A void match counts as decided for the round but gives no result to either player
+ALTER TABLE matches DROP CONSTRAINT IF EXISTS matches_result_type_check;
+ALTER TABLE matches ADD CONSTRAINT matches_result_type_check
+  CHECK (result_type IN ('played', 'forfeit', 'void'));
+ALTER TABLE online_tournament_matches DROP CONSTRAINT IF EXISTS online_tournament_matches_result_type_check;
+ALTER TABLE online_tournament_matches ADD CONSTRAINT online_tournament_matches_result_type_check
+  CHECK (result_type IN ('played', 'forfeit', 'double_loss', 'void'));
+
+-- Archived matches: existing archives are assumed to have been played
+ALTER TABLE tournament_matches ADD COLUMN IF NOT EXISTS result_type VARCHAR(20) NOT NULL DEFAULT 'played'
+  CHECK (result_type IN ('played', 'forfeit', 'double_loss', 'void'));
+
+-- Recreate in-person standings without void matches
+DROP VIEW IF EXISTS standings;
+
+CREATE VIEW standings AS
+SELECT 
+    p.id,
+    p.name,
+    p.status,
+    COUNT(CASE WHEN m.completed = true THEN 1 END) as matches_played,
+    SUM(CASE 
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 > m.score2) OR 
+            (m.player2_id = p.id AND m.score2 > m.score1)
+        ) THEN 1 ELSE 0 
+    END) as wins,
+    SUM(CASE 
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 = m.score2) OR 
+            (m.player2_id = p.id AND m.score2 = m.score1)
+        ) THEN 1 ELSE 0 
+    END) as ties,
+    SUM(CASE 
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 < m.score2) OR 
+            (m.player2_id = p.id AND m.score2 < m.score1)
+        ) THEN 1 ELSE 0 
+    END) as losses,
+    SUM(CASE 
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 > m.score2) OR 
+            (m.player2_id = p.id AND m.score2 > m.score1)
+        ) THEN 3
+        WHEN m.completed AND (
+            (m.player1_id = p.id AND m.score1 = m.score2) OR 
+            (m.player2_id = p.id AND m.score2 = m.score1)
+        ) THEN 1
+        ELSE 0 
+    END) as points,
+    COALESCE(SUM(CASE 
+        WHEN m.completed AND m.result_type = 'forfeit' THEN 0
+        WHEN m.completed AND m.player1_id = p.id THEN m.score1
+        WHEN m.completed AND m.player2_id = p.id THEN m.score2
+        ELSE 0
+    END), 0) as total_points_scored,
+    COALESCE((
+        SELECT SUM(pms.games_played)
+        FROM player_match_stats pms
+        JOIN matches m2 ON pms.match_id = m2.id
+        WHERE pms.player_id = p.id AND m2.completed = true
+    ), 0) as total_matches
+FROM players p
+LEFT JOIN matches m ON (m.player1_id = p.id OR m.player2_id = p.id) AND m.result_type <> 'void'
+WHERE p.confirmed = true
+GROUP BY p.id, p.name, p.status
+ORDER BY points DESC, total_points_scored DESC;
+
+-- Recreate online standings without void matches
+DROP VIEW IF EXISTS online_tournament_standings;
+
+CREATE VIEW online_tournament_standings AS
+SELECT 
+    otp.tournament_id,
+    otp.player_id,
+    otp.player_name,
+    otp.status,
+    COUNT(CASE WHEN otm.completed THEN 1 END) as matches_played,
+    SUM(CASE 
+        WHEN otm.completed AND otm.result_type != 'double_loss' AND (
+            (otm.player1_id = otp.player_id AND otm.score1 > otm.score2) OR 
+            (otm.player2_id = otp.player_id AND otm.score2 > otm.score1)
+        ) THEN 1 ELSE 0 
+    END) as wins,
+    SUM(CASE 
+        WHEN otm.completed AND otm.result_type != 'double_loss' AND otm.score1 = otm.score2 THEN 1 ELSE 0 
+    END) as ties,
+    SUM(CASE 
+        WHEN otm.completed AND otm.result_type = 'double_loss' THEN 1
+        WHEN otm.completed AND (
+            (otm.player1_id = otp.player_id AND otm.score1 < otm.score2) OR 
+            (otm.player2_id = otp.player_id AND otm.score2 < otm.score1)
+        ) THEN 1 ELSE 0 
+    END) as losses,
+    SUM(CASE 
+        WHEN otm.completed AND otm.result_type = 'double_loss' THEN 0
+        WHEN otm.completed AND otm.player1_id = otp.player_id THEN 
+            CASE WHEN otm.score1 > otm.score2 THEN 3 
+                 WHEN otm.score1 = otm.score2 THEN 1 
+                 ELSE 0 END
+        WHEN otm.completed AND otm.player2_id = otp.player_id THEN 
+            CASE WHEN otm.score2 > otm.score1 THEN 3 
+                 WHEN otm.score2 = otm.score1 THEN 1 
+                 ELSE 0 END
+        ELSE 0
+    END) as points
+FROM online_tournament_players otp
+LEFT JOIN online_tournament_matches otm ON otm.tournament_id = otp.tournament_id 
+    AND (otm.player1_id = otp.player_id OR otm.player2_id = otp.player_id)
+    AND otm.result_type <> 'void'
+GROUP BY otp.tournament_id, otp.player_id, otp.player_name, otp.status
+ORDER BY points DESC, wins DESC;
+
+-- Every correction is logged with the result it replaced. tournament_id is NULL while
+-- the in-person tournament is running and is set when it is archived. match_id refers
+-- to matches, online_tournament_matches or tournament_matches, as told by match_source.
+CREATE TABLE IF NOT EXISTS match_corrections (
+    id SERIAL PRIMARY KEY,
+    tournament_id INTEGER REFERENCES tournaments(id) ON DELETE CASCADE,
+    match_source VARCHAR(20) NOT NULL CHECK (match_source IN ('in_person', 'online', 'archived')),
+    match_id INTEGER NOT NULL,
+    round_number INTEGER,
+    player1_name VARCHAR(255) NOT NULL,
+    player2_name VARCHAR(255) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('score', 'reopen', 'void')),
+    previous_score1 INTEGER,
+    previous_score2 INTEGER,
+    previous_completed BOOLEAN NOT NULL,
+    previous_result_type VARCHAR(20) NOT NULL,
+    score1 INTEGER,
+    score2 INTEGER,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_corrections_tournament ON match_corrections(tournament_id);
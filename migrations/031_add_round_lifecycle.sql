@@ -0,0 +1,27 @@
+-- Migration: Round lifecycle
+-- Created: 2026-10-19
+-- Purpose: Rounds go through pending (no results), in_progress (some results) and
+-- complete (every match decided), following their matches. The organizer locks a
+-- complete round once its results are final: scores can then only change through
+-- a correction. The next round can only be paired once the previous one is complete.
+
+ALTER TABLE rounds ADD COLUMN IF NOT EXISTS locked_at TIMESTAMP;
+
+-- Progress of every round of the running tournament
+CREATE OR REPLACE VIEW round_progress AS
+SELECT
+    r.id,
+    r.round_number,
+    r.format,
+    r.locked_at,
+    COUNT(m.id) as total_matches,
+    COUNT(m.id) FILTER (WHERE m.completed) as completed_matches,
+    CASE
+        WHEN r.locked_at IS NOT NULL THEN 'locked'
+        WHEN COUNT(m.id) FILTER (WHERE m.completed) = 0 THEN 'pending'
+        WHEN COUNT(m.id) FILTER (WHERE NOT m.completed) = 0 THEN 'complete'
+        ELSE 'in_progress'
+    END as status
+FROM rounds r
+LEFT JOIN matches m ON m.round_id = r.id
+GROUP BY r.id, r.round_number, r.format, r.locked_at;
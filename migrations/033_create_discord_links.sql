@@ -0,0 +1,16 @@
+-- Migration: Discord accounts of players
+-- Created: 2026-10-19
+-- Purpose: Link Discord user ids to premier players, so the Discord bot knows who
+-- is reporting a result or asking for their pending match. A player has at most one
+-- Discord account and a Discord account belongs to at most one player.
+
+CREATE TABLE IF NOT EXISTS discord_links (
+    discord_user_id VARCHAR(32) PRIMARY KEY,
+    player_id INTEGER NOT NULL UNIQUE REFERENCES premier_players(id) ON DELETE CASCADE,
+    discord_username VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_discord_links_updated_at BEFORE UPDATE ON discord_links
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,44 @@
+-- Migration: Email notifications
+-- Created: 2026-10-19
+-- Purpose: Tell players about published pairings, approaching online deadlines,
+-- reported results and tournament status changes. Players choose which notifications
+-- they get and in which language; messages are rendered when queued and sent by a
+-- background dispatcher with retries.
+
+CREATE TABLE IF NOT EXISTS player_contacts (
+    player_id INTEGER PRIMARY KEY REFERENCES premier_players(id) ON DELETE CASCADE,
+    email VARCHAR(255),
+    locale VARCHAR(5) NOT NULL DEFAULT 'es' CHECK (locale IN ('es', 'en')),
+    notify_pairings BOOLEAN NOT NULL DEFAULT true,
+    notify_deadlines BOOLEAN NOT NULL DEFAULT true,
+    notify_results BOOLEAN NOT NULL DEFAULT true,
+    notify_tournaments BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_player_contacts_updated_at BEFORE UPDATE ON player_contacts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Outbox of rendered messages. player_id is kept NULL if the player is deleted so
+-- the history stays.
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    player_id INTEGER REFERENCES premier_players(id) ON DELETE SET NULL,
+    channel VARCHAR(20) NOT NULL DEFAULT 'email' CHECK (channel IN ('email')),
+    recipient VARCHAR(255) NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    locale VARCHAR(5) NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_attempt_at TIMESTAMP,
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_pending ON notifications(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notifications_player ON notifications(player_id, created_at DESC);
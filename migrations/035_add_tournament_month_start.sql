@@ -0,0 +1,48 @@
+-- Migration: Tournament month as a date
+-- Created: 2026-10-19
+-- Purpose: Store the month of a tournament as the date of its first day, so the
+-- history is sorted and filtered by date instead of by Spanish month names. The
+-- month name stays for display and is still what clients send; a trigger keeps
+-- month_start in step with it.
+
+-- First day of the month of a tournament. Accepts Spanish and English month names
+-- in any case, and month numbers; NULL for anything else.
+CREATE OR REPLACE FUNCTION tournament_month_start(month_name TEXT, year INTEGER) RETURNS DATE AS $$
+    SELECT make_date(year, m, 1)
+    FROM (
+        SELECT CASE LOWER(TRIM(month_name))
+            WHEN 'enero' THEN 1 WHEN 'january' THEN 1
+            WHEN 'febrero' THEN 2 WHEN 'february' THEN 2
+            WHEN 'marzo' THEN 3 WHEN 'march' THEN 3
+            WHEN 'abril' THEN 4 WHEN 'april' THEN 4
+            WHEN 'mayo' THEN 5 WHEN 'may' THEN 5
+            WHEN 'junio' THEN 6 WHEN 'june' THEN 6
+            WHEN 'julio' THEN 7 WHEN 'july' THEN 7
+            WHEN 'agosto' THEN 8 WHEN 'august' THEN 8
+            WHEN 'septiembre' THEN 9 WHEN 'setiembre' THEN 9 WHEN 'september' THEN 9
+            WHEN 'octubre' THEN 10 WHEN 'october' THEN 10
+            WHEN 'noviembre' THEN 11 WHEN 'november' THEN 11
+            WHEN 'diciembre' THEN 12 WHEN 'december' THEN 12
+            ELSE CASE WHEN TRIM(month_name) ~ '^(0?[1-9]|1[0-2])$' THEN TRIM(month_name)::INTEGER END
+        END AS m
+    ) months
+    WHERE m IS NOT NULL AND year BETWEEN 1 AND 9999
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS month_start DATE;
+
+UPDATE tournaments SET month_start = tournament_month_start(month, year);
+
+CREATE OR REPLACE FUNCTION set_tournament_month_start() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.month_start := tournament_month_start(NEW.month, NEW.year);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_tournaments_month_start BEFORE INSERT OR UPDATE OF month, year ON tournaments
+    FOR EACH ROW EXECUTE FUNCTION set_tournament_month_start();
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_month_start ON tournaments(month_start DESC, id DESC);
+
+COMMENT ON COLUMN tournaments.month_start IS 'First day of the tournament month, set from month and year by a trigger';
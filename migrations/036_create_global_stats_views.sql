@@ -0,0 +1,126 @@
+-- Migration: Global statistics views
+-- Created: 2026-10-19
+-- Purpose: Precompute the all-time statistics of the finished tournaments, which only
+-- change when a tournament is archived, deleted or corrected or a race is changed.
+-- The server refreshes both views after those changes (REFRESH ... CONCURRENTLY, so
+-- reads are not blocked), and caches the responses built from them.
+
+-- One row per player name with medals, most played races and results by format, as
+-- in the history of every player
+CREATE MATERIALIZED VIEW IF NOT EXISTS global_player_stats AS
+WITH medals AS (
+    SELECT
+        player_name,
+        SUM(CASE WHEN final_position = 1 THEN 1 ELSE 0 END) AS first_place_count,
+        SUM(CASE WHEN final_position = 2 THEN 1 ELSE 0 END) AS second_place_count,
+        SUM(CASE WHEN final_position = 3 THEN 1 ELSE 0 END) AS third_place_count
+    FROM tournament_standings
+    GROUP BY player_name
+),
+race_pb AS (
+    SELECT DISTINCT ON (player_name) player_name, race_pb AS race
+    FROM tournament_player_races
+    WHERE race_pb IS NOT NULL AND race_pb != ''
+    GROUP BY player_name, race_pb
+    ORDER BY player_name, COUNT(*) DESC, race_pb
+),
+race_bf AS (
+    SELECT DISTINCT ON (player_name) player_name, race_bf AS race
+    FROM tournament_player_races
+    WHERE race_bf IS NOT NULL AND race_bf != ''
+    GROUP BY player_name, race_bf
+    ORDER BY player_name, COUNT(*) DESC, race_bf
+),
+results AS (
+    SELECT
+        ts.player_name,
+        SUM(CASE WHEN tr.format = 'PB' AND tm.completed AND (
+            (tm.player1_id = ts.player_id AND tm.score1 > tm.score2) OR
+            (tm.player2_id = ts.player_id AND tm.score2 > tm.score1)
+        ) THEN 1 ELSE 0 END) AS pb_wins,
+        SUM(CASE WHEN tr.format = 'PB' AND tm.completed AND tm.score1 = tm.score2 THEN 1 ELSE 0 END) AS pb_ties,
+        SUM(CASE WHEN tr.format = 'PB' AND tm.completed THEN 1 ELSE 0 END) AS pb_matches,
+        SUM(CASE WHEN tr.format = 'BF' AND tm.completed AND (
+            (tm.player1_id = ts.player_id AND tm.score1 > tm.score2) OR
+            (tm.player2_id = ts.player_id AND tm.score2 > tm.score1)
+        ) THEN 1 ELSE 0 END) AS bf_wins,
+        SUM(CASE WHEN tr.format = 'BF' AND tm.completed AND tm.score1 = tm.score2 THEN 1 ELSE 0 END) AS bf_ties,
+        SUM(CASE WHEN tr.format = 'BF' AND tm.completed THEN 1 ELSE 0 END) AS bf_matches
+    FROM tournament_standings ts
+    JOIN tournament_rounds tr ON tr.tournament_id = ts.tournament_id
+    JOIN tournament_matches tm ON tm.tournament_round_id = tr.id
+        AND (tm.player1_id = ts.player_id OR tm.player2_id = ts.player_id)
+    GROUP BY ts.player_name
+),
+names AS (
+    SELECT player_name FROM tournament_standings
+    UNION
+    SELECT player_name FROM tournament_player_races
+)
+SELECT
+    n.player_name,
+    COALESCE(m.first_place_count, 0) AS first_place_count,
+    COALESCE(m.second_place_count, 0) AS second_place_count,
+    COALESCE(m.third_place_count, 0) AS third_place_count,
+    pb.race AS most_played_race_pb,
+    bf.race AS most_played_race_bf,
+    COALESCE(r.pb_wins, 0) AS pb_wins,
+    COALESCE(r.pb_ties, 0) AS pb_ties,
+    COALESCE(r.pb_matches, 0) AS pb_matches,
+    COALESCE(r.bf_wins, 0) AS bf_wins,
+    COALESCE(r.bf_ties, 0) AS bf_ties,
+    COALESCE(r.bf_matches, 0) AS bf_matches
+FROM names n
+LEFT JOIN medals m ON m.player_name = n.player_name
+LEFT JOIN race_pb pb ON pb.player_name = n.player_name
+LEFT JOIN race_bf bf ON bf.player_name = n.player_name
+LEFT JOIN results r ON r.player_name = n.player_name
+WHERE n.player_name IS NOT NULL;
+
+-- A unique index is required to refresh concurrently
+CREATE UNIQUE INDEX IF NOT EXISTS idx_global_player_stats_name ON global_player_stats(player_name);
+
+-- One row per format and race: how often it was picked, and its matches and win
+-- points (a tie is half a win) in rounds of that format
+CREATE MATERIALIZED VIEW IF NOT EXISTS global_race_stats AS
+WITH picks AS (
+    SELECT tournament_id, player_id, 'PB' AS format, race_pb AS race
+    FROM tournament_player_races
+    WHERE race_pb IS NOT NULL AND race_pb != ''
+    UNION ALL
+    SELECT tournament_id, player_id, 'BF' AS format, race_bf AS race
+    FROM tournament_player_races
+    WHERE race_bf IS NOT NULL AND race_bf != ''
+),
+counts AS (
+    SELECT format, race, COUNT(*) AS picks
+    FROM picks
+    GROUP BY format, race
+),
+results AS (
+    SELECT
+        p.format,
+        p.race,
+        COUNT(*) AS total_matches,
+        SUM(CASE
+            WHEN m.player1_id = p.player_id AND m.score1 > m.score2 THEN 1
+            WHEN m.player2_id = p.player_id AND m.score2 > m.score1 THEN 1
+            WHEN m.score1 IS NOT NULL AND m.score2 IS NOT NULL AND m.score1 = m.score2 THEN 0.5
+            ELSE 0
+        END) AS win_points
+    FROM picks p
+    JOIN tournament_rounds tr ON tr.tournament_id = p.tournament_id AND tr.format = p.format
+    JOIN tournament_matches m ON m.tournament_round_id = tr.id
+        AND (m.player1_id = p.player_id OR m.player2_id = p.player_id)
+    GROUP BY p.format, p.race
+)
+SELECT
+    c.format,
+    c.race,
+    c.picks,
+    COALESCE(r.total_matches, 0) AS total_matches,
+    COALESCE(r.win_points, 0) AS win_points
+FROM counts c
+LEFT JOIN results r ON r.format = c.format AND r.race = c.race;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_global_race_stats_format_race ON global_race_stats(format, race);
@@ -0,0 +1,162 @@
+-- Migration: Player results by tournament and format
+-- Created: 2026-10-19
+-- Purpose: Summarize the matches, wins, ties and losses of every player in every
+-- tournament by format, so the statistics no longer scan every archived and online
+-- match. The server keeps the table up to date in the transactions that archive,
+-- correct or score matches; `go run ./cmd/rebuild-stats` regenerates it from scratch.
+
+CREATE TABLE IF NOT EXISTS player_format_stats (
+    tournament_id INTEGER NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    player_id INTEGER NOT NULL,
+    player_name VARCHAR(255) NOT NULL,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('PB', 'BF')),
+    matches INTEGER NOT NULL DEFAULT 0,
+    wins INTEGER NOT NULL DEFAULT 0,
+    ties INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tournament_id, player_id, format)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_format_stats_name ON player_format_stats(player_name);
+
+-- Backfill: in-person tournaments by round format from their archived matches, online
+-- ones in the tournament format. Void matches don't count; a double loss is a loss.
+INSERT INTO player_format_stats (tournament_id, player_id, player_name, format, matches, wins, ties, losses)
+SELECT
+    r.tournament_id, r.player_id, r.player_name, r.format,
+    COUNT(*),
+    SUM(CASE WHEN r.result_type <> 'double_loss' AND r.scored > r.conceded THEN 1 ELSE 0 END),
+    SUM(CASE WHEN r.result_type <> 'double_loss' AND r.scored = r.conceded THEN 1 ELSE 0 END),
+    SUM(CASE WHEN r.result_type = 'double_loss' OR r.scored < r.conceded THEN 1 ELSE 0 END)
+FROM (
+    SELECT
+        ts.tournament_id, ts.player_id, ts.player_name, tr.format, tm.result_type,
+        CASE WHEN tm.player1_id = ts.player_id THEN tm.score1 ELSE tm.score2 END AS scored,
+        CASE WHEN tm.player1_id = ts.player_id THEN tm.score2 ELSE tm.score1 END AS conceded
+    FROM tournaments t
+    JOIN tournament_standings ts ON ts.tournament_id = t.id
+    JOIN tournament_rounds tr ON tr.tournament_id = t.id AND tr.format IN ('PB', 'BF')
+    JOIN tournament_matches tm ON tm.tournament_round_id = tr.id
+        AND (tm.player1_id = ts.player_id OR tm.player2_id = ts.player_id)
+    WHERE t.type <> 'ONLINE' AND tm.completed AND tm.result_type <> 'void'
+    UNION ALL
+    SELECT
+        otp.tournament_id, otp.player_id, otp.player_name, t.format, otm.result_type,
+        CASE WHEN otm.player1_id = otp.player_id THEN otm.score1 ELSE otm.score2 END AS scored,
+        CASE WHEN otm.player1_id = otp.player_id THEN otm.score2 ELSE otm.score1 END AS conceded
+    FROM tournaments t
+    JOIN online_tournament_players otp ON otp.tournament_id = t.id
+    JOIN online_tournament_matches otm ON otm.tournament_id = t.id
+        AND (otm.player1_id = otp.player_id OR otm.player2_id = otp.player_id)
+    WHERE t.type = 'ONLINE' AND t.format IS NOT NULL AND otm.completed AND otm.result_type <> 'void'
+) r
+GROUP BY r.tournament_id, r.player_id, r.player_name, r.format
+ON CONFLICT (tournament_id, player_id, format) DO NOTHING;
+
+-- Recreate the global views on the summary, counting the finished tournaments
+DROP MATERIALIZED VIEW IF EXISTS global_player_stats;
+
+CREATE MATERIALIZED VIEW global_player_stats AS
+WITH medals AS (
+    SELECT
+        player_name,
+        SUM(CASE WHEN final_position = 1 THEN 1 ELSE 0 END) AS first_place_count,
+        SUM(CASE WHEN final_position = 2 THEN 1 ELSE 0 END) AS second_place_count,
+        SUM(CASE WHEN final_position = 3 THEN 1 ELSE 0 END) AS third_place_count
+    FROM tournament_standings
+    GROUP BY player_name
+),
+race_pb AS (
+    SELECT DISTINCT ON (player_name) player_name, race_pb AS race
+    FROM tournament_player_races
+    WHERE race_pb IS NOT NULL AND race_pb != ''
+    GROUP BY player_name, race_pb
+    ORDER BY player_name, COUNT(*) DESC, race_pb
+),
+race_bf AS (
+    SELECT DISTINCT ON (player_name) player_name, race_bf AS race
+    FROM tournament_player_races
+    WHERE race_bf IS NOT NULL AND race_bf != ''
+    GROUP BY player_name, race_bf
+    ORDER BY player_name, COUNT(*) DESC, race_bf
+),
+results AS (
+    SELECT
+        pfs.player_name,
+        SUM(CASE WHEN pfs.format = 'PB' THEN pfs.wins ELSE 0 END) AS pb_wins,
+        SUM(CASE WHEN pfs.format = 'PB' THEN pfs.ties ELSE 0 END) AS pb_ties,
+        SUM(CASE WHEN pfs.format = 'PB' THEN pfs.matches ELSE 0 END) AS pb_matches,
+        SUM(CASE WHEN pfs.format = 'BF' THEN pfs.wins ELSE 0 END) AS bf_wins,
+        SUM(CASE WHEN pfs.format = 'BF' THEN pfs.ties ELSE 0 END) AS bf_ties,
+        SUM(CASE WHEN pfs.format = 'BF' THEN pfs.matches ELSE 0 END) AS bf_matches
+    FROM player_format_stats pfs
+    JOIN tournaments t ON t.id = pfs.tournament_id AND t.status IN ('completed', 'archived')
+    GROUP BY pfs.player_name
+),
+names AS (
+    SELECT player_name FROM tournament_standings
+    UNION
+    SELECT player_name FROM tournament_player_races
+)
+SELECT
+    n.player_name,
+    COALESCE(m.first_place_count, 0) AS first_place_count,
+    COALESCE(m.second_place_count, 0) AS second_place_count,
+    COALESCE(m.third_place_count, 0) AS third_place_count,
+    pb.race AS most_played_race_pb,
+    bf.race AS most_played_race_bf,
+    COALESCE(r.pb_wins, 0) AS pb_wins,
+    COALESCE(r.pb_ties, 0) AS pb_ties,
+    COALESCE(r.pb_matches, 0) AS pb_matches,
+    COALESCE(r.bf_wins, 0) AS bf_wins,
+    COALESCE(r.bf_ties, 0) AS bf_ties,
+    COALESCE(r.bf_matches, 0) AS bf_matches
+FROM names n
+LEFT JOIN medals m ON m.player_name = n.player_name
+LEFT JOIN race_pb pb ON pb.player_name = n.player_name
+LEFT JOIN race_bf bf ON bf.player_name = n.player_name
+LEFT JOIN results r ON r.player_name = n.player_name
+WHERE n.player_name IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_global_player_stats_name ON global_player_stats(player_name);
+
+DROP MATERIALIZED VIEW IF EXISTS global_race_stats;
+
+CREATE MATERIALIZED VIEW global_race_stats AS
+WITH picks AS (
+    SELECT tournament_id, player_id, 'PB' AS format, race_pb AS race
+    FROM tournament_player_races
+    WHERE race_pb IS NOT NULL AND race_pb != ''
+    UNION ALL
+    SELECT tournament_id, player_id, 'BF' AS format, race_bf AS race
+    FROM tournament_player_races
+    WHERE race_bf IS NOT NULL AND race_bf != ''
+),
+counts AS (
+    SELECT format, race, COUNT(*) AS picks
+    FROM picks
+    GROUP BY format, race
+),
+results AS (
+    SELECT
+        p.format,
+        p.race,
+        SUM(pfs.matches) AS total_matches,
+        SUM(pfs.wins + 0.5 * pfs.ties) AS win_points
+    FROM picks p
+    JOIN player_format_stats pfs ON pfs.tournament_id = p.tournament_id
+        AND pfs.player_id = p.player_id AND pfs.format = p.format
+    JOIN tournaments t ON t.id = p.tournament_id AND t.status IN ('completed', 'archived')
+    GROUP BY p.format, p.race
+)
+SELECT
+    c.format,
+    c.race,
+    c.picks,
+    COALESCE(r.total_matches, 0) AS total_matches,
+    COALESCE(r.win_points, 0) AS win_points
+FROM counts c
+LEFT JOIN results r ON r.format = c.format AND r.race = c.race;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_global_race_stats_format_race ON global_race_stats(format, race);
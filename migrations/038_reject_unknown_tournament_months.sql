@@ -0,0 +1,17 @@
+-- Migration: Reject unknown tournament months
+-- Created: 2026-10-19
+-- Purpose: A month tournament_month_start does not know left month_start NULL, so
+-- the tournament dropped out of the history sorted and filtered by date. The API
+-- rejects such months; the trigger now refuses them too, for any other writer.
+-- Existing rows are left as they are until their month or year is changed.
+
+CREATE OR REPLACE FUNCTION set_tournament_month_start() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.month_start := tournament_month_start(NEW.month, NEW.year);
+    IF NEW.month_start IS NULL THEN
+        RAISE EXCEPTION 'unknown tournament month ''%'' of year %', NEW.month, NEW.year
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
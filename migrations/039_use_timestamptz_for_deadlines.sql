@@ -0,0 +1,12 @@
+-- Migration: Time zones for online deadlines
+-- Created: 2026-10-19
+-- Purpose: Online deadlines were TIMESTAMP columns: the offset sent with a deadline
+-- was dropped and the wall clock read back as UTC. They become TIMESTAMPTZ; existing
+-- values are taken in the session time zone, the one they were written in.
+
+ALTER TABLE online_tournament_matchdays
+    ALTER COLUMN deadline TYPE TIMESTAMPTZ;
+
+ALTER TABLE online_tournament_matches
+    ALTER COLUMN deadline TYPE TIMESTAMPTZ,
+    ALTER COLUMN reminder_sent_at TYPE TIMESTAMPTZ;
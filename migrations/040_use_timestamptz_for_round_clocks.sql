@@ -0,0 +1,11 @@
+-- Migration: Time zones for round clocks
+-- Created: 2026-10-19
+-- Purpose: Round clocks were TIMESTAMP columns written with LOCALTIMESTAMP, so the
+-- start time and server time were read back as UTC wall clocks. They become
+-- TIMESTAMPTZ; existing values are taken in the session time zone they were
+-- written in.
+
+ALTER TABLE round_clocks
+    ALTER COLUMN running_since TYPE TIMESTAMPTZ,
+    ALTER COLUMN started_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN started_at SET DEFAULT CURRENT_TIMESTAMP;
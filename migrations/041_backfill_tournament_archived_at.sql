@@ -0,0 +1,10 @@
+-- Migration: Backfill archived_at of archived tournaments
+-- Created: 2026-10-19
+-- Purpose: Migration 022 cleared archived_at of online tournaments it took for
+-- unfinished and dropped the column default, so archived tournaments could be left
+-- without it, while v1 has always answered archived_at for them. Such rows get the
+-- time they were completed, or created when that is missing too.
+
+UPDATE tournaments
+SET archived_at = COALESCE(completed_at, created_at, CURRENT_TIMESTAMP)
+WHERE status = 'archived' AND archived_at IS NULL;
@@ -0,0 +1,21 @@
+-- Migration: Player status in archived standings
+-- Created: 2026-10-19
+-- Purpose: Recomputing the standings of an archived tournament (after a correction)
+-- must keep disqualified players last, as when the standings were frozen, so the
+-- standings keep the status of each player. Online tournaments take it from their
+-- players; archived in-person ones from the disqualifications recorded for them.
+
+ALTER TABLE tournament_standings ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active'
+  CHECK (status IN ('active', 'dropped', 'disqualified'));
+
+UPDATE tournament_standings ts
+SET status = otp.status
+FROM online_tournament_players otp
+WHERE otp.tournament_id = ts.tournament_id AND otp.player_id = ts.player_id;
+
+UPDATE tournament_standings ts
+SET status = 'disqualified'
+FROM infractions i
+WHERE i.tournament_id = ts.tournament_id
+  AND i.penalty = 'disqualification'
+  AND LOWER(TRIM(i.player_name)) = LOWER(TRIM(ts.player_name));
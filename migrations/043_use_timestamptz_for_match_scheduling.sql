@@ -0,0 +1,13 @@
+-- Migration: Time zones for scheduled online matches
+-- Created: 2026-10-19
+-- Purpose: Proposed slots and the agreed match_date were TIMESTAMP columns: the
+-- offset a player sent with a slot was dropped and the wall clock read back as UTC,
+-- so accepted matches (and their calendar events) moved by the player's offset.
+-- They become TIMESTAMPTZ, like the deadlines in 039; existing values are taken in
+-- the session time zone.
+
+ALTER TABLE online_match_proposal_slots
+    ALTER COLUMN starts_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE online_tournament_matches
+    ALTER COLUMN match_date TYPE TIMESTAMPTZ;